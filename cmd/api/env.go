@@ -0,0 +1,74 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envString 讀取字符串環境變量，未設置時返回默認值
+func envString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// envList 讀取以逗號分隔的環境變量
+func envList(key string) []string {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
+
+// envInt 讀取整數環境變量，格式錯誤時終止程序
+func envInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", key, err)
+	}
+	return n
+}
+
+// envIntRange 讀取整數環境變量，格式錯誤或超出 [min, max] 範圍時終止程序
+func envIntRange(key string, def, min, max int) int {
+	n := envInt(key, def)
+	if n < min || n > max {
+		log.Fatalf("Invalid %s: must be between %d and %d", key, min, max)
+	}
+	return n
+}
+
+// envDuration 讀取時長環境變量（如 30s、5m），格式錯誤時終止程序
+func envDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", key, err)
+	}
+	return d
+}
+
+// envBool 讀取布爾環境變量（true、false、1、0 等），格式錯誤時終止程序
+func envBool(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", key, err)
+	}
+	return b
+}
@@ -1,21 +1,42 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	netHTTP "net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 	"time"
 
 	_ "blog-api/docs"
+	"blog-api/internal/application/analytics"
+	"blog-api/internal/application/avatar"
+	"blog-api/internal/application/bookmark"
+	appExport "blog-api/internal/application/export"
+	"blog-api/internal/application/follow"
 	"blog-api/internal/application/post"
+	"blog-api/internal/application/reaction"
+	"blog-api/internal/application/site"
+	"blog-api/internal/application/sitemap"
+	"blog-api/internal/application/syndication"
+	appToken "blog-api/internal/application/token"
 	"blog-api/internal/application/user"
+	domainUser "blog-api/internal/domain/user"
 	"blog-api/internal/infrastructure/auth"
+	"blog-api/internal/infrastructure/breach"
+	"blog-api/internal/infrastructure/hash"
 	"blog-api/internal/infrastructure/http"
 	"blog-api/internal/infrastructure/http/handlers"
+	"blog-api/internal/infrastructure/mail"
 	"blog-api/internal/infrastructure/postgres"
+	"blog-api/internal/infrastructure/storage"
 
 	"github.com/joho/godotenv"
-	pgDriver "gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
@@ -45,39 +66,186 @@ func main() {
 		log.Println("No .env file found")
 	}
 
-	// 從環境變量獲取數據庫連接信息
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		log.Fatal("DATABASE_URL is not set in the environment")
+	// migrate 子命令只管理數據庫結構，不啟動服務器
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
 	}
 
-	// 配置數據庫連接
-	db, err := gorm.Open(pgDriver.Open(dsn), &gorm.Config{
-		NowFunc: func() time.Time {
-			return time.Now().Local()
-		},
-	})
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+	db := openDatabase()
+	if envBool("AUTO_MIGRATE", false) {
+		migrateUp(db)
 	}
 
 	// 初始化存儲層
 	userRepo := postgres.NewUserRepository(db)
 	postRepo := postgres.NewPostRepository(db)
+	tokenRepo := postgres.NewTokenRepository(db)
+	exportRepo := postgres.NewExportRepository(db)
+	authorRepo := postgres.NewAuthorRepository(db)
+	followRepo := postgres.NewFollowRepository(db)
+	reactionRepo := postgres.NewReactionRepository(db)
+	bookmarkRepo := postgres.NewBookmarkRepository(db)
+	analyticsRepo := postgres.NewAnalyticsRepository(db)
+	sitemapRepo := postgres.NewSitemapRepository(db)
+
+	// 初始化事務管理器，序列化衝突和死鎖時自動重試
+	txIsolation, err := postgres.ParseIsolationLevel(envString("DB_TX_ISOLATION", "repeatable_read"))
+	if err != nil {
+		log.Fatalf("Invalid DB_TX_ISOLATION: %v", err)
+	}
+	txManager := postgres.NewTransactionManager(db, postgres.TransactionConfig{
+		Isolation:    txIsolation,
+		MaxRetries:   envInt("DB_TX_MAX_RETRIES", 3),
+		RetryBackoff: envDuration("DB_TX_RETRY_BACKOFF", 20*time.Millisecond),
+	})
+
+	// 加載 JWT 密鑰，密鑰目錄會定期重新加載以支持無需重新部署的輪換
+	keyManager, err := auth.NewKeyManager(auth.KeySource{
+		Dir:          os.Getenv("JWT_KEYS_DIR"),
+		ActiveKeyID:  os.Getenv("JWT_ACTIVE_KID"),
+		SecretKey:    os.Getenv("JWT_SECRET_KEY"),
+		PrivateKey:   os.Getenv("JWT_PRIVATE_KEY"),
+		PrivateKeyID: os.Getenv("JWT_PRIVATE_KEY_ID"),
+	})
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+	keyManager.Watch(envDuration("JWT_KEYS_RELOAD_INTERVAL", time.Minute))
 
 	// 初始化 JWT 服務
-	jwtService := auth.NewJWTService(os.Getenv("JWT_SECRET_KEY"))
+	jwtService := auth.NewJWTService(keyManager, auth.JWTConfig{
+		Issuer:    os.Getenv("JWT_ISSUER"),
+		Audiences: envList("JWT_AUDIENCES"),
+		Leeway:    envDuration("JWT_LEEWAY", 0),
+	})
+
+	// 初始化密碼哈希：新密碼使用首選算法，舊格式的哈希仍可驗證並在登錄時重新哈希
+	argon2Params := hash.Argon2Params{
+		Memory:      uint32(envIntRange("ARGON2_MEMORY_KIB", int(hash.DefaultArgon2Params.Memory), 8, math.MaxUint32)),
+		Iterations:  uint32(envIntRange("ARGON2_ITERATIONS", int(hash.DefaultArgon2Params.Iterations), 1, math.MaxUint32)),
+		Parallelism: uint8(envIntRange("ARGON2_PARALLELISM", int(hash.DefaultArgon2Params.Parallelism), 1, math.MaxUint8)),
+		SaltLength:  hash.DefaultArgon2Params.SaltLength,
+		KeyLength:   hash.DefaultArgon2Params.KeyLength,
+	}
+	if argon2Params.Validate() != nil {
+		log.Fatalf("Invalid ARGON2_MEMORY_KIB: must be at least 8 KiB per thread (ARGON2_PARALLELISM)")
+	}
+	argon2id := hash.NewArgon2idHasher(argon2Params)
+	bcrypt := hash.NewBcryptHasher(envInt("BCRYPT_COST", hash.DefaultCost))
+	var passwordHasher *hash.PasswordHasher
+	switch algorithm := envString("PASSWORD_HASH_ALGORITHM", "argon2id"); algorithm {
+	case "argon2id":
+		passwordHasher = hash.NewPasswordHasher(argon2id, bcrypt)
+	case "bcrypt":
+		passwordHasher = hash.NewPasswordHasher(bcrypt, argon2id)
+	default:
+		log.Fatalf("Unsupported PASSWORD_HASH_ALGORITHM: %s", algorithm)
+	}
+
+	// 初始化密碼策略，可選擇加載本地的已洩露密碼列表
+	passwordPolicy := domainUser.PasswordPolicy{
+		MinLength:        envInt("PASSWORD_MIN_LENGTH", domainUser.DefaultPasswordPolicy.MinLength),
+		MaxLength:        envInt("PASSWORD_MAX_LENGTH", domainUser.DefaultPasswordPolicy.MaxLength),
+		DisallowUsername: true,
+		DisallowEmail:    true,
+	}
+	if path := os.Getenv("BREACHED_PASSWORDS_FILE"); path != "" {
+		checker, err := breach.NewFileChecker(path)
+		if err != nil {
+			log.Fatalf("Failed to load breached password list: %v", err)
+		}
+		passwordPolicy.Breached = checker
+	}
 
 	// 初始化服務層
-	userService := user.NewService(userRepo, jwtService)
-	postService := post.NewService(postRepo)
+	avatarService := avatar.NewService(userRepo, storage.NewLocalStorage(envString("MEDIA_DIR", "./media")))
+	exportSigningKey := os.Getenv("EXPORT_SIGNING_KEY")
+	if exportSigningKey == "" {
+		log.Fatal("EXPORT_SIGNING_KEY is not set in the environment")
+	}
+	exportService := appExport.NewService(exportRepo, appExport.Config{
+		Dir:         envString("EXPORT_DIR", filepath.Join(os.TempDir(), "blog-api-exports")),
+		Retention:   envDuration("EXPORT_RETENTION", 7*24*time.Hour),
+		LinkTTL:     envDuration("EXPORT_LINK_TTL", time.Hour),
+		SigningKey:  []byte(exportSigningKey),
+		Concurrency: envInt("EXPORT_CONCURRENCY", 2),
+		StaleAfter:  envDuration("EXPORT_STALE_AFTER", time.Hour),
+	},
+		appExport.NewProfileSection(userRepo),
+		appExport.NewPostsSection(postRepo),
+		appExport.NewSessionsSection(tokenRepo),
+		appExport.NewMediaSection(avatarService),
+		appExport.NewFollowsSection(followRepo),
+		appExport.NewReactionsSection(reactionRepo),
+		appExport.NewBookmarksSection(bookmarkRepo),
+		appExport.NewAnalyticsSection(postRepo, analyticsRepo),
+	)
+	exportService.StartCleanup(time.Hour)
 
-	// 初始化處理器
-	userHandler := handlers.NewUserHandler(userService)
-	postHandler := handlers.NewPostHandler(postService)
+	// 刪除策略配置錯誤時在啟動時終止，而不是等到用戶刪除賬戶時才失敗
+	deletionPolicy := domainUser.AccountDeletionPolicy(envString("ACCOUNT_DELETION_POLICY", string(domainUser.DeletionAnonymize)))
+	if !deletionPolicy.Valid() {
+		log.Fatalf("Unsupported ACCOUNT_DELETION_POLICY: %s", deletionPolicy)
+	}
+	transferUserID := uint(envIntRange("ACCOUNT_DELETION_TRANSFER_USER_ID", 0, 0, math.MaxInt32))
+	if deletionPolicy == domainUser.DeletionTransfer {
+		if transferUserID == 0 {
+			log.Fatal("ACCOUNT_DELETION_TRANSFER_USER_ID is required when ACCOUNT_DELETION_POLICY is transfer")
+		}
+		if _, err := userRepo.FindByID(context.Background(), transferUserID); err != nil {
+			log.Fatalf("Invalid ACCOUNT_DELETION_TRANSFER_USER_ID: %v", err)
+		}
+	}
+	userService := user.NewService(userRepo, postRepo, txManager, jwtService, passwordHasher, mail.NewLogMailer(), avatarService, exportService, user.Config{
+		PasswordPolicy:       passwordPolicy,
+		EmailVerificationURL: envString("EMAIL_VERIFICATION_URL", "http://localhost:3000/verify-email?token=%s"),
+		DeletionPolicy:       deletionPolicy,
+		TransferUserID:       transferUserID,
+		GhostUsername:        envString("ACCOUNT_DELETION_GHOST_USERNAME", domainUser.DefaultGhostUsername),
+	})
+	postService := post.NewService(postRepo, authorRepo, bookmarkRepo, txManager)
+	bookmarkService := bookmark.NewService(bookmarkRepo, postService)
+	analyticsService := analytics.NewService(analyticsRepo, postRepo)
+	viewRecorder := analytics.NewRecorder(analyticsRepo, analytics.RecorderConfig{
+		DedupWindow:   envDuration("VIEW_DEDUP_WINDOW", 30*time.Minute),
+		FlushInterval: envDuration("VIEW_FLUSH_INTERVAL", 10*time.Second),
+		MaxPending:    envInt("VIEW_MAX_PENDING", 1000),
+		MaxBuffered:   envInt("VIEW_MAX_BUFFERED", 10000),
+		MaxVisitors:   envInt("VIEW_MAX_VISITORS", 100000),
+	})
+	viewRecorder.Start()
+	links := site.NewLinks(envString("SITE_URL", "http://localhost:3000"), envString("PUBLIC_BASE_URL", "http://localhost:8080"))
+	syndicationService := syndication.NewService(postService, authorRepo, links, syndication.Config{
+		Title:       envString("SITE_TITLE", "Blog"),
+		Description: envString("SITE_DESCRIPTION", "Latest posts"),
+		Language:    os.Getenv("SITE_LANGUAGE"),
+		ItemCount:   envInt("FEED_ITEM_COUNT", 20),
+		Excerpt:     envString("FEED_CONTENT", "full") == "excerpt",
+	})
+	sitemapService := sitemap.NewService(sitemapRepo, links, sitemap.Config{
+		TTL: envDuration("SITEMAP_TTL", time.Hour),
+	})
+	postService.OnChange(sitemapService.Invalidate)
+	tokenService := appToken.NewService(tokenRepo)
+	followService := follow.NewService(followRepo, userRepo)
+	reactionService := reaction.NewService(reactionRepo, postRepo)
 
-	// 設置路由
-	r := http.SetupRouter(userHandler, postHandler, jwtService, userService)
+	// 初始化處理器並設置路由
+	r := http.SetupRouter(http.Handlers{
+		User:        handlers.NewUserHandler(userService, followService),
+		Post:        handlers.NewPostHandler(postService, viewRecorder),
+		Token:       handlers.NewTokenHandler(tokenService),
+		JWKS:        handlers.NewJWKSHandler(jwtService),
+		Export:      handlers.NewExportHandler(exportService),
+		Avatar:      handlers.NewAvatarHandler(avatarService),
+		Follow:      handlers.NewFollowHandler(followService),
+		Reaction:    handlers.NewReactionHandler(reactionService),
+		Bookmark:    handlers.NewBookmarkHandler(bookmarkService),
+		Analytics:   handlers.NewAnalyticsHandler(analyticsService),
+		Syndication: handlers.NewSyndicationHandler(syndicationService, links),
+		Sitemap:     handlers.NewSitemapHandler(sitemapService, links),
+	}, jwtService, userService, tokenService, envDuration("REQUEST_TIMEOUT", 30*time.Second))
 
 	// 獲取服務器端口
 	port := os.Getenv("PORT")
@@ -89,8 +257,52 @@ func main() {
 	fmt.Printf("\nSwagger UI is available at: http://localhost:%s/swagger/index.html\n\n", port)
 
 	// 啟動服務器
-	log.Printf("Server is starting on port %s", port)
-	if err := r.Run(":" + port); err != nil {
-		log.Fatalf("Failed to run server: %v", err)
+	server := &netHTTP.Server{Addr: ":" + port, Handler: r}
+	go func() {
+		log.Printf("Server is starting on port %s", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, netHTTP.ErrServerClosed) {
+			log.Fatalf("Failed to run server: %v", err)
+		}
+	}()
+
+	// 收到終止信號後停止接收新請求，等待進行中的請求完成，再寫入緩衝的瀏覽計數
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Println("Shutting down server...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), envDuration("SHUTDOWN_TIMEOUT", 30*time.Second))
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Server forced to shut down: %v", err)
+	}
+	viewRecorder.Stop()
+	log.Println("Server exited")
+}
+
+// openDatabase 根據 DATABASE_URL 連接數據庫
+func openDatabase() *gorm.DB {
+	// 從環境變量獲取數據庫連接信息
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		log.Fatal("DATABASE_URL is not set in the environment")
+	}
+
+	// 配置數據庫連接
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		NowFunc: func() time.Time {
+			return time.Now().Local()
+		},
+		// 將外鍵、唯一約束等數據庫錯誤轉換為 gorm 的通用錯誤
+		TranslateError: true,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	// 單條語句的執行時限，與請求的處理時限疊加
+	if err := db.Use(postgres.NewQueryTimeout(envDuration("DB_QUERY_TIMEOUT", 5*time.Second))); err != nil {
+		log.Fatalf("Failed to configure query timeout: %v", err)
 	}
+	return db
 }
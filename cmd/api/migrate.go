@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"blog-api/internal/infrastructure/migrate"
+	"blog-api/internal/infrastructure/postgres/migrations"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `Usage: api migrate <command>
+
+Commands:
+  up              應用所有未應用的遷移
+  down [steps]    回滾最近的遷移，默認 1 個
+  status          列出所有遷移及其應用時間
+  create <name>   在源碼目錄中創建新的遷移文件
+`
+
+// runMigrate 執行 migrate 子命令
+func runMigrate(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dir := fs.String("dir", migrations.Dir, "migration source directory (create only)")
+	fs.Usage = func() { fmt.Fprint(os.Stderr, migrateUsage) }
+	fs.Parse(args)
+	args = fs.Args()
+	if len(args) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "create":
+		if len(args) != 2 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		paths, err := migrate.Create(*dir, args[1])
+		if err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		for _, path := range paths {
+			fmt.Println("Created", path)
+		}
+	case "up":
+		migrateUp(openDatabase())
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatalf("Invalid number of steps: %s", args[1])
+			}
+			steps = n
+		}
+		reverted, err := newMigrator(openDatabase()).Down(context.Background(), steps)
+		for _, m := range reverted {
+			log.Printf("Reverted migration %04d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Failed to revert migrations: %v", err)
+		}
+	case "status":
+		statuses, err := newMigrator(openDatabase()).Status(context.Background())
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%04d  %-45s %s\n", s.Version, s.Name, applied)
+		}
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// migrateUp 應用所有未應用的遷移，失敗時終止程序
+func migrateUp(db *gorm.DB) {
+	applied, err := newMigrator(db).Up(context.Background())
+	for _, m := range applied {
+		log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+	}
+	if err != nil {
+		log.Fatalf("Failed to apply migrations: %v", err)
+	}
+	if len(applied) == 0 {
+		log.Println("Database schema is up to date")
+	}
+}
+
+// newMigrator 使用嵌入的遷移文件創建 Migrator
+func newMigrator(db *gorm.DB) *migrate.Migrator {
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("Failed to get database handle: %v", err)
+	}
+	m, err := migrate.New(sqlDB, migrations.FS)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	return m
+}
@@ -0,0 +1,261 @@
+package analytics
+
+import (
+	"blog-api/internal/domain/analytics"
+	"container/list"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RecorderConfig 定義瀏覽記錄器的配置
+type RecorderConfig struct {
+	DedupWindow   time.Duration // 同一訪客在該時間窗口內重複瀏覽同一文章只計一次
+	FlushInterval time.Duration // 將緩衝的計數寫入數據庫的間隔
+	MaxPending    int           // 緩衝的計數項達到該數量時提前寫入
+	MaxBuffered   int           // 緩衝的計數項上限，數據庫持續不可用時超出部分被丟棄，默認為 MaxPending 的 10 倍
+	MaxVisitors   int           // 去重記錄的上限，超出時淘汰最早的記錄，默認為 100000
+}
+
+// viewKey 是緩衝計數的聚合鍵
+type viewKey struct {
+	postID   uint
+	day      time.Time
+	referrer string
+}
+
+// seenVisitor 是去重記錄中的一項
+type seenVisitor struct {
+	hash string
+	at   time.Time
+}
+
+// Recorder 在內存中緩衝文章瀏覽並定期批量寫入，避免每次閱讀都訪問數據庫
+// 訪客只以帶密鑰的哈希標識，原始 IP 和 User-Agent 既不存儲也不保留在內存中
+type Recorder struct {
+	repo   analytics.Repository
+	config RecorderConfig
+	salt   []byte
+
+	mu        sync.Mutex
+	pending   map[viewKey]int64
+	seen      map[string]*list.Element // 值為 *seenVisitor
+	seenOrder *list.List               // 按最近瀏覽時間排序，最早的在前
+
+	flushNow chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewRecorder 創建一個新的瀏覽記錄器
+// 哈希密鑰在每次啟動時隨機生成，因此訪客哈希無法跨進程關聯
+func NewRecorder(repo analytics.Repository, config RecorderConfig) *Recorder {
+	if config.DedupWindow <= 0 {
+		config.DedupWindow = 30 * time.Minute
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 10 * time.Second
+	}
+	if config.MaxPending <= 0 {
+		config.MaxPending = 1000
+	}
+	if config.MaxBuffered < config.MaxPending {
+		config.MaxBuffered = 10 * config.MaxPending
+	}
+	if config.MaxVisitors <= 0 {
+		config.MaxVisitors = 100000
+	}
+
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		panic(err)
+	}
+
+	return &Recorder{
+		repo:      repo,
+		config:    config,
+		salt:      salt,
+		pending:   make(map[viewKey]int64),
+		seen:      make(map[string]*list.Element),
+		seenOrder: list.New(),
+		flushNow:  make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Record 記錄一次文章瀏覽，爬蟲和時間窗口內的重複瀏覽會被忽略
+func (r *Recorder) Record(postID uint, ip, userAgent, referrer string) {
+	if analytics.IsBot(userAgent) {
+		return
+	}
+
+	now := time.Now()
+	visitor := r.visitorHash(postID, ip, userAgent)
+	key := viewKey{postID: postID, day: analytics.Day(now), referrer: normalizeReferrer(referrer)}
+
+	r.mu.Lock()
+	if !r.markSeen(visitor, now) {
+		r.mu.Unlock()
+		return
+	}
+	if !r.add(key, 1) {
+		r.mu.Unlock()
+		return
+	}
+	full := len(r.pending) >= r.config.MaxPending
+	r.mu.Unlock()
+
+	if full {
+		select {
+		case r.flushNow <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Start 在後台定期寫入緩衝的計數並清理過期的去重記錄
+func (r *Recorder) Start() {
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.config.FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.Flush()
+				r.purgeSeen()
+			case <-r.flushNow:
+				r.Flush()
+			case <-r.stop:
+				r.Flush()
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止後台任務並寫入剩餘的計數
+func (r *Recorder) Stop() {
+	close(r.stop)
+	<-r.done
+}
+
+// Flush 將緩衝的計數寫入數據庫，寫入失敗時計數會放回緩衝區等待重試
+func (r *Recorder) Flush() {
+	r.mu.Lock()
+	batch := r.pending
+	r.pending = make(map[viewKey]int64)
+	r.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	type dailyKey struct {
+		postID uint
+		day    time.Time
+	}
+	dailyTotals := make(map[dailyKey]int64)
+	referrers := make([]analytics.ReferrerCount, 0, len(batch))
+	for k, views := range batch {
+		dailyTotals[dailyKey{k.postID, k.day}] += views
+		referrers = append(referrers, analytics.ReferrerCount{PostID: k.postID, Day: k.day, Referrer: k.referrer, Views: views})
+	}
+	daily := make([]analytics.DailyCount, 0, len(dailyTotals))
+	for k, views := range dailyTotals {
+		daily = append(daily, analytics.DailyCount{PostID: k.postID, Day: k.day, Views: views})
+	}
+
+	if err := r.repo.Increment(context.Background(), daily, referrers); err != nil {
+		log.Printf("Failed to flush %d view counters: %v", len(batch), err)
+		dropped := 0
+		r.mu.Lock()
+		for k, views := range batch {
+			if !r.add(k, views) {
+				dropped++
+			}
+		}
+		r.mu.Unlock()
+		if dropped > 0 {
+			log.Printf("View buffer is full, dropped %d view counters", dropped)
+		}
+	}
+}
+
+// add 將計數加入緩衝區，緩衝區已滿且是新的計數項時丟棄並返回 false，調用方需持有鎖
+func (r *Recorder) add(key viewKey, views int64) bool {
+	if _, ok := r.pending[key]; !ok && len(r.pending) >= r.config.MaxBuffered {
+		return false
+	}
+	r.pending[key] += views
+	return true
+}
+
+// markSeen 記錄訪客的瀏覽時間，訪客在去重窗口內已瀏覽過時返回 false，調用方需持有鎖
+// 記錄數達到上限時淘汰最早的記錄，大量偽造的訪客只會使部分重複瀏覽被多計，而不會無限佔用內存
+func (r *Recorder) markSeen(visitor string, now time.Time) bool {
+	if el, ok := r.seen[visitor]; ok {
+		v := el.Value.(*seenVisitor)
+		if now.Sub(v.at) < r.config.DedupWindow {
+			return false
+		}
+		v.at = now
+		r.seenOrder.MoveToBack(el)
+		return true
+	}
+
+	if r.seenOrder.Len() >= r.config.MaxVisitors {
+		oldest := r.seenOrder.Front()
+		r.seenOrder.Remove(oldest)
+		delete(r.seen, oldest.Value.(*seenVisitor).hash)
+	}
+	r.seen[visitor] = r.seenOrder.PushBack(&seenVisitor{hash: visitor, at: now})
+	return true
+}
+
+// purgeSeen 清理超出去重窗口的訪客記錄
+func (r *Recorder) purgeSeen() {
+	cutoff := time.Now().Add(-r.config.DedupWindow)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for el := r.seenOrder.Front(); el != nil; el = r.seenOrder.Front() {
+		v := el.Value.(*seenVisitor)
+		if !v.at.Before(cutoff) {
+			return
+		}
+		r.seenOrder.Remove(el)
+		delete(r.seen, v.hash)
+	}
+}
+
+// visitorHash 計算訪客在某篇文章上的匿名標識
+func (r *Recorder) visitorHash(postID uint, ip, userAgent string) string {
+	mac := hmac.New(sha256.New, r.salt)
+	mac.Write([]byte(strconv.FormatUint(uint64(postID), 10) + "\x00" + ip + "\x00" + userAgent))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// normalizeReferrer 只保留來源頁面的主機名，避免存儲包含個人信息的完整 URL
+func normalizeReferrer(referrer string) string {
+	if referrer == "" {
+		return analytics.DirectReferrer
+	}
+	u, err := url.Parse(referrer)
+	if err != nil || u.Hostname() == "" {
+		return analytics.DirectReferrer
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if len(host) > 255 {
+		host = host[:255]
+	}
+	return host
+}
@@ -0,0 +1,270 @@
+package analytics
+
+import (
+	"blog-api/internal/domain/analytics"
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+const browserUA = "Mozilla/5.0 (X11; Linux x86_64) Firefox/131.0"
+
+// fakeRepository 累加寫入的計數，fail 為 true 時寫入失敗
+type fakeRepository struct {
+	analytics.Repository
+	mu          sync.Mutex
+	fail        bool
+	onIncrement func()
+	daily       map[uint]int64
+	referrers   map[string]int64
+}
+
+func (r *fakeRepository) Increment(_ context.Context, daily []analytics.DailyCount, referrers []analytics.ReferrerCount) error {
+	if r.onIncrement != nil {
+		r.onIncrement()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fail {
+		return errors.New("database unavailable")
+	}
+	if r.daily == nil {
+		r.daily = make(map[uint]int64)
+		r.referrers = make(map[string]int64)
+	}
+	for _, d := range daily {
+		r.daily[d.PostID] += d.Views
+	}
+	for _, c := range referrers {
+		r.referrers[c.Referrer] += c.Views
+	}
+	return nil
+}
+
+func (r *fakeRepository) views(postID uint) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.daily[postID]
+}
+
+func TestRecordDeduplicatesVisitors(t *testing.T) {
+	repo := &fakeRepository{}
+	r := NewRecorder(repo, RecorderConfig{})
+
+	r.Record(1, "192.0.2.1", browserUA, "")
+	r.Record(1, "192.0.2.1", browserUA, "https://news.ycombinator.com/item?id=1") // 窗口內重複瀏覽
+	r.Record(1, "192.0.2.2", browserUA, "")
+	r.Record(1, "192.0.2.1", browserUA+" Mobile", "")
+	r.Record(2, "192.0.2.1", browserUA, "")
+	r.Flush()
+
+	if got := repo.views(1); got != 3 {
+		t.Errorf("post 1 views = %d, want 3", got)
+	}
+	if got := repo.views(2); got != 1 {
+		t.Errorf("post 2 views = %d, want 1", got)
+	}
+	if repo.referrers[analytics.DirectReferrer] != 4 || len(repo.referrers) != 1 {
+		t.Errorf("referrers = %v, want 4 direct views", repo.referrers)
+	}
+}
+
+func TestRecordIgnoresBots(t *testing.T) {
+	repo := &fakeRepository{}
+	r := NewRecorder(repo, RecorderConfig{})
+
+	for _, ua := range []string{"", "  ", "Googlebot/2.1", "curl/8.4.0", "Mozilla/5.0 HeadlessChrome/120.0", "python-requests/2.31"} {
+		r.Record(1, "192.0.2.1", ua, "")
+	}
+	r.Flush()
+	if got := repo.views(1); got != 0 {
+		t.Errorf("views = %d, want bots ignored", got)
+	}
+}
+
+func TestFlushAggregatesReferrers(t *testing.T) {
+	repo := &fakeRepository{}
+	r := NewRecorder(repo, RecorderConfig{})
+
+	r.Record(1, "192.0.2.1", browserUA, "https://www.Google.com/search?q=secret")
+	r.Record(1, "192.0.2.2", browserUA, "https://google.com/")
+	r.Record(1, "192.0.2.3", browserUA, "https://lobste.rs/s/abc")
+	r.Flush()
+
+	if got := repo.views(1); got != 3 {
+		t.Errorf("views = %d, want 3", got)
+	}
+	if repo.referrers["google.com"] != 2 || repo.referrers["lobste.rs"] != 1 {
+		t.Errorf("referrers = %v, want google.com 2 and lobste.rs 1", repo.referrers)
+	}
+}
+
+func TestFlushKeepsCountsOnFailure(t *testing.T) {
+	repo := &fakeRepository{fail: true}
+	r := NewRecorder(repo, RecorderConfig{})
+
+	r.Record(1, "192.0.2.1", browserUA, "")
+	r.Record(1, "192.0.2.2", browserUA, "")
+	r.Flush()
+	r.Record(1, "192.0.2.3", browserUA, "")
+
+	repo.fail = false
+	r.Flush()
+	if got := repo.views(1); got != 3 {
+		t.Errorf("views = %d, want the failed batch retried with the new view", got)
+	}
+}
+
+func TestRecordDropsNewCountersWhenBufferIsFull(t *testing.T) {
+	repo := &fakeRepository{}
+	r := NewRecorder(repo, RecorderConfig{MaxPending: 1, MaxBuffered: 2})
+
+	r.Record(1, "192.0.2.1", browserUA, "")
+	r.Record(2, "192.0.2.1", browserUA, "")
+	r.Record(3, "192.0.2.1", browserUA, "") // 新的計數項超出上限，被丟棄
+	r.Record(1, "192.0.2.2", browserUA, "") // 已有的計數項仍然累加
+	r.Flush()
+
+	if repo.views(1) != 2 || repo.views(2) != 1 || repo.views(3) != 0 {
+		t.Errorf("views = %v, want post 3 dropped", repo.daily)
+	}
+}
+
+func TestFlushFailureRespectsMaxBuffered(t *testing.T) {
+	repo := &fakeRepository{fail: true}
+	r := NewRecorder(repo, RecorderConfig{MaxPending: 1, MaxBuffered: 2})
+
+	r.Record(1, "192.0.2.1", browserUA, "")
+	r.Record(2, "192.0.2.1", browserUA, "")
+	// 寫入期間到達的瀏覽填滿了緩衝區，失敗的批次放回時只能保留已有的計數項
+	repo.onIncrement = func() {
+		repo.onIncrement = nil
+		r.Record(2, "192.0.2.2", browserUA, "")
+		r.Record(3, "192.0.2.2", browserUA, "")
+	}
+	r.Flush()
+
+	r.mu.Lock()
+	pending := len(r.pending)
+	r.mu.Unlock()
+	if pending != 2 {
+		t.Fatalf("pending counters = %d, want the buffer capped at 2", pending)
+	}
+
+	repo.fail = false
+	r.Flush()
+	if repo.views(1) != 0 || repo.views(2) != 2 || repo.views(3) != 1 {
+		t.Errorf("views = %v, want post 1 dropped and posts 2 and 3 kept", repo.daily)
+	}
+}
+
+func TestRecordBoundsVisitorMemory(t *testing.T) {
+	repo := &fakeRepository{}
+	r := NewRecorder(repo, RecorderConfig{MaxVisitors: 3})
+
+	for i := 0; i < 100; i++ {
+		r.Record(1, "192.0.2."+strconv.Itoa(i), browserUA, "")
+	}
+	r.mu.Lock()
+	seen, ordered := len(r.seen), r.seenOrder.Len()
+	r.mu.Unlock()
+	if seen != 3 || ordered != 3 {
+		t.Fatalf("visitor records = %d (%d ordered), want at most 3", seen, ordered)
+	}
+
+	// 最近的訪客仍在去重記錄中，最早的訪客已被淘汰，再次瀏覽時重新計數
+	r.Record(1, "192.0.2.99", browserUA, "")
+	r.Record(1, "192.0.2.0", browserUA, "")
+	r.Flush()
+	if got := repo.views(1); got != 101 {
+		t.Errorf("views = %d, want 101", got)
+	}
+}
+
+func TestRecordRefreshesReturningVisitors(t *testing.T) {
+	r := NewRecorder(&fakeRepository{}, RecorderConfig{MaxVisitors: 2})
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.markSeen("a", now.Add(-time.Hour)) || !r.markSeen("b", now.Add(-time.Minute)) {
+		t.Fatal("markSeen rejected new visitors")
+	}
+	// a 的記錄已超出去重窗口，再次瀏覽時計數並成為最新的記錄
+	if !r.markSeen("a", now) {
+		t.Error("markSeen rejected a visitor outside the dedup window")
+	}
+	if r.markSeen("b", now) {
+		t.Error("markSeen accepted a visitor inside the dedup window")
+	}
+	r.markSeen("c", now)
+	if _, ok := r.seen["b"]; ok {
+		t.Error("the oldest visitor was not evicted")
+	}
+	if _, ok := r.seen["a"]; !ok {
+		t.Error("the refreshed visitor was evicted")
+	}
+}
+
+func TestPurgeSeenRemovesExpiredVisitors(t *testing.T) {
+	r := NewRecorder(&fakeRepository{}, RecorderConfig{DedupWindow: time.Minute})
+	now := time.Now()
+
+	r.mu.Lock()
+	r.markSeen("old", now.Add(-2*time.Minute))
+	r.markSeen("recent", now)
+	r.mu.Unlock()
+
+	r.purgeSeen()
+	if _, ok := r.seen["old"]; ok || len(r.seen) != 1 || r.seenOrder.Len() != 1 {
+		t.Errorf("visitor records after purge = %d, want only the recent visitor", len(r.seen))
+	}
+}
+
+func TestStopFlushesPendingViews(t *testing.T) {
+	repo := &fakeRepository{}
+	r := NewRecorder(repo, RecorderConfig{})
+	r.Start()
+
+	r.Record(1, "192.0.2.1", browserUA, "")
+	r.Stop()
+	if got := repo.views(1); got != 1 {
+		t.Errorf("views after Stop = %d, want 1", got)
+	}
+}
+
+func TestNormalizeReferrer(t *testing.T) {
+	tests := map[string]string{
+		"":                                      analytics.DirectReferrer,
+		"not a url":                             analytics.DirectReferrer,
+		"/relative/path":                        analytics.DirectReferrer,
+		"https://WWW.Example.com/a?email=x@y.z": "example.com",
+		"http://blog.example.com:8080/post":     "blog.example.com",
+		"android-app://com.slack/":              "com.slack",
+	}
+	for referrer, want := range tests {
+		if got := normalizeReferrer(referrer); got != want {
+			t.Errorf("normalizeReferrer(%q) = %q, want %q", referrer, got, want)
+		}
+	}
+}
+
+func TestVisitorHashDoesNotContainIdentifiers(t *testing.T) {
+	r := NewRecorder(&fakeRepository{}, RecorderConfig{})
+	other := NewRecorder(&fakeRepository{}, RecorderConfig{})
+
+	hash := r.visitorHash(1, "192.0.2.1", browserUA)
+	if hash != r.visitorHash(1, "192.0.2.1", browserUA) {
+		t.Error("visitorHash is not stable within a process")
+	}
+	if hash == r.visitorHash(2, "192.0.2.1", browserUA) {
+		t.Error("visitorHash is the same for different posts")
+	}
+	// 每個進程使用不同的密鑰，哈希無法跨進程關聯
+	if hash == other.visitorHash(1, "192.0.2.1", browserUA) {
+		t.Error("visitorHash is the same across recorders")
+	}
+}
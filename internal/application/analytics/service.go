@@ -0,0 +1,79 @@
+package analytics
+
+import (
+	"blog-api/internal/domain/analytics"
+	"blog-api/internal/domain/post"
+	"context"
+	"time"
+)
+
+// 統計查詢的範圍限制
+const (
+	DefaultDays   = 30
+	MaxDays       = 365
+	referrerLimit = 20
+	dateLayout    = "2006-01-02"
+)
+
+// Service 封裝了文章瀏覽統計相關的業務邏輯
+type Service struct {
+	repo  analytics.Repository
+	posts post.Repository
+}
+
+// NewService 創建一個新的統計服務實例
+func NewService(repo analytics.Repository, posts post.Repository) *Service {
+	return &Service{repo: repo, posts: posts}
+}
+
+// GetPostStats 獲取文章最近若干天的瀏覽統計，只有作者可以查看
+// 時間序列包含沒有瀏覽的日期，方便直接繪圖
+func (s *Service) GetPostStats(ctx context.Context, postID, userID uint, days int) (*analytics.PostStats, error) {
+	p, err := s.posts.FindByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if !p.IsAuthor(userID) {
+		return nil, post.ErrUnauthorized
+	}
+
+	if days <= 0 {
+		days = DefaultDays
+	}
+	if days > MaxDays {
+		days = MaxDays
+	}
+	to := analytics.Day(time.Now())
+	from := to.AddDate(0, 0, -(days - 1))
+
+	counts, err := s.repo.FindDaily(ctx, postID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	referrers, err := s.repo.FindReferrers(ctx, postID, from, to, referrerLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	byDay := make(map[string]int64, len(counts))
+	for _, c := range counts {
+		byDay[c.Day.Format(dateLayout)] = c.Views
+	}
+
+	stats := &analytics.PostStats{
+		PostID:    postID,
+		From:      from.Format(dateLayout),
+		To:        to.Format(dateLayout),
+		Daily:     make([]analytics.DailyViews, 0, days),
+		Referrers: referrers,
+	}
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		date := d.Format(dateLayout)
+		stats.Daily = append(stats.Daily, analytics.DailyViews{Date: date, Views: byDay[date]})
+		stats.TotalViews += byDay[date]
+	}
+	if stats.Referrers == nil {
+		stats.Referrers = []analytics.ReferrerViews{}
+	}
+	return stats, nil
+}
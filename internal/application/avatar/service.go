@@ -0,0 +1,164 @@
+package avatar
+
+import (
+	"blog-api/internal/domain/user"
+	"blog-api/internal/infrastructure/imaging"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strconv"
+)
+
+// ErrNotFound 表示請求的頭像文件不存在
+var ErrNotFound = errors.New("avatar not found")
+
+// fileNamePattern 頭像文件名格式：<十六進制哈希>-<尺寸>.png
+var fileNamePattern = regexp.MustCompile(`^([0-9a-f]{16})-([0-9]+)\.png$`)
+
+// Storage 定義頭像文件存儲的接口
+type Storage interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+}
+
+// Service 封裝了頭像相關的業務邏輯
+type Service struct {
+	users   user.Repository
+	storage Storage
+}
+
+// NewService 創建一個新的頭像服務實例
+func NewService(users user.Repository, storage Storage) *Service {
+	return &Service{users: users, storage: storage}
+}
+
+// Upload 處理上傳的頭像：裁剪為正方形並生成各個尺寸，返回新的頭像 URL
+func (s *Service) Upload(ctx context.Context, userID uint, r io.Reader) (string, error) {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	thumbnails, err := imaging.SquareThumbnails(r, user.AvatarSizes)
+	if err != nil {
+		return "", err
+	}
+
+	// 以最大尺寸的內容計算哈希，作為所有尺寸共用的 URL 標識
+	sum := sha256.Sum256(thumbnails[user.AvatarSizes[len(user.AvatarSizes)-1]])
+	contentHash := hex.EncodeToString(sum[:8])
+	for size, data := range thumbnails {
+		if err := s.storage.Put(storageKey(contentHash, size), data); err != nil {
+			return "", err
+		}
+	}
+
+	// 圖片處理期間用戶的其他字段可能已被修改，因此只寫入頭像哈希
+	previous, err := s.users.SetAvatarHash(ctx, userID, contentHash)
+	if err != nil {
+		return "", err
+	}
+	if previous != contentHash {
+		s.RemoveFiles(ctx, previous)
+	}
+	return user.AvatarURL(u.Username, contentHash), nil
+}
+
+// Remove 移除用戶上傳的頭像，之後將使用生成的默認頭像
+func (s *Service) Remove(ctx context.Context, userID uint) error {
+	previous, err := s.users.SetAvatarHash(ctx, userID, "")
+	if err != nil {
+		return err
+	}
+	s.RemoveFiles(ctx, previous)
+	return nil
+}
+
+// RemoveFiles 刪除頭像的所有尺寸文件，應在用戶記錄不再引用該頭像之後調用
+// 相同的圖片在不同用戶之間共用文件，仍有用戶使用時保留；刪除失敗只記錄日誌
+func (s *Service) RemoveFiles(ctx context.Context, contentHash string) {
+	if contentHash == "" {
+		return
+	}
+	count, err := s.users.CountByAvatarHash(ctx, contentHash)
+	if err != nil {
+		log.Printf("Failed to check avatar %s usage: %v", contentHash, err)
+		return
+	}
+	if count > 0 {
+		return
+	}
+	for _, size := range user.AvatarSizes {
+		if err := s.storage.Delete(storageKey(contentHash, size)); err != nil {
+			log.Printf("Failed to remove avatar %s: %v", storageKey(contentHash, size), err)
+		}
+	}
+}
+
+// Uploaded 讀取上傳頭像的指定文件
+func (s *Service) Uploaded(fileName string) ([]byte, error) {
+	contentHash, size, err := parseFileName(fileName)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.storage.Get(storageKey(contentHash, size))
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	return data, nil
+}
+
+// UploadedOriginals 返回用戶上傳頭像的所有尺寸，用於個人數據導出
+func (s *Service) UploadedOriginals(ctx context.Context, userID uint) (map[int][]byte, error) {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[int][]byte)
+	if u.AvatarHash == "" {
+		return files, nil
+	}
+	for _, size := range user.AvatarSizes {
+		data, err := s.storage.Get(storageKey(u.AvatarHash, size))
+		if err != nil {
+			return nil, err
+		}
+		files[size] = data
+	}
+	return files, nil
+}
+
+// Identicon 根據文件名生成默認頭像，文件名中的標識由用戶名決定，因此不需要查詢數據庫
+func (s *Service) Identicon(fileName string) ([]byte, error) {
+	key, size, err := parseFileName(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return imaging.Identicon(key, size)
+}
+
+// parseFileName 解析並驗證頭像文件名
+func parseFileName(fileName string) (string, int, error) {
+	m := fileNamePattern.FindStringSubmatch(fileName)
+	if m == nil {
+		return "", 0, ErrNotFound
+	}
+	size, _ := strconv.Atoi(m[2])
+	for _, allowed := range user.AvatarSizes {
+		if size == allowed {
+			return m[1], size, nil
+		}
+	}
+	return "", 0, ErrNotFound
+}
+
+// storageKey 返回頭像文件在存儲中的路徑
+func storageKey(contentHash string, size int) string {
+	return fmt.Sprintf("avatars/%s-%d.png", contentHash, size)
+}
@@ -0,0 +1,78 @@
+package bookmark
+
+import (
+	appPost "blog-api/internal/application/post"
+	"blog-api/internal/domain/bookmark"
+	"context"
+)
+
+// Service 封裝了收藏相關的業務邏輯
+type Service struct {
+	repo  bookmark.Repository
+	posts *appPost.Service
+}
+
+// NewService 創建一個新的收藏服務實例
+func NewService(repo bookmark.Repository, posts *appPost.Service) *Service {
+	return &Service{repo: repo, posts: posts}
+}
+
+// SaveInput 收藏文章時的可選信息
+type SaveInput struct {
+	Folder string
+	Note   string
+}
+
+// Save 收藏文章，已收藏時更新收藏夾和備註
+func (s *Service) Save(ctx context.Context, userID, postID uint, input SaveInput) (*bookmark.Bookmark, error) {
+	p, err := s.posts.GetPostByID(ctx, postID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &bookmark.Bookmark{UserID: userID, PostID: postID, Folder: input.Folder, Note: input.Note}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, b); err != nil {
+		return nil, err
+	}
+
+	isBookmarked := true
+	p.IsBookmarked = &isBookmarked
+	b.Post = p
+	return b, nil
+}
+
+// Remove 取消收藏文章
+func (s *Service) Remove(ctx context.Context, userID, postID uint) error {
+	return s.repo.Delete(ctx, userID, postID)
+}
+
+// List 獲取用戶的收藏列表，folder 為 nil 時返回所有收藏夾
+// 文章已被刪除的收藏不包含文章內容
+func (s *Service) List(ctx context.Context, userID uint, folder *string, beforeID uint, limit int) ([]bookmark.Bookmark, error) {
+	bookmarks, err := s.repo.FindByUserID(ctx, userID, folder, beforeID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(bookmarks))
+	for i, b := range bookmarks {
+		ids[i] = b.PostID
+	}
+	posts, err := s.posts.GetPostsByIDs(ctx, ids, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range bookmarks {
+		bookmarks[i].Post = posts[bookmarks[i].PostID]
+	}
+	return bookmarks, nil
+}
+
+// Folders 獲取用戶的收藏夾列表
+func (s *Service) Folders(ctx context.Context, userID uint) ([]bookmark.Folder, error) {
+	return s.repo.FindFolders(ctx, userID)
+}
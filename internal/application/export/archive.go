@@ -0,0 +1,41 @@
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Archive 封裝導出檔案的寫入，每個部分可以寫入 JSON 文件或原始文件（如上傳的媒體）
+type Archive struct {
+	zw *zip.Writer
+}
+
+// WriteJSON 將數據以縮進的 JSON 格式寫入檔案中的指定文件
+func (a *Archive) WriteJSON(name string, v interface{}) error {
+	w, err := a.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// WriteFile 將原始內容寫入檔案中的指定文件
+func (a *Archive) WriteFile(name string, r io.Reader) error {
+	w, err := a.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// Section 是導出檔案中的一個部分，新的數據類型通過實現此接口加入導出
+type Section interface {
+	Name() string
+	Write(ctx context.Context, userID uint, archive *Archive) error
+}
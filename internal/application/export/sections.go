@@ -0,0 +1,303 @@
+package export
+
+import (
+	"blog-api/internal/domain/analytics"
+	"blog-api/internal/domain/bookmark"
+	"blog-api/internal/domain/follow"
+	"blog-api/internal/domain/post"
+	"blog-api/internal/domain/reaction"
+	"blog-api/internal/domain/token"
+	"blog-api/internal/domain/user"
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+)
+
+// exportPageSize 分頁讀取數據時每頁的數量
+const exportPageSize = 100
+
+// dateLayout 瀏覽統計中日期的格式
+const dateLayout = "2006-01-02"
+
+// ProfileSection 導出用戶資料
+type ProfileSection struct {
+	repo user.Repository
+}
+
+// NewProfileSection 創建一個新的 ProfileSection 實例
+func NewProfileSection(repo user.Repository) *ProfileSection {
+	return &ProfileSection{repo: repo}
+}
+
+// Name 返回部分名稱
+func (s *ProfileSection) Name() string { return "profile" }
+
+// Write 將用戶資料寫入 profile.json
+func (s *ProfileSection) Write(ctx context.Context, userID uint, archive *Archive) error {
+	u, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return archive.WriteJSON("profile.json", u)
+}
+
+// PostsSection 導出用戶撰寫的所有文章
+type PostsSection struct {
+	repo post.Repository
+}
+
+// NewPostsSection 創建一個新的 PostsSection 實例
+func NewPostsSection(repo post.Repository) *PostsSection {
+	return &PostsSection{repo: repo}
+}
+
+// Name 返回部分名稱
+func (s *PostsSection) Name() string { return "posts" }
+
+// Write 將所有文章寫入 posts.json
+func (s *PostsSection) Write(ctx context.Context, userID uint, archive *Archive) error {
+	all := []post.Post{}
+	for page := 1; ; page++ {
+		posts, err := s.repo.FindByUserID(ctx, userID, page, exportPageSize)
+		if err != nil {
+			return err
+		}
+		all = append(all, posts...)
+		if len(posts) < exportPageSize {
+			break
+		}
+	}
+	return archive.WriteJSON("posts.json", all)
+}
+
+// SessionsSection 導出用戶的訪問令牌記錄（不包含令牌哈希）
+type SessionsSection struct {
+	repo token.Repository
+}
+
+// NewSessionsSection 創建一個新的 SessionsSection 實例
+func NewSessionsSection(repo token.Repository) *SessionsSection {
+	return &SessionsSection{repo: repo}
+}
+
+// Name 返回部分名稱
+func (s *SessionsSection) Name() string { return "sessions" }
+
+// Write 將訪問令牌記錄寫入 sessions.json
+func (s *SessionsSection) Write(ctx context.Context, userID uint, archive *Archive) error {
+	tokens, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if tokens == nil {
+		tokens = []token.AccessToken{}
+	}
+	return archive.WriteJSON("sessions.json", tokens)
+}
+
+// AvatarSource 定義讀取用戶上傳頭像的接口
+type AvatarSource interface {
+	UploadedOriginals(ctx context.Context, userID uint) (map[int][]byte, error)
+}
+
+// MediaSection 導出用戶上傳的媒體文件
+type MediaSection struct {
+	avatars AvatarSource
+}
+
+// NewMediaSection 創建一個新的 MediaSection 實例
+func NewMediaSection(avatars AvatarSource) *MediaSection {
+	return &MediaSection{avatars: avatars}
+}
+
+// Name 返回部分名稱
+func (s *MediaSection) Name() string { return "media" }
+
+// Write 將上傳的頭像寫入 media 目錄
+func (s *MediaSection) Write(ctx context.Context, userID uint, archive *Archive) error {
+	files, err := s.avatars.UploadedOriginals(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for size, data := range files {
+		if err := archive.WriteFile(fmt.Sprintf("media/avatar-%d.png", size), bytes.NewReader(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FollowsSection 導出用戶關注的作者和用戶的關注者
+type FollowsSection struct {
+	repo follow.Repository
+}
+
+// NewFollowsSection 創建一個新的 FollowsSection 實例
+func NewFollowsSection(repo follow.Repository) *FollowsSection {
+	return &FollowsSection{repo: repo}
+}
+
+// Name 返回部分名稱
+func (s *FollowsSection) Name() string { return "follows" }
+
+// followsExport 是 follows.json 的內容，其他用戶只包含公開資料
+type followsExport struct {
+	Following []*user.PublicProfile `json:"following"`
+	Followers []*user.PublicProfile `json:"followers"`
+}
+
+// Write 將關注關係寫入 follows.json
+func (s *FollowsSection) Write(ctx context.Context, userID uint, archive *Archive) error {
+	following, err := s.profiles(ctx, userID, s.repo.FindFollowing)
+	if err != nil {
+		return err
+	}
+	followers, err := s.profiles(ctx, userID, s.repo.FindFollowers)
+	if err != nil {
+		return err
+	}
+	return archive.WriteJSON("follows.json", followsExport{Following: following, Followers: followers})
+}
+
+// profiles 分頁讀取所有用戶並轉換為公開資料
+func (s *FollowsSection) profiles(ctx context.Context, userID uint, find func(context.Context, uint, int, int) ([]user.User, error)) ([]*user.PublicProfile, error) {
+	profiles := []*user.PublicProfile{}
+	for page := 1; ; page++ {
+		users, err := find(ctx, userID, page, exportPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for i := range users {
+			profiles = append(profiles, users[i].PublicProfile())
+		}
+		if len(users) < exportPageSize {
+			return profiles, nil
+		}
+	}
+}
+
+// ReactionsSection 導出用戶對文章的回應
+type ReactionsSection struct {
+	repo reaction.Repository
+}
+
+// NewReactionsSection 創建一個新的 ReactionsSection 實例
+func NewReactionsSection(repo reaction.Repository) *ReactionsSection {
+	return &ReactionsSection{repo: repo}
+}
+
+// Name 返回部分名稱
+func (s *ReactionsSection) Name() string { return "reactions" }
+
+// Write 將所有回應寫入 reactions.json
+func (s *ReactionsSection) Write(ctx context.Context, userID uint, archive *Archive) error {
+	all := []reaction.Reaction{}
+	for page := 1; ; page++ {
+		reactions, err := s.repo.FindByUserID(ctx, userID, page, exportPageSize)
+		if err != nil {
+			return err
+		}
+		all = append(all, reactions...)
+		if len(reactions) < exportPageSize {
+			break
+		}
+	}
+	return archive.WriteJSON("reactions.json", all)
+}
+
+// BookmarksSection 導出用戶的收藏及備註
+type BookmarksSection struct {
+	repo bookmark.Repository
+}
+
+// NewBookmarksSection 創建一個新的 BookmarksSection 實例
+func NewBookmarksSection(repo bookmark.Repository) *BookmarksSection {
+	return &BookmarksSection{repo: repo}
+}
+
+// Name 返回部分名稱
+func (s *BookmarksSection) Name() string { return "bookmarks" }
+
+// Write 將所有收藏寫入 bookmarks.json
+func (s *BookmarksSection) Write(ctx context.Context, userID uint, archive *Archive) error {
+	all := []bookmark.Bookmark{}
+	var beforeID uint
+	for {
+		bookmarks, err := s.repo.FindByUserID(ctx, userID, nil, beforeID, exportPageSize)
+		if err != nil {
+			return err
+		}
+		all = append(all, bookmarks...)
+		if len(bookmarks) < exportPageSize {
+			break
+		}
+		beforeID = bookmarks[len(bookmarks)-1].ID
+	}
+	return archive.WriteJSON("bookmarks.json", all)
+}
+
+// AnalyticsSection 導出用戶文章的瀏覽統計
+type AnalyticsSection struct {
+	posts post.Repository
+	views analytics.Repository
+}
+
+// NewAnalyticsSection 創建一個新的 AnalyticsSection 實例
+func NewAnalyticsSection(posts post.Repository, views analytics.Repository) *AnalyticsSection {
+	return &AnalyticsSection{posts: posts, views: views}
+}
+
+// Name 返回部分名稱
+func (s *AnalyticsSection) Name() string { return "analytics" }
+
+// Write 將每篇文章自發表以來的瀏覽統計寫入 analytics.json，只包含有瀏覽的日期
+func (s *AnalyticsSection) Write(ctx context.Context, userID uint, archive *Archive) error {
+	to := analytics.Day(time.Now())
+	all := []analytics.PostStats{}
+	for page := 1; ; page++ {
+		posts, err := s.posts.FindByUserID(ctx, userID, page, exportPageSize)
+		if err != nil {
+			return err
+		}
+		for _, p := range posts {
+			stats, err := s.postStats(ctx, p.ID, analytics.Day(p.CreatedAt), to)
+			if err != nil {
+				return err
+			}
+			all = append(all, *stats)
+		}
+		if len(posts) < exportPageSize {
+			break
+		}
+	}
+	return archive.WriteJSON("analytics.json", all)
+}
+
+// postStats 讀取一篇文章在日期範圍內的全部統計
+func (s *AnalyticsSection) postStats(ctx context.Context, postID uint, from, to time.Time) (*analytics.PostStats, error) {
+	counts, err := s.views.FindDaily(ctx, postID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	referrers, err := s.views.FindReferrers(ctx, postID, from, to, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &analytics.PostStats{
+		PostID:    postID,
+		From:      from.Format(dateLayout),
+		To:        to.Format(dateLayout),
+		Daily:     make([]analytics.DailyViews, len(counts)),
+		Referrers: referrers,
+	}
+	for i, c := range counts {
+		stats.Daily[i] = analytics.DailyViews{Date: c.Day.Format(dateLayout), Views: c.Views}
+		stats.TotalViews += c.Views
+	}
+	if stats.Referrers == nil {
+		stats.Referrers = []analytics.ReferrerViews{}
+	}
+	return stats, nil
+}
@@ -0,0 +1,196 @@
+package export
+
+import (
+	"archive/zip"
+	"blog-api/internal/domain/analytics"
+	"blog-api/internal/domain/bookmark"
+	"blog-api/internal/domain/follow"
+	"blog-api/internal/domain/post"
+	"blog-api/internal/domain/reaction"
+	"blog-api/internal/domain/user"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// writeSection 將部分寫入內存中的檔案並解析指定的 JSON 文件
+func writeSection(t *testing.T, section Section, name string, v interface{}) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if err := section.Write(context.Background(), 1, &Archive{zw: zw}); err != nil {
+		t.Fatalf("%s.Write: %v", section.Name(), err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatalf("archive has no %s: %v", name, err)
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		t.Fatalf("decode %s: %v", name, err)
+	}
+}
+
+type sectionFollows struct {
+	follow.Repository
+	following, followers int
+}
+
+func (r sectionFollows) users(total, page, pageSize int) []user.User {
+	var users []user.User
+	for i := (page - 1) * pageSize; i < total && i < page*pageSize; i++ {
+		users = append(users, user.User{ID: uint(i + 2), Username: "user", Email: "private@example.com"})
+	}
+	return users
+}
+
+func (r sectionFollows) FindFollowing(_ context.Context, _ uint, page, pageSize int) ([]user.User, error) {
+	return r.users(r.following, page, pageSize), nil
+}
+
+func (r sectionFollows) FindFollowers(_ context.Context, _ uint, page, pageSize int) ([]user.User, error) {
+	return r.users(r.followers, page, pageSize), nil
+}
+
+func TestFollowsSectionWritesPublicProfiles(t *testing.T) {
+	var got map[string][]map[string]interface{}
+	writeSection(t, NewFollowsSection(sectionFollows{following: exportPageSize + 1, followers: 0}), "follows.json", &got)
+
+	if len(got["following"]) != exportPageSize+1 {
+		t.Errorf("following = %d, want %d", len(got["following"]), exportPageSize+1)
+	}
+	if got["followers"] == nil || len(got["followers"]) != 0 {
+		t.Errorf("followers = %v, want an empty list", got["followers"])
+	}
+	if _, ok := got["following"][0]["email"]; ok {
+		t.Error("followed users' emails are exported")
+	}
+}
+
+type sectionReactions struct {
+	reaction.Repository
+	total int
+}
+
+func (r sectionReactions) FindByUserID(_ context.Context, userID uint, page, pageSize int) ([]reaction.Reaction, error) {
+	var reactions []reaction.Reaction
+	for i := (page - 1) * pageSize; i < r.total && i < page*pageSize; i++ {
+		reactions = append(reactions, reaction.Reaction{PostID: uint(i + 1), UserID: userID, Type: reaction.TypeLike})
+	}
+	return reactions, nil
+}
+
+func TestReactionsSectionReadsAllPages(t *testing.T) {
+	var got []reaction.Reaction
+	writeSection(t, NewReactionsSection(sectionReactions{total: 2*exportPageSize + 3}), "reactions.json", &got)
+	if len(got) != 2*exportPageSize+3 {
+		t.Errorf("reactions = %d, want %d", len(got), 2*exportPageSize+3)
+	}
+}
+
+type sectionBookmarks struct {
+	bookmark.Repository
+	total    uint
+	beforeID []uint
+}
+
+func (r *sectionBookmarks) FindByUserID(_ context.Context, userID uint, folder *string, beforeID uint, limit int) ([]bookmark.Bookmark, error) {
+	r.beforeID = append(r.beforeID, beforeID)
+	if folder != nil {
+		return nil, nil
+	}
+	id := r.total
+	if beforeID > 0 {
+		id = beforeID - 1
+	}
+	var bookmarks []bookmark.Bookmark
+	for ; id > 0 && len(bookmarks) < limit; id-- {
+		bookmarks = append(bookmarks, bookmark.Bookmark{ID: id, UserID: userID, PostID: id, Note: "note"})
+	}
+	return bookmarks, nil
+}
+
+func TestBookmarksSectionFollowsTheCursor(t *testing.T) {
+	repo := &sectionBookmarks{total: exportPageSize + 5}
+	var got []bookmark.Bookmark
+	writeSection(t, NewBookmarksSection(repo), "bookmarks.json", &got)
+
+	if len(got) != exportPageSize+5 {
+		t.Errorf("bookmarks = %d, want %d", len(got), exportPageSize+5)
+	}
+	if len(repo.beforeID) != 2 || repo.beforeID[0] != 0 || repo.beforeID[1] != 6 {
+		t.Errorf("cursors = %v, want [0 6]", repo.beforeID)
+	}
+}
+
+type sectionPosts struct {
+	post.Repository
+	posts []post.Post
+}
+
+func (r sectionPosts) FindByUserID(_ context.Context, _ uint, page, pageSize int) ([]post.Post, error) {
+	if page > 1 {
+		return nil, nil
+	}
+	return r.posts, nil
+}
+
+type sectionViews struct {
+	analytics.Repository
+	from  map[uint]time.Time
+	limit int
+}
+
+func (r *sectionViews) FindDaily(_ context.Context, postID uint, from, _ time.Time) ([]analytics.DailyCount, error) {
+	r.from[postID] = from
+	if postID != 1 {
+		return nil, nil
+	}
+	return []analytics.DailyCount{
+		{PostID: 1, Day: time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC), Views: 3},
+		{PostID: 1, Day: time.Date(2024, 10, 5, 0, 0, 0, 0, time.UTC), Views: 4},
+	}, nil
+}
+
+func (r *sectionViews) FindReferrers(_ context.Context, postID uint, _, _ time.Time, limit int) ([]analytics.ReferrerViews, error) {
+	r.limit = limit
+	if postID != 1 {
+		return nil, nil
+	}
+	return []analytics.ReferrerViews{{Referrer: analytics.DirectReferrer, Views: 7}}, nil
+}
+
+func TestAnalyticsSectionExportsEveryPostSinceCreation(t *testing.T) {
+	created := time.Date(2024, 9, 30, 22, 0, 0, 0, time.UTC)
+	posts := sectionPosts{posts: []post.Post{{ID: 1, CreatedAt: created}, {ID: 2, CreatedAt: created}}}
+	views := &sectionViews{from: make(map[uint]time.Time)}
+
+	var got []analytics.PostStats
+	writeSection(t, NewAnalyticsSection(posts, views), "analytics.json", &got)
+
+	if len(got) != 2 {
+		t.Fatalf("stats = %d posts, want 2", len(got))
+	}
+	if got[0].TotalViews != 7 || len(got[0].Daily) != 2 || got[0].Daily[1].Date != "2024-10-05" {
+		t.Errorf("post 1 stats = %+v", got[0])
+	}
+	if got[0].From != "2024-09-30" || !views.from[1].Equal(analytics.Day(created)) {
+		t.Errorf("from = %s (%v), want the creation day", got[0].From, views.from[1])
+	}
+	if views.limit > 0 {
+		t.Errorf("referrer limit = %d, want all referrers", views.limit)
+	}
+	if got[1].TotalViews != 0 || got[1].Daily == nil || got[1].Referrers == nil {
+		t.Errorf("post 2 stats = %+v, want empty lists", got[1])
+	}
+}
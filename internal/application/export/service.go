@@ -0,0 +1,339 @@
+package export
+
+import (
+	"archive/zip"
+	"blog-api/internal/domain/export"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Config 定義導出服務的配置
+type Config struct {
+	Dir         string        // 導出檔案的保存目錄
+	Retention   time.Duration // 檔案保留時長
+	LinkTTL     time.Duration // 下載鏈接的有效期
+	SigningKey  []byte        // 用於簽名下載鏈接的密鑰
+	Concurrency int           // 同時執行的導出任務數量
+	StaleAfter  time.Duration // 不屬於本進程的未完成任務超過此時長視為已中斷，例如進程在執行中重啟
+}
+
+// Service 封裝了個人數據導出相關的業務邏輯
+type Service struct {
+	repo     export.Repository
+	sections []Section
+	config   Config
+	slots    chan struct{}
+
+	mu     sync.Mutex
+	active map[uint]*activeJob // 本進程中等待或正在執行的任務
+}
+
+// activeJob 記錄本進程中的任務，刪除賬戶時用於取消
+type activeJob struct {
+	userID uint
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewService 創建一個新的導出服務實例
+func NewService(repo export.Repository, config Config, sections ...Section) *Service {
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	if config.StaleAfter <= 0 {
+		config.StaleAfter = time.Hour
+	}
+	return &Service{
+		repo:     repo,
+		sections: sections,
+		config:   config,
+		slots:    make(chan struct{}, config.Concurrency),
+		active:   make(map[uint]*activeJob),
+	}
+}
+
+// DownloadLink 包含已簽名的下載參數
+type DownloadLink struct {
+	Expires   int64  `json:"expires" example:"1729436400"`
+	Signature string `json:"signature" example:"3b1f..."`
+}
+
+// Request 為用戶創建導出任務並在後台執行
+// 預先檢查只用於返回更明確的錯誤，並發請求由倉庫的唯一約束拒絕
+func (s *Service) Request(ctx context.Context, userID uint) (*export.Job, error) {
+	if _, err := s.repo.FindActiveByUserID(ctx, userID); err == nil {
+		return nil, export.ErrJobAlreadyRunning
+	} else if !errors.Is(err, export.ErrJobNotFound) {
+		return nil, err
+	}
+
+	job := &export.Job{UserID: userID, Status: export.StatusPending}
+	if err := s.repo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.active[job.ID] = &activeJob{userID: userID, cancel: cancel, done: make(chan struct{})}
+	s.mu.Unlock()
+	go s.run(runCtx, *job)
+	return job, nil
+}
+
+// RemoveArchives 取消用戶在本進程中的導出任務並刪除其所有導出檔案
+// 在刪除賬戶的事務提交之後調用；檔案無法隨事務回滾，刪除失敗只記錄日誌
+func (s *Service) RemoveArchives(ctx context.Context, userID uint) {
+	var cancelled []*activeJob
+	s.mu.Lock()
+	for _, job := range s.active {
+		if job.userID == userID {
+			job.cancel()
+			cancelled = append(cancelled, job)
+		}
+	}
+	s.mu.Unlock()
+	// 等待任務退出，避免在刪除之後才寫出檔案
+	for _, job := range cancelled {
+		select {
+		case <-job.done:
+		case <-ctx.Done():
+		}
+	}
+
+	paths, err := filepath.Glob(filepath.Join(s.config.Dir, fmt.Sprintf("export-%d-*.zip", userID)))
+	if err != nil {
+		log.Printf("Failed to list exports of user %d: %v", userID, err)
+	}
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove export %s: %v", path, err)
+		}
+	}
+	// 匿名化的賬戶仍然保留任務記錄，將其標記為已過期以拒絕下載
+	if err := s.repo.ExpireByUserID(ctx, userID); err != nil {
+		log.Printf("Failed to expire exports of user %d: %v", userID, err)
+	}
+}
+
+// GetJob 獲取用戶的導出任務
+func (s *Service) GetJob(ctx context.Context, userID, id uint) (*export.Job, error) {
+	job, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.UserID != userID {
+		return nil, export.ErrJobNotFound
+	}
+	return job, nil
+}
+
+// SignDownload 為已完成的任務生成有時效的下載鏈接參數
+func (s *Service) SignDownload(job *export.Job) (*DownloadLink, error) {
+	if err := job.Downloadable(time.Now()); err != nil {
+		return nil, err
+	}
+	expires := time.Now().Add(s.config.LinkTTL)
+	if job.ExpiresAt != nil && job.ExpiresAt.Before(expires) {
+		expires = *job.ExpiresAt
+	}
+	return &DownloadLink{Expires: expires.Unix(), Signature: s.sign(job.ID, expires.Unix())}, nil
+}
+
+// OpenDownload 驗證下載鏈接的簽名並返回檔案路徑
+func (s *Service) OpenDownload(ctx context.Context, id uint, expires int64, signature string) (string, error) {
+	expected := s.sign(id, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return "", export.ErrInvalidSignature
+	}
+	if time.Now().Unix() > expires {
+		return "", export.ErrExportExpired
+	}
+
+	job, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if err := job.Downloadable(time.Now()); err != nil {
+		return "", err
+	}
+	return job.FilePath, nil
+}
+
+// StartCleanup 定期刪除已過期的導出檔案並結束已中斷的任務
+// 啟動時立即執行一次，以便恢復上次進程退出時未完成的任務
+func (s *Service) StartCleanup(interval time.Duration) {
+	go func() {
+		s.cleanup()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.cleanup()
+		}
+	}()
+}
+
+// cleanup 刪除過期的檔案並更新任務狀態
+func (s *Service) cleanup() {
+	ctx := context.Background()
+	s.failStale(ctx)
+
+	jobs, err := s.repo.FindExpired(ctx, time.Now())
+	if err != nil {
+		log.Printf("Failed to find expired exports: %v", err)
+		return
+	}
+	for i := range jobs {
+		job := &jobs[i]
+		if err := os.Remove(job.FilePath); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove export %d: %v", job.ID, err)
+			continue
+		}
+		job.Expire()
+		if err := s.repo.Update(ctx, job); err != nil {
+			log.Printf("Failed to mark export %d as expired: %v", job.ID, err)
+		}
+	}
+}
+
+// failStale 將長時間未完成的任務標記為失敗
+// 任務在進程內的 goroutine 中執行，進程退出後不會再被處理，不結束它們用戶將無法再次請求導出；
+// 本進程仍在等待或執行的任務不受影響，無論已經運行了多久
+func (s *Service) failStale(ctx context.Context) {
+	jobs, err := s.repo.FindStale(ctx, time.Now().Add(-s.config.StaleAfter))
+	if err != nil {
+		log.Printf("Failed to find stale exports: %v", err)
+		return
+	}
+	for i := range jobs {
+		job := &jobs[i]
+		if s.isActive(job.ID) {
+			continue
+		}
+		// 查詢之後任務可能已經完成，只結束仍未完成的任務
+		failed, err := s.repo.FailUnfinished(ctx, job.ID, export.ErrJobInterrupted)
+		if err != nil {
+			log.Printf("Failed to mark export %d as failed: %v", job.ID, err)
+			continue
+		}
+		if !failed {
+			continue
+		}
+		// 刪除中斷時可能留下的不完整檔案
+		if err := os.Remove(s.archivePath(*job)); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove partial export %d: %v", job.ID, err)
+		}
+	}
+}
+
+// isActive 檢查任務是否由本進程等待或執行
+func (s *Service) isActive(id uint) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active[id] != nil
+}
+
+// run 執行導出任務，同時執行的任務數量受 Concurrency 限制
+// 任務在請求返回後繼續執行，因此不使用請求的上下文；ctx 只在刪除賬戶時被取消
+func (s *Service) run(ctx context.Context, job export.Job) {
+	defer func() {
+		s.mu.Lock()
+		active := s.active[job.ID]
+		delete(s.active, job.ID)
+		s.mu.Unlock()
+		active.cancel()
+		close(active.done)
+	}()
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return
+	}
+	defer func() { <-s.slots }()
+
+	job.Start()
+	if err := s.repo.Update(ctx, &job); err != nil {
+		log.Printf("Failed to start export %d: %v", job.ID, err)
+		return
+	}
+
+	path, size, err := s.build(ctx, job)
+	if ctx.Err() != nil {
+		if err == nil {
+			os.Remove(path)
+		}
+		return
+	}
+	if err != nil {
+		log.Printf("Export %d failed: %v", job.ID, err)
+		job.Fail(errors.New("failed to build export archive"))
+	} else {
+		job.Complete(path, size, time.Now().Add(s.config.Retention))
+	}
+	if err := s.repo.Update(ctx, &job); err != nil {
+		log.Printf("Failed to update export %d: %v", job.ID, err)
+		// 無法記錄的檔案不會被下載或過期清理
+		if job.Status == export.StatusCompleted {
+			os.Remove(path)
+		}
+	}
+}
+
+// build 將所有部分寫入 zip 檔案，返回檔案路徑和大小
+func (s *Service) build(ctx context.Context, job export.Job) (string, int64, error) {
+	if err := os.MkdirAll(s.config.Dir, 0o700); err != nil {
+		return "", 0, err
+	}
+	path := s.archivePath(job)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", 0, err
+	}
+
+	zw := zip.NewWriter(f)
+	archive := &Archive{zw: zw}
+	for _, section := range s.sections {
+		if err := section.Write(ctx, job.UserID, archive); err != nil {
+			zw.Close()
+			f.Close()
+			os.Remove(path)
+			return "", 0, fmt.Errorf("section %s: %w", section.Name(), err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		os.Remove(path)
+		return "", 0, err
+	}
+
+	info, err := f.Stat()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", 0, err
+	}
+	return path, info.Size(), nil
+}
+
+// archivePath 返回任務的 zip 檔案路徑
+func (s *Service) archivePath(job export.Job) string {
+	return filepath.Join(s.config.Dir, fmt.Sprintf("export-%d-%d.zip", job.UserID, job.ID))
+}
+
+// sign 計算下載鏈接的 HMAC 簽名
+func (s *Service) sign(id uint, expires int64) string {
+	mac := hmac.New(sha256.New, s.config.SigningKey)
+	mac.Write([]byte(strconv.FormatUint(uint64(id), 10) + ":" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
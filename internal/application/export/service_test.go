@@ -0,0 +1,243 @@
+package export
+
+import (
+	"blog-api/internal/domain/export"
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRepository 是內存中的導出任務存儲
+type fakeRepository struct {
+	mu     sync.Mutex
+	jobs   map[uint]export.Job
+	nextID uint
+	stale  []export.Job
+	failed []uint
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{jobs: make(map[uint]export.Job)}
+}
+
+func (r *fakeRepository) Create(_ context.Context, job *export.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	job.ID = r.nextID
+	job.CreatedAt = time.Now()
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *fakeRepository) FindByID(_ context.Context, id uint) (*export.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, export.ErrJobNotFound
+	}
+	return &job, nil
+}
+
+func (r *fakeRepository) FindActiveByUserID(context.Context, uint) (*export.Job, error) {
+	return nil, export.ErrJobNotFound
+}
+
+func (r *fakeRepository) FindExpired(context.Context, time.Time) ([]export.Job, error) {
+	return nil, nil
+}
+
+func (r *fakeRepository) FindStale(context.Context, time.Time) ([]export.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]export.Job(nil), r.stale...), nil
+}
+
+func (r *fakeRepository) FailUnfinished(_ context.Context, id uint, reason error) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok || (job.Status != export.StatusPending && job.Status != export.StatusRunning) {
+		return false, nil
+	}
+	job.Fail(reason)
+	r.jobs[id] = job
+	r.failed = append(r.failed, id)
+	return true, nil
+}
+
+func (r *fakeRepository) Update(_ context.Context, job *export.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *fakeRepository) ExpireByUserID(_ context.Context, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, job := range r.jobs {
+		if job.UserID == userID && job.Status == export.StatusCompleted {
+			job.Expire()
+			r.jobs[id] = job
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepository) status(id uint) export.Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.jobs[id].Status
+}
+
+// blockingSection 在 release 關閉之前不會完成，用於模擬耗時的導出
+type blockingSection struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingSection) Name() string { return "blocking" }
+
+func (s *blockingSection) Write(ctx context.Context, _ uint, archive *Archive) error {
+	s.started <- struct{}{}
+	select {
+	case <-s.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return archive.WriteJSON("blocking.json", true)
+}
+
+// waitForStatus 等待後台任務進入指定狀態
+func waitForStatus(t *testing.T, repo *fakeRepository, id uint, want export.Status) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for repo.status(id) != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("job %d status = %s, want %s", id, repo.status(id), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFailStaleSkipsJobsOwnedByThisProcess(t *testing.T) {
+	repo := newFakeRepository()
+	section := &blockingSection{started: make(chan struct{}, 2), release: make(chan struct{})}
+	s := NewService(repo, Config{Dir: t.TempDir(), Concurrency: 1, StaleAfter: time.Nanosecond}, section)
+
+	running, err := s.Request(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	<-section.started
+	queued, err := s.Request(context.Background(), 2) // 等待並發名額
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+
+	// 另一個進程遺留的任務及其不完整檔案
+	orphan := export.Job{UserID: 3, Status: export.StatusRunning}
+	if err := repo.Create(context.Background(), &orphan); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := os.WriteFile(s.archivePath(orphan), []byte("partial"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	repo.mu.Lock()
+	repo.stale = []export.Job{repo.jobs[running.ID], repo.jobs[queued.ID], orphan}
+	repo.mu.Unlock()
+
+	s.failStale(context.Background())
+
+	if len(repo.failed) != 1 || repo.failed[0] != orphan.ID {
+		t.Errorf("failed jobs = %v, want only the orphaned job %d", repo.failed, orphan.ID)
+	}
+	if _, err := os.Stat(s.archivePath(orphan)); !os.IsNotExist(err) {
+		t.Errorf("partial archive of the orphaned job still exists: %v", err)
+	}
+
+	close(section.release)
+	waitForStatus(t, repo, running.ID, export.StatusCompleted)
+	waitForStatus(t, repo, queued.ID, export.StatusCompleted)
+	if _, err := os.Stat(s.archivePath(*running)); err != nil {
+		t.Errorf("archive of the running job: %v", err)
+	}
+}
+
+func TestFailStaleKeepsJobsFinishedInTheMeantime(t *testing.T) {
+	repo := newFakeRepository()
+	s := NewService(repo, Config{Dir: t.TempDir(), StaleAfter: time.Nanosecond})
+
+	// 查詢時仍未完成，標記之前已由其他進程完成
+	job := export.Job{UserID: 1, Status: export.StatusRunning}
+	if err := repo.Create(context.Background(), &job); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	repo.stale = []export.Job{job}
+	job.Complete(s.archivePath(job), 7, time.Now().Add(time.Hour))
+	repo.jobs[job.ID] = job
+	if err := os.WriteFile(s.archivePath(job), []byte("archive"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	s.failStale(context.Background())
+
+	if repo.status(job.ID) != export.StatusCompleted || len(repo.failed) != 0 {
+		t.Errorf("status = %s, failed = %v; want the completed job untouched", repo.status(job.ID), repo.failed)
+	}
+	if _, err := os.Stat(s.archivePath(job)); err != nil {
+		t.Errorf("archive of the completed job: %v", err)
+	}
+}
+
+func TestRemoveArchivesCancelsJobsAndDeletesFiles(t *testing.T) {
+	repo := newFakeRepository()
+	section := &blockingSection{started: make(chan struct{}, 1), release: make(chan struct{})}
+	s := NewService(repo, Config{Dir: t.TempDir(), Concurrency: 1}, section)
+
+	completed := export.Job{UserID: 1, Status: export.StatusPending}
+	other := export.Job{UserID: 12, Status: export.StatusPending}
+	for _, job := range []*export.Job{&completed, &other} {
+		if err := repo.Create(context.Background(), job); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		job.Complete(s.archivePath(*job), 7, time.Now().Add(time.Hour))
+		repo.jobs[job.ID] = *job
+		if err := os.WriteFile(s.archivePath(*job), []byte("archive"), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	running, err := s.Request(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	<-section.started
+
+	s.RemoveArchives(context.Background(), 1)
+
+	if s.isActive(running.ID) {
+		t.Error("running job is still active after RemoveArchives returned")
+	}
+	if _, err := os.Stat(s.archivePath(*running)); !os.IsNotExist(err) {
+		t.Errorf("archive of the cancelled job exists: %v", err)
+	}
+	if _, err := os.Stat(s.archivePath(completed)); !os.IsNotExist(err) {
+		t.Errorf("archive of the completed job exists: %v", err)
+	}
+	if got := repo.status(completed.ID); got != export.StatusExpired {
+		t.Errorf("completed job status = %s, want expired", got)
+	}
+	if got := repo.status(running.ID); got == export.StatusCompleted {
+		t.Error("cancelled job was marked as completed")
+	}
+	if _, err := os.Stat(s.archivePath(other)); err != nil {
+		t.Errorf("archive of another user was removed: %v", err)
+	}
+	if got := repo.status(other.ID); got != export.StatusCompleted {
+		t.Errorf("other user's job status = %s, want completed", got)
+	}
+}
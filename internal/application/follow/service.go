@@ -0,0 +1,98 @@
+package follow
+
+import (
+	"blog-api/internal/domain/follow"
+	"blog-api/internal/domain/user"
+	"context"
+)
+
+// pageSize 關注列表每頁的數量
+const pageSize = 20
+
+// Service 封裝了關注相關的業務邏輯
+type Service struct {
+	repo  follow.Repository
+	users user.Repository
+}
+
+// NewService 創建一個新的關注服務實例
+func NewService(repo follow.Repository, users user.Repository) *Service {
+	return &Service{repo: repo, users: users}
+}
+
+// Follow 關注指定用戶名的作者
+func (s *Service) Follow(ctx context.Context, followerID uint, username string) error {
+	followee, err := s.findActive(ctx, username)
+	if err != nil {
+		return err
+	}
+	if followee.ID == followerID {
+		return follow.ErrSelfFollow
+	}
+	return s.repo.Create(ctx, &follow.Follow{FollowerID: followerID, FolloweeID: followee.ID})
+}
+
+// Unfollow 取消關注指定用戶名的作者
+func (s *Service) Unfollow(ctx context.Context, followerID uint, username string) error {
+	followee, err := s.users.FindByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, followerID, followee.ID)
+}
+
+// IsFollowing 檢查用戶是否關注了指定用戶名的作者
+func (s *Service) IsFollowing(ctx context.Context, followerID uint, username string) (bool, error) {
+	followee, err := s.users.FindByUsername(ctx, username)
+	if err != nil {
+		return false, err
+	}
+	return s.repo.Exists(ctx, followerID, followee.ID)
+}
+
+// GetFollowers 獲取關注指定用戶的用戶列表
+func (s *Service) GetFollowers(ctx context.Context, username string, page int) ([]user.PublicProfile, error) {
+	u, err := s.findActive(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.repo.FindFollowers(ctx, u.ID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return toProfiles(users), nil
+}
+
+// GetFollowing 獲取指定用戶關注的用戶列表
+func (s *Service) GetFollowing(ctx context.Context, username string, page int) ([]user.PublicProfile, error) {
+	u, err := s.findActive(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.repo.FindFollowing(ctx, u.ID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return toProfiles(users), nil
+}
+
+// findActive 根據用戶名查找啟用中的用戶
+func (s *Service) findActive(ctx context.Context, username string) (*user.User, error) {
+	u, err := s.users.FindByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsActive {
+		return nil, user.ErrUserNotFound
+	}
+	return u, nil
+}
+
+// toProfiles 將用戶轉換為公開資料
+func toProfiles(users []user.User) []user.PublicProfile {
+	profiles := make([]user.PublicProfile, len(users))
+	for i := range users {
+		profiles[i] = *users[i].PublicProfile()
+	}
+	return profiles
+}
@@ -0,0 +1,112 @@
+package follow
+
+import (
+	"blog-api/internal/domain/follow"
+	"blog-api/internal/domain/user"
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakeUsers 是按用戶名查找的內存用戶存儲
+type fakeUsers struct {
+	user.Repository
+	byName map[string]*user.User
+}
+
+func (r fakeUsers) FindByUsername(_ context.Context, username string) (*user.User, error) {
+	u, ok := r.byName[username]
+	if !ok {
+		return nil, user.ErrUserNotFound
+	}
+	return u, nil
+}
+
+// fakeFollows 記錄創建和刪除的關注關係
+type fakeFollows struct {
+	follow.Repository
+	created []follow.Follow
+	deleted [][2]uint
+	pages   []int
+}
+
+func (r *fakeFollows) Create(_ context.Context, f *follow.Follow) error {
+	r.created = append(r.created, *f)
+	return nil
+}
+
+func (r *fakeFollows) Delete(_ context.Context, followerID, followeeID uint) error {
+	r.deleted = append(r.deleted, [2]uint{followerID, followeeID})
+	return nil
+}
+
+func (r *fakeFollows) FindFollowers(_ context.Context, _ uint, page, _ int) ([]user.User, error) {
+	r.pages = append(r.pages, page)
+	return []user.User{{ID: 1, Username: "alice", Email: "alice@example.com"}}, nil
+}
+
+func newTestService() (*Service, *fakeFollows) {
+	follows := &fakeFollows{}
+	users := fakeUsers{byName: map[string]*user.User{
+		"alice": {ID: 1, Username: "alice", IsActive: true},
+		"bob":   {ID: 2, Username: "bob", IsActive: true},
+		"ghost": {ID: 3, Username: "ghost", IsActive: false},
+	}}
+	return NewService(follows, users), follows
+}
+
+func TestFollow(t *testing.T) {
+	s, follows := newTestService()
+	if err := s.Follow(context.Background(), 1, "bob"); err != nil {
+		t.Fatalf("Follow: %v", err)
+	}
+	if len(follows.created) != 1 || follows.created[0].FollowerID != 1 || follows.created[0].FolloweeID != 2 {
+		t.Errorf("created = %+v, want alice following bob", follows.created)
+	}
+}
+
+func TestFollowRejectsInvalidTargets(t *testing.T) {
+	tests := []struct {
+		username string
+		want     error
+	}{
+		{"alice", follow.ErrSelfFollow},
+		{"ghost", user.ErrUserNotFound},
+		{"nobody", user.ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		s, follows := newTestService()
+		if err := s.Follow(context.Background(), 1, tt.username); !errors.Is(err, tt.want) {
+			t.Errorf("Follow(%s) = %v, want %v", tt.username, err, tt.want)
+		}
+		if len(follows.created) != 0 {
+			t.Errorf("Follow(%s) created %+v", tt.username, follows.created)
+		}
+	}
+}
+
+func TestUnfollowAllowsInactiveAuthors(t *testing.T) {
+	s, follows := newTestService()
+	// 停用的作者不能被關注，但已有的關注仍然可以取消
+	if err := s.Unfollow(context.Background(), 1, "ghost"); err != nil {
+		t.Fatalf("Unfollow: %v", err)
+	}
+	if len(follows.deleted) != 1 || follows.deleted[0] != [2]uint{1, 3} {
+		t.Errorf("deleted = %v, want alice unfollowing ghost", follows.deleted)
+	}
+}
+
+func TestGetFollowers(t *testing.T) {
+	s, follows := newTestService()
+	profiles, err := s.GetFollowers(context.Background(), "bob", 2)
+	if err != nil {
+		t.Fatalf("GetFollowers: %v", err)
+	}
+	if len(profiles) != 1 || profiles[0].Username != "alice" || len(follows.pages) != 1 || follows.pages[0] != 2 {
+		t.Errorf("GetFollowers = %+v after pages %v", profiles, follows.pages)
+	}
+
+	if _, err := s.GetFollowers(context.Background(), "ghost", 1); !errors.Is(err, user.ErrUserNotFound) {
+		t.Errorf("GetFollowers(ghost) = %v, want %v", err, user.ErrUserNotFound)
+	}
+}
@@ -0,0 +1,92 @@
+package post
+
+import (
+	"blog-api/internal/domain/post"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// PatchDocument 是 PATCH 請求所修改的文章文檔
+// Version 是只讀的，可以在 JSON Patch 的 test 操作中使用，修改它會被拒絕
+type PatchDocument struct {
+	Title   string    `json:"title" example:"My Blog Post"`
+	Content string    `json:"content" example:"This is the content of my blog post."`
+	Tags    post.Tags `json:"tags" example:"go,databases"`
+	Version uint      `json:"version" example:"1"`
+}
+
+// Patch 將補丁應用到 PatchDocument 的 JSON 編碼上並返回結果
+// 補丁無法應用時應返回 post.ErrInvalidPatch，test 操作失敗時應返回 post.ErrPatchTestFailed
+type Patch func(doc []byte) ([]byte, error)
+
+// PatchPost 對文章應用部分更新，領域驗證作用於合併後的結果
+// 文章的當前版本必須與 version 相同，否則返回 *post.ConflictError
+func (s *Service) PatchPost(ctx context.Context, id, userID, version uint, patch Patch) (*post.Post, error) {
+	var existingPost *post.Post
+	err := s.tx.Do(ctx, func(ctx context.Context) error {
+		var err error
+		existingPost, err = s.repo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !existingPost.IsAuthor(userID) {
+			return post.ErrUnauthorized
+		}
+		if existingPost.Version != version {
+			return post.ErrVersionConflict
+		}
+
+		patched, err := applyPatch(existingPost, patch)
+		if err != nil {
+			return err
+		}
+		if err := existingPost.UpdateContent(patched.Title, patched.Content); err != nil {
+			return err
+		}
+		if err := existingPost.SetTags(patched.Tags); err != nil {
+			return err
+		}
+		return s.repo.Update(ctx, existingPost)
+	})
+	if errors.Is(err, post.ErrVersionConflict) || errors.Is(err, post.ErrPatchTestFailed) {
+		return nil, s.conflict(ctx, id, userID, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.notifyChange()
+	if err := s.attachAuthors(ctx, []*post.Post{existingPost}); err != nil {
+		return nil, err
+	}
+	return existingPost, nil
+}
+
+// applyPatch 將補丁應用到文章的可編輯字段上，拒絕未知字段和對版本的修改
+func applyPatch(p *post.Post, patch Patch) (*PatchDocument, error) {
+	tags := p.Tags
+	if tags == nil {
+		tags = post.Tags{}
+	}
+	doc, err := json.Marshal(PatchDocument{Title: p.Title, Content: p.Content, Tags: tags, Version: p.Version})
+	if err != nil {
+		return nil, err
+	}
+	out, err := patch(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var patched PatchDocument
+	dec := json.NewDecoder(bytes.NewReader(out))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&patched); err != nil {
+		return nil, fmt.Errorf("%w: %v", post.ErrInvalidPatch, err)
+	}
+	if patched.Version != p.Version {
+		return nil, fmt.Errorf("%w: version is read-only", post.ErrInvalidPatch)
+	}
+	return &patched, nil
+}
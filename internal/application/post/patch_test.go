@@ -0,0 +1,81 @@
+package post
+
+import (
+	"blog-api/internal/domain/post"
+	"errors"
+	"reflect"
+	"testing"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+// mergePatch 返回應用給定 JSON Merge Patch 的 Patch
+func mergePatch(patch string) Patch {
+	return func(doc []byte) ([]byte, error) {
+		return jsonpatch.MergePatch(doc, []byte(patch))
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	p := &post.Post{Title: "Hello", Content: "World", Version: 3}
+
+	got, err := applyPatch(p, mergePatch(`{"title":"Changed","version":3}`))
+	if err != nil {
+		t.Fatalf("applyPatch: %v", err)
+	}
+	if want := (PatchDocument{Title: "Changed", Content: "World", Tags: post.Tags{}, Version: 3}); !reflect.DeepEqual(*got, want) {
+		t.Errorf("applyPatch = %+v, want %+v", *got, want)
+	}
+	if p.Title != "Hello" {
+		t.Errorf("applyPatch modified the post: title = %q", p.Title)
+	}
+}
+
+func TestApplyPatchTags(t *testing.T) {
+	p := &post.Post{Title: "Hello", Content: "World", Tags: post.Tags{"go"}, Version: 3}
+	tests := []struct {
+		name  string
+		patch string
+		want  post.Tags
+	}{
+		{"unchanged", `{"title":"Changed"}`, post.Tags{"go"}},
+		{"replaced", `{"tags":["go","sql"]}`, post.Tags{"go", "sql"}},
+		{"cleared", `{"tags":[]}`, post.Tags{}},
+		{"removed", `{"tags":null}`, nil},
+	}
+	for _, tt := range tests {
+		got, err := applyPatch(p, mergePatch(tt.patch))
+		if err != nil {
+			t.Fatalf("%s: applyPatch: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got.Tags, tt.want) {
+			t.Errorf("%s: tags = %#v, want %#v", tt.name, got.Tags, tt.want)
+		}
+	}
+}
+
+func TestApplyPatchRejectsInvalidResults(t *testing.T) {
+	p := &post.Post{Title: "Hello", Content: "World", Version: 3}
+	tests := []struct {
+		name  string
+		patch string
+	}{
+		{"version changed", `{"version":4}`},
+		{"version removed", `{"version":null}`},
+		{"unknown field", `{"author_id":2}`},
+		{"wrong type", `{"title":1}`},
+	}
+	for _, tt := range tests {
+		if _, err := applyPatch(p, mergePatch(tt.patch)); !errors.Is(err, post.ErrInvalidPatch) {
+			t.Errorf("%s: applyPatch error = %v, want %v", tt.name, err, post.ErrInvalidPatch)
+		}
+	}
+}
+
+func TestApplyPatchReturnsPatchErrors(t *testing.T) {
+	p := &post.Post{Title: "Hello", Content: "World", Version: 3}
+	failing := func([]byte) ([]byte, error) { return nil, post.ErrPatchTestFailed }
+	if _, err := applyPatch(p, failing); !errors.Is(err, post.ErrPatchTestFailed) {
+		t.Errorf("applyPatch error = %v, want %v", err, post.ErrPatchTestFailed)
+	}
+}
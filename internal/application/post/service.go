@@ -1,63 +1,265 @@
 package post
 
 import (
+	"blog-api/internal/application/transaction"
+	"blog-api/internal/domain/bookmark"
 	"blog-api/internal/domain/post"
+	"context"
+	"errors"
 )
 
+// pageSize 每頁的文章數量
+const pageSize = 10
+
 // Service 封裝了文章相關的業務邏輯
 type Service struct {
-	repo post.Repository
+	repo      post.Repository
+	authors   post.AuthorRepository
+	bookmarks bookmark.Repository
+	tx        transaction.Manager
+	listeners []func()
 }
 
 // NewService 創建一個新的文章服務實例
-func NewService(repo post.Repository) *Service {
-	return &Service{repo: repo}
+func NewService(repo post.Repository, authors post.AuthorRepository, bookmarks bookmark.Repository, tx transaction.Manager) *Service {
+	return &Service{repo: repo, authors: authors, bookmarks: bookmarks, tx: tx}
+}
+
+// OnChange 註冊文章創建、更新或刪除後的回調，用於使依賴文章的緩存失效
+// 必須在開始處理請求之前註冊
+func (s *Service) OnChange(fn func()) {
+	s.listeners = append(s.listeners, fn)
+}
+
+// notifyChange 通知所有回調文章已變更
+func (s *Service) notifyChange() {
+	for _, fn := range s.listeners {
+		fn()
+	}
 }
 
+// 以下查詢方法的 viewerID 為當前請求的用戶，0 表示匿名訪問
+
 // GetPosts 獲取文章列表
-func (s *Service) GetPosts(page int) ([]post.Post, error) {
-	return s.repo.FindAll(page, 10) // 10 posts per page
+func (s *Service) GetPosts(ctx context.Context, page int, viewerID uint) ([]post.Post, error) {
+	return s.listPosts(ctx, viewerID, func() ([]post.Post, error) {
+		return s.repo.FindAll(ctx, page, pageSize)
+	})
+}
+
+// GetPostsByAuthor 根據作者用戶名獲取文章列表
+func (s *Service) GetPostsByAuthor(ctx context.Context, username string, page int, viewerID uint) ([]post.Post, error) {
+	author, err := s.authors.FindByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return s.listPosts(ctx, viewerID, func() ([]post.Post, error) {
+		return s.repo.FindByUserID(ctx, author.ID, page, pageSize)
+	})
+}
+
+// GetFeed 獲取用戶關注作者的文章，按時間倒序，beforeID 為上一頁最後一篇文章的ID
+func (s *Service) GetFeed(ctx context.Context, userID, beforeID uint, limit int) ([]post.Post, error) {
+	return s.listPosts(ctx, userID, func() ([]post.Post, error) {
+		return s.repo.FindFeed(ctx, userID, beforeID, limit)
+	})
+}
+
+// GetLatestPosts 獲取最新的若干篇文章，authorID 不為 0 時只返回該作者的文章
+func (s *Service) GetLatestPosts(ctx context.Context, limit int, authorID uint) ([]post.Post, error) {
+	return s.listPosts(ctx, 0, func() ([]post.Post, error) {
+		if authorID != 0 {
+			return s.repo.FindByUserID(ctx, authorID, 1, limit)
+		}
+		return s.repo.FindAll(ctx, 1, limit)
+	})
+}
+
+// GetLatestPostsByTag 獲取帶有指定標籤的最新文章，標籤應已規範化
+func (s *Service) GetLatestPostsByTag(ctx context.Context, tag string, limit int) ([]post.Post, error) {
+	return s.listPosts(ctx, 0, func() ([]post.Post, error) {
+		return s.repo.FindByTag(ctx, tag, 1, limit)
+	})
+}
+
+// GetPostsByIDs 批量獲取文章，返回以文章ID為鍵的映射，不存在的文章不會出現在結果中
+func (s *Service) GetPostsByIDs(ctx context.Context, ids []uint, viewerID uint) (map[uint]*post.Post, error) {
+	posts, err := s.listPosts(ctx, viewerID, func() ([]post.Post, error) {
+		return s.repo.FindByIDs(ctx, ids)
+	})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*post.Post, len(posts))
+	for i := range posts {
+		byID[posts[i].ID] = &posts[i]
+	}
+	return byID, nil
 }
 
 // GetPostByID 根據ID獲取單個文章
-func (s *Service) GetPostByID(id uint) (*post.Post, error) {
-	return s.repo.FindByID(id)
+func (s *Service) GetPostByID(ctx context.Context, id, viewerID uint) (*post.Post, error) {
+	p, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.decorate(ctx, viewerID, []*post.Post{p}); err != nil {
+		return nil, err
+	}
+	return p, nil
 }
 
 // CreatePost 創建新文章
-func (s *Service) CreatePost(p *post.Post) error {
+func (s *Service) CreatePost(ctx context.Context, p *post.Post) error {
 	if err := post.ValidateTitle(p.Title); err != nil {
 		return err
 	}
 	if err := post.ValidateContent(p.Content); err != nil {
 		return err
 	}
-	return s.repo.Create(p)
+	if err := p.SetTags(p.Tags); err != nil {
+		return err
+	}
+	if err := s.repo.Create(ctx, p); err != nil {
+		return err
+	}
+	s.notifyChange()
+	return s.attachAuthors(ctx, []*post.Post{p})
 }
 
-// UpdatePost 更新現有文章
-func (s *Service) UpdatePost(p *post.Post, userID uint) error {
-	existingPost, err := s.repo.FindByID(p.ID)
+// UpdatePost 更新現有文章，返回更新後的文章
+// p.Version 為客戶端讀取時的版本，文章在此之後被修改時返回 *post.ConflictError，其中包含服務器上的當前版本；
+// p.Tags 為 nil 時保留原有標籤
+func (s *Service) UpdatePost(ctx context.Context, p *post.Post, userID uint) (*post.Post, error) {
+	var existingPost *post.Post
+	err := s.tx.Do(ctx, func(ctx context.Context) error {
+		var err error
+		existingPost, err = s.repo.FindByID(ctx, p.ID)
+		if err != nil {
+			return err
+		}
+		if !existingPost.IsAuthor(userID) {
+			return post.ErrUnauthorized
+		}
+		if existingPost.Version != p.Version {
+			return post.ErrVersionConflict
+		}
+		if err := existingPost.UpdateContent(p.Title, p.Content); err != nil {
+			return err
+		}
+		if p.Tags != nil {
+			if err := existingPost.SetTags(p.Tags); err != nil {
+				return err
+			}
+		}
+		return s.repo.Update(ctx, existingPost)
+	})
+	if errors.Is(err, post.ErrVersionConflict) {
+		return nil, s.conflict(ctx, p.ID, userID, err)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if !existingPost.IsAuthor(userID) {
-		return post.ErrUnauthorized
+	s.notifyChange()
+	if err := s.attachAuthors(ctx, []*post.Post{existingPost}); err != nil {
+		return nil, err
 	}
-	if err := existingPost.UpdateContent(p.Title, p.Content); err != nil {
+	return existingPost, nil
+}
+
+// conflict 讀取文章的當前版本，構造返回給客戶端用於合併的衝突錯誤
+func (s *Service) conflict(ctx context.Context, id, viewerID uint, cause error) error {
+	current, err := s.GetPostByID(ctx, id, viewerID)
+	if err != nil {
 		return err
 	}
-	return s.repo.Update(existingPost)
+	return &post.ConflictError{Current: current, Err: cause}
 }
 
 // DeletePost 刪除文章
-func (s *Service) DeletePost(id, userID uint) error {
-	existingPost, err := s.repo.FindByID(id)
+func (s *Service) DeletePost(ctx context.Context, id, userID uint) error {
+	err := s.tx.Do(ctx, func(ctx context.Context) error {
+		existingPost, err := s.repo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !existingPost.IsAuthor(userID) {
+			return post.ErrUnauthorized
+		}
+		return s.repo.Delete(ctx, id)
+	})
+	if err != nil {
+		return err
+	}
+	s.notifyChange()
+	return nil
+}
+
+// listPosts 是文章列表的公共流程：查詢文章後批量附加作者摘要和當前用戶的狀態
+func (s *Service) listPosts(ctx context.Context, viewerID uint, fetch func() ([]post.Post, error)) ([]post.Post, error) {
+	posts, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	ptrs := make([]*post.Post, len(posts))
+	for i := range posts {
+		ptrs[i] = &posts[i]
+	}
+	if err := s.decorate(ctx, viewerID, ptrs); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// decorate 為文章附加作者摘要，已認證時再附加收藏狀態
+func (s *Service) decorate(ctx context.Context, viewerID uint, posts []*post.Post) error {
+	if err := s.attachAuthors(ctx, posts); err != nil {
+		return err
+	}
+	if viewerID == 0 {
+		return nil
+	}
+	return s.attachBookmarks(ctx, viewerID, posts)
+}
+
+// attachBookmarks 為文章批量填充當前用戶的收藏狀態
+func (s *Service) attachBookmarks(ctx context.Context, viewerID uint, posts []*post.Post) error {
+	ids := make([]uint, len(posts))
+	for i, p := range posts {
+		ids[i] = p.ID
+	}
+
+	bookmarked, err := s.bookmarks.FindBookmarkedPostIDs(ctx, viewerID, ids)
+	if err != nil {
+		return err
+	}
+	for _, p := range posts {
+		isBookmarked := bookmarked[p.ID]
+		p.IsBookmarked = &isBookmarked
+	}
+	return nil
+}
+
+// attachAuthors 為文章批量填充作者摘要
+func (s *Service) attachAuthors(ctx context.Context, posts []*post.Post) error {
+	ids := make([]uint, 0, len(posts))
+	seen := make(map[uint]bool, len(posts))
+	for _, p := range posts {
+		if !seen[p.UserID] {
+			seen[p.UserID] = true
+			ids = append(ids, p.UserID)
+		}
+	}
+
+	authors, err := s.authors.FindByIDs(ctx, ids)
 	if err != nil {
 		return err
 	}
-	if !existingPost.IsAuthor(userID) {
-		return post.ErrUnauthorized
+	for _, p := range posts {
+		if a, ok := authors[p.UserID]; ok {
+			p.Author = &a
+		}
 	}
-	return s.repo.Delete(id)
+	return nil
 }
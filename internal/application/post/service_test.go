@@ -0,0 +1,123 @@
+package post
+
+import (
+	"blog-api/internal/domain/bookmark"
+	"blog-api/internal/domain/post"
+	"context"
+	"errors"
+	"testing"
+)
+
+// directTx 直接執行事務函數
+type directTx struct{}
+
+func (directTx) Do(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) }
+
+// racingPosts 是內存中的單篇文章，concurrent 不為空時在第一次 Update 之前模擬另一個請求先保存了修改
+type racingPosts struct {
+	post.Repository
+	stored     post.Post
+	concurrent func(p *post.Post)
+	updates    int
+}
+
+func (r *racingPosts) FindByID(_ context.Context, id uint) (*post.Post, error) {
+	if id != r.stored.ID {
+		return nil, post.ErrPostNotFound
+	}
+	p := r.stored
+	return &p, nil
+}
+
+func (r *racingPosts) Update(_ context.Context, p *post.Post) error {
+	if r.concurrent != nil {
+		r.concurrent(&r.stored)
+		r.stored.Version++
+		r.concurrent = nil
+	}
+	if r.stored.Version != p.Version {
+		return post.ErrVersionConflict
+	}
+	r.updates++
+	p.Version++
+	r.stored = *p
+	return nil
+}
+
+type stubAuthors struct{ post.AuthorRepository }
+
+func (stubAuthors) FindByIDs(_ context.Context, ids []uint) (map[uint]post.Author, error) {
+	authors := make(map[uint]post.Author, len(ids))
+	for _, id := range ids {
+		authors[id] = post.Author{ID: id, Username: "john"}
+	}
+	return authors, nil
+}
+
+type stubBookmarks struct{ bookmark.Repository }
+
+func (stubBookmarks) FindBookmarkedPostIDs(context.Context, uint, []uint) (map[uint]bool, error) {
+	return map[uint]bool{}, nil
+}
+
+func TestUpdateVersionConflicts(t *testing.T) {
+	stored := post.Post{ID: 1, UserID: 1, Title: "Hello", Content: "Hello, world", Version: 3}
+	edit := func(version uint) *post.Post {
+		return &post.Post{ID: 1, UserID: 1, Title: "Edited", Content: "Edited content", Version: version}
+	}
+	concurrentEdit := func(p *post.Post) { p.Title = "Concurrent" }
+
+	tests := []struct {
+		name         string
+		version      uint
+		concurrent   func(p *post.Post)
+		wantErr      error
+		wantTitle    string // 成功時為更新後的標題，衝突時為 Current 的標題
+		wantVersion  uint
+		patchVersion bool
+	}{
+		{name: "current version", version: 3, wantTitle: "Edited", wantVersion: 4},
+		{name: "stale version", version: 2, wantErr: post.ErrVersionConflict, wantTitle: "Hello", wantVersion: 3},
+		{name: "concurrent update", version: 3, concurrent: concurrentEdit, wantErr: post.ErrVersionConflict, wantTitle: "Concurrent", wantVersion: 4},
+	}
+	for _, tt := range tests {
+		for _, method := range []string{"UpdatePost", "PatchPost"} {
+			t.Run(tt.name+"/"+method, func(t *testing.T) {
+				repo := &racingPosts{stored: stored, concurrent: tt.concurrent}
+				s := NewService(repo, stubAuthors{}, stubBookmarks{}, directTx{})
+
+				var got *post.Post
+				var err error
+				if method == "UpdatePost" {
+					got, err = s.UpdatePost(context.Background(), edit(tt.version), 1)
+				} else {
+					got, err = s.PatchPost(context.Background(), 1, 1, tt.version, mergePatch(`{"title":"Edited","content":"Edited content"}`))
+				}
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("%s error = %v, want %v", method, err, tt.wantErr)
+				}
+
+				if tt.wantErr == nil {
+					if got.Title != tt.wantTitle || got.Version != tt.wantVersion || got.Author == nil {
+						t.Errorf("updated post = %+v", got)
+					}
+					return
+				}
+				var conflict *post.ConflictError
+				if !errors.As(err, &conflict) {
+					t.Fatalf("error %v is not a ConflictError", err)
+				}
+				current := conflict.Current
+				if current.Title != tt.wantTitle || current.Version != tt.wantVersion {
+					t.Errorf("Current = %q version %d, want %q version %d", current.Title, current.Version, tt.wantTitle, tt.wantVersion)
+				}
+				if current.Author == nil || current.IsBookmarked == nil {
+					t.Error("Current is not decorated like a GetPost response")
+				}
+				if repo.updates != 0 {
+					t.Error("a conflicting update was saved")
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package reaction
+
+import (
+	"blog-api/internal/domain/post"
+	"blog-api/internal/domain/reaction"
+	"context"
+)
+
+// pageSize 回應列表每頁的數量
+const pageSize = 50
+
+// Service 封裝了文章回應相關的業務邏輯
+type Service struct {
+	repo  reaction.Repository
+	posts post.Repository
+}
+
+// NewService 創建一個新的回應服務實例
+func NewService(repo reaction.Repository, posts post.Repository) *Service {
+	return &Service{repo: repo, posts: posts}
+}
+
+// React 為文章添加回應，返回更新後的計數
+func (s *Service) React(ctx context.Context, postID, userID uint, reactionType reaction.Type) (post.ReactionCounts, error) {
+	if err := reaction.ValidateType(reactionType); err != nil {
+		return nil, err
+	}
+	if _, err := s.posts.FindByID(ctx, postID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Add(ctx, &reaction.Reaction{PostID: postID, UserID: userID, Type: reactionType}); err != nil {
+		return nil, err
+	}
+	return s.counts(ctx, postID)
+}
+
+// Unreact 取消文章的回應，返回更新後的計數
+func (s *Service) Unreact(ctx context.Context, postID, userID uint, reactionType reaction.Type) (post.ReactionCounts, error) {
+	if err := reaction.ValidateType(reactionType); err != nil {
+		return nil, err
+	}
+	if _, err := s.posts.FindByID(ctx, postID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Remove(ctx, postID, userID, reactionType); err != nil {
+		return nil, err
+	}
+	return s.counts(ctx, postID)
+}
+
+// GetReactors 獲取回應文章的用戶列表，reactionType 為空時返回所有類型
+func (s *Service) GetReactors(ctx context.Context, postID uint, reactionType reaction.Type, page int) ([]reaction.Reactor, error) {
+	if reactionType != "" {
+		if err := reaction.ValidateType(reactionType); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := s.posts.FindByID(ctx, postID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindReactors(ctx, postID, reactionType, page, pageSize)
+}
+
+// counts 讀取文章當前的回應計數
+func (s *Service) counts(ctx context.Context, postID uint) (post.ReactionCounts, error) {
+	p, err := s.posts.FindByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	return p.ReactionCounts, nil
+}
@@ -0,0 +1,51 @@
+package site
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Links 根據配置的公開地址生成對外的絕對鏈接
+type Links struct {
+	SiteURL string // 面向讀者的網站地址，用於文章和作者頁面，以及由網站代理的網站地圖
+	BaseURL string // 本 API 的公開地址，用於訂閱源和頭像等資源
+}
+
+// NewLinks 創建 Links，並去掉地址末尾的斜杠
+func NewLinks(siteURL, baseURL string) Links {
+	return Links{SiteURL: strings.TrimRight(siteURL, "/"), BaseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// Home 返回網站首頁地址
+func (l Links) Home() string {
+	return l.SiteURL + "/"
+}
+
+// Post 返回文章頁面地址
+func (l Links) Post(id uint) string {
+	return fmt.Sprintf("%s/posts/%d", l.SiteURL, id)
+}
+
+// Author 返回作者頁面地址
+func (l Links) Author(username string) string {
+	return l.SiteURL + "/users/" + url.PathEscape(username)
+}
+
+// Tag 返回標籤頁面地址
+func (l Links) Tag(tag string) string {
+	return l.SiteURL + "/tags/" + url.PathEscape(tag)
+}
+
+// Site 將網站上的路徑轉換為絕對地址
+func (l Links) Site(path string) string {
+	return l.SiteURL + path
+}
+
+// API 將本 API 的路徑轉換為絕對地址，已經是絕對地址時原樣返回
+func (l Links) API(path string) string {
+	if path == "" || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return l.BaseURL + path
+}
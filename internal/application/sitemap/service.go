@@ -0,0 +1,218 @@
+package sitemap
+
+import (
+	"blog-api/internal/application/site"
+	"blog-api/internal/domain/sitemap"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrSitemapNotFound 表示請求的分片不存在
+var ErrSitemapNotFound = errors.New("sitemap not found")
+
+// Config 定義網站地圖的配置
+type Config struct {
+	MaxURLs int           // 單個文件的 URL 上限，超出時拆分為網站地圖索引
+	TTL     time.Duration // 緩存的最長有效期，用於覆蓋文章以外的變更（如作者停用）
+}
+
+// Document 是渲染好的網站地圖文件
+type Document struct {
+	Body         []byte
+	ETag         string
+	LastModified time.Time
+}
+
+// snapshot 是某一時刻生成的全部網站地圖文件
+type snapshot struct {
+	index       *Document   // 只有一個文件時為 urlset，否則為 sitemapindex
+	parts       []*Document // 拆分後的各個 urlset，未拆分時為空
+	generatedAt time.Time
+}
+
+// Service 生成並緩存網站地圖，文章變更時通過 Invalidate 使緩存失效
+type Service struct {
+	repo   sitemap.Repository
+	links  site.Links
+	config Config
+
+	mu      sync.Mutex
+	current *snapshot
+}
+
+// NewService 創建一個新的網站地圖服務實例
+func NewService(repo sitemap.Repository, links site.Links, config Config) *Service {
+	if config.MaxURLs <= 0 || config.MaxURLs > sitemap.MaxURLsPerSitemap {
+		config.MaxURLs = sitemap.MaxURLsPerSitemap
+	}
+	if config.TTL <= 0 {
+		config.TTL = time.Hour
+	}
+	return &Service{repo: repo, links: links, config: config}
+}
+
+// Invalidate 使緩存失效，下次請求時重新生成
+func (s *Service) Invalidate() {
+	s.mu.Lock()
+	s.current = nil
+	s.mu.Unlock()
+}
+
+// Index 返回 /sitemap.xml 的內容
+func (s *Service) Index(ctx context.Context) (*Document, error) {
+	snap, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return snap.index, nil
+}
+
+// Part 返回拆分後的第 n 個網站地圖，從 1 開始
+func (s *Service) Part(ctx context.Context, n int) (*Document, error) {
+	snap, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if n < 1 || n > len(snap.parts) {
+		return nil, ErrSitemapNotFound
+	}
+	return snap.parts[n-1], nil
+}
+
+// PartPath 返回第 n 個分片的路徑
+func PartPath(n int) string {
+	return fmt.Sprintf("/sitemaps/sitemap-%d.xml", n)
+}
+
+// snapshot 返回緩存的網站地圖，過期或失效時重新生成
+// 生成期間持有鎖，避免緩存失效後的並發請求重複查詢數據庫
+func (s *Service) snapshot(ctx context.Context) (*snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current != nil && time.Since(s.current.generatedAt) < s.config.TTL {
+		return s.current, nil
+	}
+
+	snap, err := s.generate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.current = snap
+	return snap, nil
+}
+
+// generate 查詢文章和作者並渲染網站地圖
+func (s *Service) generate(ctx context.Context) (*snapshot, error) {
+	posts, err := s.repo.FindPostEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	authors, err := s.repo.FindAuthorEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]urlEntry, 0, len(posts)+len(authors)+1)
+	var latest time.Time
+	for _, p := range posts {
+		urls = append(urls, newURLEntry(s.links.Post(p.ID), p.UpdatedAt))
+		if p.UpdatedAt.After(latest) {
+			latest = p.UpdatedAt
+		}
+	}
+	for _, a := range authors {
+		urls = append(urls, newURLEntry(s.links.Author(a.Username), a.UpdatedAt))
+	}
+	urls = append([]urlEntry{newURLEntry(s.links.Home(), latest)}, urls...)
+
+	snap := &snapshot{generatedAt: time.Now()}
+	if len(urls) <= s.config.MaxURLs {
+		snap.index, err = render(urlSet{XMLNS: sitemapNS, URLs: urls}, latest)
+		return snap, err
+	}
+
+	index := sitemapIndex{XMLNS: sitemapNS}
+	for start, n := 0, 1; start < len(urls); start, n = start+s.config.MaxURLs, n+1 {
+		end := start + s.config.MaxURLs
+		if end > len(urls) {
+			end = len(urls)
+		}
+		chunk := urls[start:end]
+		lastMod := chunkLastModified(chunk)
+		part, err := render(urlSet{XMLNS: sitemapNS, URLs: chunk}, lastMod)
+		if err != nil {
+			return nil, err
+		}
+		snap.parts = append(snap.parts, part)
+		index.Sitemaps = append(index.Sitemaps, sitemapRef{Loc: s.links.Site(PartPath(n)), LastMod: formatLastMod(lastMod)})
+	}
+	snap.index, err = render(index, latest)
+	return snap, err
+}
+
+// sitemapNS 是網站地圖協議的命名空間
+const sitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+type urlSet struct {
+	XMLName xml.Name   `xml:"urlset"`
+	XMLNS   string     `xml:"xmlns,attr"`
+	URLs    []urlEntry `xml:"url"`
+}
+
+type urlEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+	lastMod time.Time
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	XMLNS    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapRef `xml:"sitemap"`
+}
+
+type sitemapRef struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// newURLEntry 創建一個 URL 條目
+func newURLEntry(loc string, lastMod time.Time) urlEntry {
+	return urlEntry{Loc: loc, LastMod: formatLastMod(lastMod), lastMod: lastMod}
+}
+
+// formatLastMod 以 W3C Datetime 格式輸出時間，零值時省略
+func formatLastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// chunkLastModified 返回一組 URL 中最近的修改時間
+func chunkLastModified(urls []urlEntry) time.Time {
+	var latest time.Time
+	for _, u := range urls {
+		if u.lastMod.After(latest) {
+			latest = u.lastMod
+		}
+	}
+	return latest
+}
+
+// render 將網站地圖編碼為 XML 文件
+func render(doc interface{}, lastModified time.Time) (*Document, error) {
+	body, err := xml.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	body = append([]byte(xml.Header), body...)
+	sum := sha256.Sum256(body)
+	return &Document{Body: body, ETag: `"` + hex.EncodeToString(sum[:16]) + `"`, LastModified: lastModified}, nil
+}
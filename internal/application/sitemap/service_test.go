@@ -0,0 +1,161 @@
+package sitemap
+
+import (
+	"blog-api/internal/application/site"
+	"blog-api/internal/domain/sitemap"
+	"context"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRepository 返回固定的網站地圖數據並記錄查詢次數
+type fakeRepository struct {
+	posts   []sitemap.PostEntry
+	authors []sitemap.AuthorEntry
+	queries int
+}
+
+func (r *fakeRepository) FindPostEntries(context.Context) ([]sitemap.PostEntry, error) {
+	r.queries++
+	return r.posts, nil
+}
+
+func (r *fakeRepository) FindAuthorEntries(context.Context) ([]sitemap.AuthorEntry, error) {
+	return r.authors, nil
+}
+
+var testLinks = site.NewLinks("https://blog.example.com/", "https://api.example.com")
+
+func day(d int) time.Time {
+	return time.Date(2024, 10, d, 12, 0, 0, 0, time.UTC)
+}
+
+func TestIndexSingleURLSet(t *testing.T) {
+	repo := &fakeRepository{
+		posts:   []sitemap.PostEntry{{ID: 2, UpdatedAt: day(3)}, {ID: 1, UpdatedAt: day(1)}},
+		authors: []sitemap.AuthorEntry{{Username: "john doe", UpdatedAt: day(3)}},
+	}
+	s := NewService(repo, testLinks, Config{})
+
+	doc, err := s.Index(context.Background())
+	if err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	var set urlSet
+	if err := xml.Unmarshal(doc.Body, &set); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	want := []urlEntry{
+		{Loc: "https://blog.example.com/", LastMod: "2024-10-03T12:00:00Z"},
+		{Loc: "https://blog.example.com/posts/2", LastMod: "2024-10-03T12:00:00Z"},
+		{Loc: "https://blog.example.com/posts/1", LastMod: "2024-10-01T12:00:00Z"},
+		{Loc: "https://blog.example.com/users/john%20doe", LastMod: "2024-10-03T12:00:00Z"},
+	}
+	if len(set.URLs) != len(want) {
+		t.Fatalf("urls = %+v, want %+v", set.URLs, want)
+	}
+	for i := range want {
+		if set.URLs[i].Loc != want[i].Loc || set.URLs[i].LastMod != want[i].LastMod {
+			t.Errorf("urls[%d] = %+v, want %+v", i, set.URLs[i], want[i])
+		}
+	}
+	if !doc.LastModified.Equal(day(3)) || !strings.HasPrefix(string(doc.Body), xml.Header) {
+		t.Errorf("document last modified %v, body %q", doc.LastModified, doc.Body[:40])
+	}
+	if _, err := s.Part(context.Background(), 1); !errors.Is(err, ErrSitemapNotFound) {
+		t.Errorf("Part(1) of an unsplit sitemap = %v, want %v", err, ErrSitemapNotFound)
+	}
+}
+
+func TestIndexSplitsIntoParts(t *testing.T) {
+	repo := &fakeRepository{}
+	for id := uint(1); id <= 4; id++ {
+		repo.posts = append(repo.posts, sitemap.PostEntry{ID: id, UpdatedAt: day(int(id))})
+	}
+	s := NewService(repo, testLinks, Config{MaxURLs: 2})
+
+	doc, err := s.Index(context.Background())
+	if err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	var index sitemapIndex
+	if err := xml.Unmarshal(doc.Body, &index); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	// 首頁加 4 篇文章共 5 個 URL，拆分為 3 個分片；分片地址必須與網站地圖位於同一主機
+	wantLocs := []string{
+		"https://blog.example.com/sitemaps/sitemap-1.xml",
+		"https://blog.example.com/sitemaps/sitemap-2.xml",
+		"https://blog.example.com/sitemaps/sitemap-3.xml",
+	}
+	if len(index.Sitemaps) != len(wantLocs) {
+		t.Fatalf("sitemaps = %+v", index.Sitemaps)
+	}
+	for i, loc := range wantLocs {
+		if index.Sitemaps[i].Loc != loc {
+			t.Errorf("sitemaps[%d].loc = %s, want %s", i, index.Sitemaps[i].Loc, loc)
+		}
+	}
+	if index.Sitemaps[1].LastMod != "2024-10-03T12:00:00Z" {
+		t.Errorf("sitemaps[1].lastmod = %s, want the latest change in the part", index.Sitemaps[1].LastMod)
+	}
+
+	var total int
+	for n := 1; n <= 3; n++ {
+		part, err := s.Part(context.Background(), n)
+		if err != nil {
+			t.Fatalf("Part(%d): %v", n, err)
+		}
+		var set urlSet
+		if err := xml.Unmarshal(part.Body, &set); err != nil {
+			t.Fatalf("unmarshal part %d: %v", n, err)
+		}
+		if len(set.URLs) > 2 {
+			t.Errorf("part %d has %d URLs, want at most 2", n, len(set.URLs))
+		}
+		total += len(set.URLs)
+	}
+	if total != 5 {
+		t.Errorf("parts contain %d URLs, want 5", total)
+	}
+	for _, n := range []int{0, 4} {
+		if _, err := s.Part(context.Background(), n); !errors.Is(err, ErrSitemapNotFound) {
+			t.Errorf("Part(%d) = %v, want %v", n, err, ErrSitemapNotFound)
+		}
+	}
+}
+
+func TestSnapshotCachingAndInvalidate(t *testing.T) {
+	repo := &fakeRepository{posts: []sitemap.PostEntry{{ID: 1, UpdatedAt: day(1)}}}
+	s := NewService(repo, testLinks, Config{})
+
+	first, err := s.Index(context.Background())
+	if err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	if _, err := s.Index(context.Background()); err != nil || repo.queries != 1 {
+		t.Errorf("second Index queried the repository again (%d queries, %v)", repo.queries, err)
+	}
+
+	repo.posts = append(repo.posts, sitemap.PostEntry{ID: 2, UpdatedAt: day(2)})
+	s.Invalidate()
+	second, err := s.Index(context.Background())
+	if err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	if repo.queries != 2 || second.ETag == first.ETag {
+		t.Errorf("after Invalidate: %d queries, ETag %s (was %s)", repo.queries, second.ETag, first.ETag)
+	}
+}
+
+func TestNewServiceLimitsMaxURLs(t *testing.T) {
+	for _, maxURLs := range []int{0, -1, sitemap.MaxURLsPerSitemap + 1} {
+		s := NewService(&fakeRepository{}, testLinks, Config{MaxURLs: maxURLs})
+		if s.config.MaxURLs != sitemap.MaxURLsPerSitemap || s.config.TTL != time.Hour {
+			t.Errorf("NewService(MaxURLs %d) config = %+v", maxURLs, s.config)
+		}
+	}
+}
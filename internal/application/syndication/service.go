@@ -0,0 +1,178 @@
+package syndication
+
+import (
+	appPost "blog-api/internal/application/post"
+	"blog-api/internal/application/site"
+	"blog-api/internal/domain/post"
+	"context"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Config 定義訂閱源的配置
+type Config struct {
+	Title         string
+	Description   string
+	Language      string
+	ItemCount     int  // 每個訂閱源包含的文章數量
+	Excerpt       bool // 為 true 時只輸出摘要，否則輸出全文
+	ExcerptLength int  // 摘要的最大字符數
+}
+
+// Person 是訂閱源中的作者信息
+type Person struct {
+	Name      string
+	URL       string
+	AvatarURL string
+}
+
+// Item 是訂閱源中的一篇文章
+type Item struct {
+	ID        string // 永久標識，使用文章的頁面地址
+	URL       string
+	Title     string
+	Content   string // 只輸出摘要時為空
+	Summary   string
+	Author    Person
+	Tags      []string
+	Published time.Time
+	Updated   time.Time
+}
+
+// Feed 是與輸出格式無關的訂閱源
+type Feed struct {
+	Title       string
+	Description string
+	Language    string
+	HomeURL     string
+	Author      *Person // 作者訂閱源的作者，全站和標籤訂閱源為空
+	Updated     time.Time
+	Items       []Item
+}
+
+// Service 根據文章生成訂閱源
+type Service struct {
+	posts   *appPost.Service
+	authors post.AuthorRepository
+	links   site.Links
+	config  Config
+}
+
+// NewService 創建一個新的訂閱源服務實例
+func NewService(posts *appPost.Service, authors post.AuthorRepository, links site.Links, config Config) *Service {
+	if config.ItemCount <= 0 {
+		config.ItemCount = 20
+	}
+	if config.ExcerptLength <= 0 {
+		config.ExcerptLength = 300
+	}
+	return &Service{posts: posts, authors: authors, links: links, config: config}
+}
+
+// SiteFeed 生成全站的訂閱源
+func (s *Service) SiteFeed(ctx context.Context) (*Feed, error) {
+	posts, err := s.posts.GetLatestPosts(ctx, s.config.ItemCount, 0)
+	if err != nil {
+		return nil, err
+	}
+	return s.build(s.config.Title, s.config.Description, s.links.Home(), nil, posts), nil
+}
+
+// AuthorFeed 生成指定作者的訂閱源
+func (s *Service) AuthorFeed(ctx context.Context, username string) (*Feed, error) {
+	author, err := s.authors.FindByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := s.posts.GetLatestPosts(ctx, s.config.ItemCount, author.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	person := s.person(author)
+	title := person.Name + " - " + s.config.Title
+	description := "Posts by " + person.Name
+	return s.build(title, description, person.URL, &person, posts), nil
+}
+
+// TagFeed 生成帶有指定標籤的文章的訂閱源，格式無效的標籤不可能存在，返回 ErrTagNotFound
+func (s *Service) TagFeed(ctx context.Context, tag string) (*Feed, error) {
+	tag, err := post.NormalizeTag(tag)
+	if err != nil {
+		return nil, post.ErrTagNotFound
+	}
+	posts, err := s.posts.GetLatestPostsByTag(ctx, tag, s.config.ItemCount)
+	if err != nil {
+		return nil, err
+	}
+
+	title := "#" + tag + " - " + s.config.Title
+	description := "Posts tagged " + tag
+	return s.build(title, description, s.links.Tag(tag), nil, posts), nil
+}
+
+// build 將文章轉換為訂閱源，更新時間取最近修改的文章
+func (s *Service) build(title, description, homeURL string, author *Person, posts []post.Post) *Feed {
+	feed := &Feed{
+		Title:       title,
+		Description: description,
+		Language:    s.config.Language,
+		HomeURL:     homeURL,
+		Author:      author,
+		Items:       make([]Item, 0, len(posts)),
+	}
+	for i := range posts {
+		p := &posts[i]
+		item := Item{
+			ID:        s.links.Post(p.ID),
+			URL:       s.links.Post(p.ID),
+			Title:     p.Title,
+			Summary:   excerpt(p.Content, s.config.ExcerptLength),
+			Author:    s.person(p.Author),
+			Tags:      p.Tags,
+			Published: p.CreatedAt.UTC(),
+			Updated:   p.UpdatedAt.UTC(),
+		}
+		if !s.config.Excerpt {
+			item.Content = p.Content
+		}
+		if item.Updated.Before(item.Published) {
+			item.Updated = item.Published
+		}
+		if item.Updated.After(feed.Updated) {
+			feed.Updated = item.Updated
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed
+}
+
+// person 將作者摘要轉換為訂閱源中的作者信息
+func (s *Service) person(a *post.Author) Person {
+	if a == nil {
+		return Person{Name: "Unknown"}
+	}
+	name := strings.TrimSpace(a.FirstName + " " + a.LastName)
+	if name == "" {
+		name = a.Username
+	}
+	return Person{Name: name, URL: s.links.Author(a.Username), AvatarURL: s.links.API(a.AvatarURL)}
+}
+
+// excerpt 截取內容的前 maxLen 個字符作為摘要，盡量在單詞邊界截斷
+func excerpt(content string, maxLen int) string {
+	content = strings.Join(strings.Fields(content), " ")
+	runes := []rune(content)
+	if len(runes) <= maxLen {
+		return content
+	}
+	cut := maxLen
+	for i := maxLen; i > maxLen*3/4; i-- {
+		if unicode.IsSpace(runes[i]) {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimSpace(string(runes[:cut])) + "…"
+}
@@ -0,0 +1,125 @@
+package token
+
+import (
+	"blog-api/internal/domain/token"
+	"blog-api/internal/infrastructure/auth"
+	"context"
+	"time"
+)
+
+// Service 封裝了個人訪問令牌相關的業務邏輯
+type Service struct {
+	repo token.Repository
+}
+
+// NewService 創建一個新的令牌服務實例
+func NewService(repo token.Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// CreateInput 定義創建令牌所需的輸入數據
+type CreateInput struct {
+	Name      string     `json:"name" binding:"required" example:"ci-publisher"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1" example:"posts:write"`
+	ExpiresAt *time.Time `json:"expiresAt" example:"2025-10-20T15:00:00Z"`
+}
+
+// CreateResult 包含新建的令牌記錄以及僅返回一次的明文令牌
+type CreateResult struct {
+	Token       string             `json:"token" example:"bpat_3f9a1c2e5b7d9f01_q8Zt..."`
+	AccessToken *token.AccessToken `json:"accessToken"`
+}
+
+// Create 為用戶創建新的個人訪問令牌
+// granted 為調用者當前令牌擁有的權限範圍，新令牌的權限不能超出此範圍
+// grantedUntil 為調用者的個人訪問令牌的過期時間，新令牌最晚在此時過期，避免令牌通過創建新令牌延長自身的有效期；為 nil 時不限制
+func (s *Service) Create(ctx context.Context, userID uint, granted token.Scopes, grantedUntil *time.Time, input CreateInput) (*CreateResult, error) {
+	if err := token.ValidateName(input.Name); err != nil {
+		return nil, err
+	}
+	scopes := token.Scopes(input.Scopes)
+	if err := scopes.Validate(); err != nil {
+		return nil, err
+	}
+	for _, scope := range scopes {
+		if !granted.Has(scope) {
+			return nil, token.ErrInsufficientScope
+		}
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, token.ErrInvalidExpiry
+	}
+	if grantedUntil != nil && (input.ExpiresAt == nil || input.ExpiresAt.After(*grantedUntil)) {
+		input.ExpiresAt = grantedUntil
+	}
+
+	raw, prefix, tokenHash, err := auth.GeneratePAT()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &token.AccessToken{
+		UserID:    userID,
+		Name:      input.Name,
+		Prefix:    prefix,
+		TokenHash: tokenHash,
+		Scopes:    scopes,
+		ExpiresAt: input.ExpiresAt,
+	}
+	if err := s.repo.Create(ctx, t); err != nil {
+		return nil, err
+	}
+
+	return &CreateResult{Token: raw, AccessToken: t}, nil
+}
+
+// List 獲取用戶的所有令牌
+func (s *Service) List(ctx context.Context, userID uint) ([]token.AccessToken, error) {
+	return s.repo.FindByUserID(ctx, userID)
+}
+
+// Revoke 撤銷用戶的指定令牌
+func (s *Service) Revoke(ctx context.Context, userID, id uint) error {
+	t, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	// 不透露其他用戶令牌的存在
+	if t.UserID != userID {
+		return token.ErrTokenNotFound
+	}
+	if t.RevokedAt != nil {
+		return nil
+	}
+	return s.repo.Revoke(ctx, t.ID, time.Now())
+}
+
+// Authenticate 驗證明文令牌並返回對應的令牌記錄
+func (s *Service) Authenticate(ctx context.Context, raw string) (*token.AccessToken, error) {
+	prefix, ok := auth.ParsePATPrefix(raw)
+	if !ok {
+		return nil, token.ErrTokenNotFound
+	}
+
+	t, err := s.repo.FindByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if !auth.ComparePAT(raw, t.TokenHash) {
+		return nil, token.ErrTokenNotFound
+	}
+	now := time.Now()
+	if err := t.IsActive(now); err != nil {
+		return nil, err
+	}
+
+	// 只寫入最後使用時間，不會覆蓋並發的撤銷
+	if t.NeedsTouch(now) {
+		if err := s.repo.Touch(ctx, t.ID, now); err != nil {
+			return nil, err
+		}
+		t.LastUsedAt = &now
+	}
+
+	return t, nil
+}
@@ -0,0 +1,223 @@
+package token
+
+import (
+	"blog-api/internal/domain/token"
+	"blog-api/internal/infrastructure/auth"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryRepository 是保存在內存中的令牌倉庫
+type memoryRepository struct {
+	tokens  map[uint]*token.AccessToken
+	touches int
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{tokens: make(map[uint]*token.AccessToken)}
+}
+
+func (r *memoryRepository) Create(ctx context.Context, t *token.AccessToken) error {
+	t.ID = uint(len(r.tokens) + 1)
+	stored := *t
+	r.tokens[t.ID] = &stored
+	return nil
+}
+
+func (r *memoryRepository) FindByID(ctx context.Context, id uint) (*token.AccessToken, error) {
+	t, ok := r.tokens[id]
+	if !ok {
+		return nil, token.ErrTokenNotFound
+	}
+	found := *t
+	return &found, nil
+}
+
+func (r *memoryRepository) FindByPrefix(ctx context.Context, prefix string) (*token.AccessToken, error) {
+	for _, t := range r.tokens {
+		if t.Prefix == prefix {
+			found := *t
+			return &found, nil
+		}
+	}
+	return nil, token.ErrTokenNotFound
+}
+
+func (r *memoryRepository) FindByUserID(ctx context.Context, userID uint) ([]token.AccessToken, error) {
+	var tokens []token.AccessToken
+	for _, t := range r.tokens {
+		if t.UserID == userID {
+			tokens = append(tokens, *t)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *memoryRepository) Revoke(ctx context.Context, id uint, at time.Time) error {
+	if t, ok := r.tokens[id]; ok && t.RevokedAt == nil {
+		t.RevokedAt = &at
+	}
+	return nil
+}
+
+func (r *memoryRepository) Touch(ctx context.Context, id uint, at time.Time) error {
+	r.touches++
+	if t, ok := r.tokens[id]; ok && t.RevokedAt == nil {
+		t.LastUsedAt = &at
+	}
+	return nil
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+	tomorrow := time.Now().Add(24 * time.Hour)
+	nextWeek := time.Now().Add(7 * 24 * time.Hour)
+	readOnly := token.Scopes{token.ScopePostsRead}
+
+	tests := []struct {
+		name          string
+		granted       token.Scopes
+		grantedUntil  *time.Time
+		input         CreateInput
+		wantErr       error
+		wantExpiresAt *time.Time
+	}{
+		{"full access", token.AllScopes, nil, CreateInput{Name: "ci", Scopes: []string{"posts:write"}}, nil, nil},
+		{"with expiry", token.AllScopes, nil, CreateInput{Name: "ci", Scopes: []string{"posts:read"}, ExpiresAt: &nextWeek}, nil, &nextWeek},
+		{"blank name", token.AllScopes, nil, CreateInput{Name: "  ", Scopes: []string{"posts:read"}}, token.ErrInvalidName, nil},
+		{"unknown scope", token.AllScopes, nil, CreateInput{Name: "ci", Scopes: []string{"posts:admin"}}, token.ErrInvalidScope, nil},
+		{"scope not granted", readOnly, nil, CreateInput{Name: "ci", Scopes: []string{"posts:write"}}, token.ErrInsufficientScope, nil},
+		{"expiry in the past", token.AllScopes, nil, CreateInput{Name: "ci", Scopes: []string{"posts:read"}, ExpiresAt: timePtr(time.Now().Add(-time.Minute))}, token.ErrInvalidExpiry, nil},
+		{"capped by caller expiry", token.AllScopes, &tomorrow, CreateInput{Name: "ci", Scopes: []string{"posts:read"}, ExpiresAt: &nextWeek}, nil, &tomorrow},
+		{"never expiring capped by caller expiry", token.AllScopes, &tomorrow, CreateInput{Name: "ci", Scopes: []string{"posts:read"}}, nil, &tomorrow},
+		{"earlier than caller expiry", token.AllScopes, &nextWeek, CreateInput{Name: "ci", Scopes: []string{"posts:read"}, ExpiresAt: &tomorrow}, nil, &tomorrow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryRepository()
+			result, err := NewService(repo).Create(ctx, 1, tt.granted, tt.grantedUntil, tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(repo.tokens) != 0 {
+					t.Error("rejected token was stored")
+				}
+				return
+			}
+
+			if !strings.HasPrefix(result.Token, auth.PATPrefix) {
+				t.Errorf("token %q does not start with %q", result.Token, auth.PATPrefix)
+			}
+			stored := repo.tokens[result.AccessToken.ID]
+			if stored.TokenHash == "" || strings.Contains(stored.TokenHash, result.Token) {
+				t.Errorf("stored hash = %q", stored.TokenHash)
+			}
+			if prefix, _ := auth.ParsePATPrefix(result.Token); prefix != stored.Prefix {
+				t.Errorf("prefix = %q, want %q", stored.Prefix, prefix)
+			}
+			switch {
+			case tt.wantExpiresAt == nil && stored.ExpiresAt != nil:
+				t.Errorf("ExpiresAt = %v, want nil", stored.ExpiresAt)
+			case tt.wantExpiresAt != nil && (stored.ExpiresAt == nil || !stored.ExpiresAt.Equal(*tt.wantExpiresAt)):
+				t.Errorf("ExpiresAt = %v, want %v", stored.ExpiresAt, tt.wantExpiresAt)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	create := func(t *testing.T, repo *memoryRepository, modify func(*token.AccessToken)) string {
+		t.Helper()
+		result, err := NewService(repo).Create(ctx, 1, token.AllScopes, nil, CreateInput{Name: "ci", Scopes: []string{"posts:read"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		modify(repo.tokens[result.AccessToken.ID])
+		return result.Token
+	}
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		modify      func(*token.AccessToken)
+		raw         func(raw string) string
+		wantErr     error
+		wantTouches int
+	}{
+		{"valid", func(*token.AccessToken) {}, nil, nil, 1},
+		{"recently used", func(t *token.AccessToken) { t.LastUsedAt = timePtr(time.Now().Add(-time.Second)) }, nil, nil, 0},
+		{"used before the touch interval", func(t *token.AccessToken) { t.LastUsedAt = timePtr(time.Now().Add(-token.TouchInterval)) }, nil, nil, 1},
+		{"revoked", func(t *token.AccessToken) { t.RevokedAt = &past }, nil, token.ErrTokenRevoked, 0},
+		{"expired", func(t *token.AccessToken) { t.ExpiresAt = &past }, nil, token.ErrTokenExpired, 0},
+		{"wrong secret", func(*token.AccessToken) {}, func(raw string) string { return raw + "x" }, token.ErrTokenNotFound, 0},
+		{"unknown identifier", func(*token.AccessToken) {}, func(string) string { return auth.PATPrefix + "0000000000000000_secret" }, token.ErrTokenNotFound, 0},
+		{"missing prefix", func(*token.AccessToken) {}, func(raw string) string { return strings.TrimPrefix(raw, auth.PATPrefix) }, token.ErrTokenNotFound, 0},
+		{"malformed identifier", func(*token.AccessToken) {}, func(string) string { return auth.PATPrefix + "xyz_secret" }, token.ErrTokenNotFound, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryRepository()
+			raw := create(t, repo, tt.modify)
+			if tt.raw != nil {
+				raw = tt.raw(raw)
+			}
+
+			got, err := NewService(repo).Authenticate(ctx, raw)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.UserID != 1 {
+				t.Errorf("UserID = %d, want 1", got.UserID)
+			}
+			if repo.touches != tt.wantTouches {
+				t.Errorf("touches = %d, want %d", repo.touches, tt.wantTouches)
+			}
+		})
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepository()
+	s := NewService(repo)
+	result, err := s.Create(ctx, 1, token.AllScopes, nil, CreateInput{Name: "ci", Scopes: []string{"posts:read"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := result.AccessToken.ID
+
+	if err := s.Revoke(ctx, 2, id); !errors.Is(err, token.ErrTokenNotFound) {
+		t.Errorf("revoking another user's token: error = %v, want %v", err, token.ErrTokenNotFound)
+	}
+	if repo.tokens[id].RevokedAt != nil {
+		t.Fatal("another user revoked the token")
+	}
+	if err := s.Revoke(ctx, 1, 99); !errors.Is(err, token.ErrTokenNotFound) {
+		t.Errorf("revoking a missing token: error = %v, want %v", err, token.ErrTokenNotFound)
+	}
+
+	if err := s.Revoke(ctx, 1, id); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	revokedAt := *repo.tokens[id].RevokedAt
+	if _, err := s.Authenticate(ctx, result.Token); !errors.Is(err, token.ErrTokenRevoked) {
+		t.Errorf("Authenticate after revoke: error = %v, want %v", err, token.ErrTokenRevoked)
+	}
+
+	// 重複撤銷不會改變撤銷時間
+	if err := s.Revoke(ctx, 1, id); err != nil {
+		t.Fatalf("second Revoke: %v", err)
+	}
+	if !repo.tokens[id].RevokedAt.Equal(revokedAt) {
+		t.Errorf("RevokedAt changed from %v to %v", revokedAt, repo.tokens[id].RevokedAt)
+	}
+}
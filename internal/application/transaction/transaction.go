@@ -0,0 +1,12 @@
+package transaction
+
+import "context"
+
+// Manager 定義跨存儲庫的事務邊界
+// 應用服務用它把多個存儲庫操作組合為一個原子操作，存儲庫從上下文中取得事務句柄
+type Manager interface {
+	// Do 在事務中執行 fn，fn 收到的上下文攜帶事務，使用它的存儲庫操作都屬於同一事務
+	// ctx 已攜帶事務時以保存點嵌套執行，fn 返回錯誤時只回滾到保存點；
+	// 遇到序列化衝突或死鎖時會重新執行整個最外層事務，因此 fn 不應產生數據庫之外的副作用
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,236 @@
+package user
+
+import (
+	"blog-api/internal/domain/user"
+	"blog-api/internal/infrastructure/auth"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// emailTokenTTL 郵箱驗證令牌的有效期
+const emailTokenTTL = 24 * time.Hour
+
+// Mailer 定義發送郵件的接口
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// AvatarRemover 定義刪除已上傳頭像文件的接口
+type AvatarRemover interface {
+	RemoveFiles(ctx context.Context, contentHash string)
+}
+
+// ArchiveRemover 定義刪除用戶數據導出檔案的接口
+type ArchiveRemover interface {
+	RemoveArchives(ctx context.Context, userID uint)
+}
+
+// UpdateProfileInput 定義更新資料所需的輸入數據，未提供的字段保持不變
+type UpdateProfileInput struct {
+	FirstName *string `json:"firstName" example:"John"`
+	LastName  *string `json:"lastName" example:"Doe"`
+	Email     *string `json:"email" binding:"omitempty,email" example:"john.doe@example.com"`
+	Bio       *string `json:"bio" example:"Writing about Go and distributed systems."`
+	Website   *string `json:"website" example:"https://johndoe.dev"`
+}
+
+// UpdateProfile 更新用戶資料，更改郵箱時需要驗證新郵箱後才會生效
+func (s *Service) UpdateProfile(ctx context.Context, userID uint, input UpdateProfileInput) (*user.User, error) {
+	var u *user.User
+	var verificationToken string
+	err := s.tx.Do(ctx, func(ctx context.Context) error {
+		var err error
+		u, err = s.repo.FindByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if input.FirstName != nil {
+			u.FirstName = *input.FirstName
+		}
+		if input.LastName != nil {
+			u.LastName = *input.LastName
+		}
+		if input.Bio != nil {
+			u.Bio = *input.Bio
+		}
+		if input.Website != nil {
+			u.Website = *input.Website
+		}
+		if err := user.ValidateProfile(u.FirstName, u.LastName, u.Bio, u.Website); err != nil {
+			return err
+		}
+
+		verificationToken = ""
+		if input.Email != nil && *input.Email != u.Email {
+			if _, err := s.repo.FindByEmail(ctx, *input.Email); err == nil {
+				return user.ErrDuplicateEmail
+			}
+			raw, tokenHash, err := auth.GenerateOpaqueToken()
+			if err != nil {
+				return err
+			}
+			u.RequestEmailChange(*input.Email, tokenHash, time.Now().Add(emailTokenTTL))
+			verificationToken = raw
+		}
+
+		return s.repo.Update(ctx, u)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 郵件在事務提交之後發送，避免事務重試時重複發送
+	if verificationToken != "" {
+		if err := s.sendEmailVerification(u.PendingEmail, verificationToken); err != nil {
+			return nil, err
+		}
+	}
+	return u, nil
+}
+
+// VerifyEmailInput 定義驗證郵箱所需的輸入數據
+type VerifyEmailInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// VerifyEmail 使用郵件中的令牌確認新郵箱
+func (s *Service) VerifyEmail(ctx context.Context, input VerifyEmailInput) error {
+	return s.tx.Do(ctx, func(ctx context.Context) error {
+		u, err := s.repo.FindByEmailTokenHash(ctx, auth.HashOpaqueToken(input.Token))
+		if err != nil {
+			return err
+		}
+
+		// 驗證期間新郵箱可能已被其他用戶使用
+		if existing, err := s.repo.FindByEmail(ctx, u.PendingEmail); err == nil && existing.ID != u.ID {
+			return user.ErrDuplicateEmail
+		}
+
+		if err := u.ConfirmEmailChange(time.Now()); err != nil {
+			return err
+		}
+		return s.repo.Update(ctx, u)
+	})
+}
+
+// DeleteAccountInput 定義刪除賬戶所需的輸入數據
+type DeleteAccountInput struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// DeleteAccount 在確認密碼後刪除賬戶，並根據配置的策略處理用戶的文章
+// 密碼在事務之外驗證；文章的處理和賬戶的刪除在同一事務中進行，任何一步失敗都不會留下部分刪除的數據
+func (s *Service) DeleteAccount(ctx context.Context, userID uint, input DeleteAccountInput) error {
+	verified, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if ok, err := s.hasher.Verify(verified.PasswordHash, input.Password); err != nil || !ok {
+		return user.ErrInvalidPassword
+	}
+
+	var avatarHash string
+	err = s.tx.Do(ctx, func(ctx context.Context) error {
+		u, err := s.reloadVerified(ctx, verified)
+		if err != nil {
+			return err
+		}
+		avatarHash = u.AvatarHash
+		return s.deleteAccount(ctx, u)
+	})
+	if err != nil {
+		return err
+	}
+
+	// 文件無法隨事務回滾，在提交之後刪除
+	s.avatars.RemoveFiles(ctx, avatarHash)
+	s.archives.RemoveArchives(ctx, userID)
+	return nil
+}
+
+// deleteAccount 執行 DeleteAccount 的各個步驟
+func (s *Service) deleteAccount(ctx context.Context, u *user.User) error {
+	switch s.config.DeletionPolicy {
+	case user.DeletionAnonymize:
+		u.Anonymize()
+		return s.repo.Update(ctx, u)
+	case user.DeletionTransfer:
+		if s.config.TransferUserID == 0 || s.config.TransferUserID == u.ID {
+			return user.ErrDeletionTarget
+		}
+		return s.transferAndDelete(ctx, u.ID, s.config.TransferUserID)
+	case user.DeletionGhost:
+		ghost, err := s.ghostUser(ctx)
+		if err != nil {
+			return err
+		}
+		if ghost.ID == u.ID {
+			return user.ErrDeletionTarget
+		}
+		return s.transferAndDelete(ctx, u.ID, ghost.ID)
+	case user.DeletionCascade:
+		return s.repo.DeleteWithPosts(ctx, u.ID)
+	case user.DeletionBlock:
+		count, err := s.postRepo.CountByUserID(ctx, u.ID)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return user.ErrUserHasPosts
+		}
+		// 數據庫外鍵同樣會阻止刪除在檢查之後新發表了文章的用戶
+		return s.repo.Delete(ctx, u.ID)
+	}
+	return fmt.Errorf("unsupported account deletion policy: %q", s.config.DeletionPolicy)
+}
+
+// transferAndDelete 將用戶的文章轉移給另一個用戶後刪除賬戶
+func (s *Service) transferAndDelete(ctx context.Context, fromUserID, toUserID uint) error {
+	if err := s.postRepo.ReassignAuthor(ctx, fromUserID, toUserID); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, fromUserID)
+}
+
+// ghostUser 查找接收已刪除賬戶文章的 ghost 用戶，不存在時創建
+// 同名的用戶如果處於啟用狀態，說明用戶名已被真實用戶佔用，此時拒絕轉移
+func (s *Service) ghostUser(ctx context.Context) (*user.User, error) {
+	username := s.config.GhostUsername
+	if username == "" {
+		username = user.DefaultGhostUsername
+	}
+
+	ghost, err := s.repo.FindByUsername(ctx, username)
+	if err == nil {
+		if ghost.IsActive {
+			return nil, user.ErrDeletionTarget
+		}
+		return ghost, nil
+	}
+	if !errors.Is(err, user.ErrUserNotFound) {
+		return nil, err
+	}
+
+	ghost = user.NewGhost(username)
+	if err := s.repo.Create(ctx, ghost); err != nil {
+		return nil, err
+	}
+	// is_active 列的默認值為 true，Create 會跳過零值 false，需要再顯式寫入一次
+	if err := s.repo.Update(ctx, ghost); err != nil {
+		return nil, err
+	}
+	return ghost, nil
+}
+
+// sendEmailVerification 發送包含驗證鏈接的郵件
+func (s *Service) sendEmailVerification(to, rawToken string) error {
+	if s.mailer == nil {
+		return errors.New("no mailer configured")
+	}
+	link := fmt.Sprintf(s.config.EmailVerificationURL, rawToken)
+	body := fmt.Sprintf("Please confirm your new email address by visiting:\n\n%s\n\nThe link expires in %s.", link, emailTokenTTL)
+	return s.mailer.Send(to, "Confirm your new email address", body)
+}
@@ -1,22 +1,43 @@
 package user
 
 import (
+	"blog-api/internal/application/transaction"
+	"blog-api/internal/domain/post"
+	"blog-api/internal/domain/token"
 	"blog-api/internal/domain/user"
 	"blog-api/internal/infrastructure/auth"
 	"blog-api/internal/infrastructure/hash"
+	"context"
 	"errors"
+	"log"
 	"time"
 )
 
 // Service 封裝了用戶相關的業務邏輯
 type Service struct {
 	repo       user.Repository
+	postRepo   post.Repository
+	tx         transaction.Manager
 	jwtService *auth.JWTService
+	hasher     hash.Hasher
+	mailer     Mailer
+	avatars    AvatarRemover
+	archives   ArchiveRemover
+	config     Config
+}
+
+// Config 定義用戶服務的可配置行為
+type Config struct {
+	PasswordPolicy       user.PasswordPolicy
+	EmailVerificationURL string // 郵箱驗證鏈接模板，%s 會被替換為令牌
+	DeletionPolicy       user.AccountDeletionPolicy
+	TransferUserID       uint   // DeletionTransfer 策略下接收文章的用戶
+	GhostUsername        string // DeletionGhost 策略下接收文章的停用用戶，不存在時自動創建
 }
 
 // NewService 創建一個新的用戶服務實例
-func NewService(repo user.Repository, jwtService *auth.JWTService) *Service {
-	return &Service{repo: repo, jwtService: jwtService}
+func NewService(repo user.Repository, postRepo post.Repository, tx transaction.Manager, jwtService *auth.JWTService, hasher hash.Hasher, mailer Mailer, avatars AvatarRemover, archives ArchiveRemover, config Config) *Service {
+	return &Service{repo: repo, postRepo: postRepo, tx: tx, jwtService: jwtService, hasher: hasher, mailer: mailer, avatars: avatars, archives: archives, config: config}
 }
 
 // RegisterInput 定義註冊所需的輸入數據
@@ -29,24 +50,14 @@ type RegisterInput struct {
 }
 
 // Register 處理用戶註冊邏輯
-func (s *Service) Register(input RegisterInput) error {
-	// 檢查用戶名是否已存在
-	if _, err := s.repo.FindByUsername(input.Username); err == nil {
-		return user.ErrDuplicateUsername
-	}
-
-	// 檢查郵箱是否已存在
-	if _, err := s.repo.FindByEmail(input.Email); err == nil {
-		return user.ErrDuplicateEmail
-	}
-
-	// 驗證密碼
-	if err := user.ValidatePassword(input.Password); err != nil {
+func (s *Service) Register(ctx context.Context, input RegisterInput) error {
+	// 根據密碼策略驗證密碼
+	if err := s.config.PasswordPolicy.Validate(input.Password, input.Username, input.Email); err != nil {
 		return err
 	}
 
 	// 對密碼進行哈希處理
-	hashedPassword, err := hash.GenerateFromPassword(input.Password, hash.DefaultCost)
+	hashedPassword, err := s.hasher.Hash(input.Password)
 	if err != nil {
 		return err
 	}
@@ -54,58 +65,124 @@ func (s *Service) Register(input RegisterInput) error {
 	newUser := &user.User{
 		Username:          input.Username,
 		Email:             input.Email,
-		PasswordHash:      string(hashedPassword),
+		PasswordHash:      hashedPassword,
 		FirstName:         input.FirstName,
 		LastName:          input.LastName,
 		IsActive:          true,
 		PasswordChangedAt: time.Now(), // 設置初始密碼修改時間
 	}
 
-	return s.repo.Create(newUser)
+	// 唯一性檢查與創建在同一事務中進行，數據庫的唯一索引仍是最終保證
+	return s.tx.Do(ctx, func(ctx context.Context) error {
+		// 檢查用戶名是否已存在
+		if _, err := s.repo.FindByUsername(ctx, input.Username); err == nil {
+			return user.ErrDuplicateUsername
+		}
+
+		// 檢查郵箱是否已存在
+		if _, err := s.repo.FindByEmail(ctx, input.Email); err == nil {
+			return user.ErrDuplicateEmail
+		}
+
+		return s.repo.Create(ctx, newUser)
+	})
 }
 
 // LoginInput 定義登錄所需的輸入數據
+// Scopes 和 Audience 可選，用於申請權限更窄的令牌，例如只讀的分析面板令牌
 type LoginInput struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Username string   `json:"username" binding:"required"`
+	Password string   `json:"password" binding:"required"`
+	Scopes   []string `json:"scopes" example:"posts:read"`
+	Audience string   `json:"audience" example:"blog-api"`
 }
 
 // Login 處理用戶登錄邏輯
-func (s *Service) Login(input LoginInput) (string, error) {
-	u, err := s.repo.FindByUsername(input.Username)
+func (s *Service) Login(ctx context.Context, input LoginInput) (string, error) {
+	// 未指定權限範圍時授予全部權限
+	scopes := token.AllScopes
+	if len(input.Scopes) > 0 {
+		scopes = token.Scopes(input.Scopes)
+		if err := scopes.Validate(); err != nil {
+			return "", err
+		}
+	}
+	if _, err := s.jwtService.ResolveAudience(input.Audience); err != nil {
+		return "", err
+	}
+
+	u, err := s.repo.FindByUsername(ctx, input.Username)
 	if err != nil {
 		if errors.Is(err, user.ErrUserNotFound) {
 			return "", user.ErrInvalidPassword // 為了安全，不透露用戶不存在的信息
 		}
 		return "", err
 	}
-
 	if !u.IsActive {
-		return "", errors.New("account is not active")
+		return "", user.ErrAccountInactive
 	}
 
-	if err := hash.CompareHashAndPassword([]byte(u.PasswordHash), []byte(input.Password)); err != nil {
+	// 密碼哈希的計算開銷很大，在事務之外進行，避免長時間佔用連接和鎖
+	if ok, err := s.hasher.Verify(u.PasswordHash, input.Password); err != nil || !ok {
 		return "", user.ErrInvalidPassword
 	}
 
-	// 更新最後登錄時間
-	u.UpdateLastLogin()
-	if err := s.repo.Update(u); err != nil {
+	// 哈希算法或參數已過時，使用當前配置透明地重新哈希
+	// 不更新 PasswordChangedAt，避免已簽發的令牌失效
+	var rehashed string
+	if s.hasher.NeedsRehash(u.PasswordHash) {
+		if rehashed, err = s.hasher.Hash(input.Password); err != nil {
+			log.Printf("Failed to rehash password for user %d: %v", u.ID, err)
+			rehashed = ""
+		}
+	}
+
+	// 重新讀取並更新登錄時間在同一事務中進行，避免覆蓋並發的修改
+	err = s.tx.Do(ctx, func(ctx context.Context) error {
+		current, err := s.reloadVerified(ctx, u)
+		if err != nil {
+			return err
+		}
+		if !current.IsActive {
+			return user.ErrAccountInactive
+		}
+		if rehashed != "" {
+			current.PasswordHash = rehashed
+		}
+
+		// 更新最後登錄時間
+		current.UpdateLastLogin()
+		u = current
+		return s.repo.Update(ctx, current)
+	})
+	if err != nil {
 		return "", err
 	}
 
-	// 生成 JWT 令牌，包含密碼修改時間
-	token, err := s.jwtService.GenerateToken(u.ID, u.PasswordChangedAt)
+	// 生成 JWT 令牌，包含密碼修改時間、權限範圍和受眾
+	jwtToken, err := s.jwtService.GenerateToken(u.ID, u.PasswordChangedAt, scopes, input.Audience)
 	if err != nil {
 		return "", err
 	}
 
-	return token, nil
+	return jwtToken, nil
 }
 
 // GetUserProfile 根據用戶ID獲取用戶信息
-func (s *Service) GetUserProfile(id uint) (*user.User, error) {
-	return s.repo.FindByID(id)
+func (s *Service) GetUserProfile(ctx context.Context, id uint) (*user.User, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+// GetPublicProfile 根據用戶名獲取用戶的公開資料，已停用的賬戶視為不存在
+func (s *Service) GetPublicProfile(ctx context.Context, username string) (*user.PublicProfile, error) {
+	u, err := s.repo.FindByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsActive {
+		return nil, user.ErrUserNotFound
+	}
+	return u.PublicProfile(), nil
 }
 
 // ChangePasswordInput 定義更改密碼所需的輸入數據
@@ -115,30 +192,52 @@ type ChangePasswordInput struct {
 }
 
 // ChangePassword 處理更改密碼的邏輯
-func (s *Service) ChangePassword(userID uint, input ChangePasswordInput) error {
-	u, err := s.repo.FindByID(userID)
+// 密碼的驗證和哈希在事務之外進行，事務中只重新讀取並更新用戶
+func (s *Service) ChangePassword(ctx context.Context, userID uint, input ChangePasswordInput) error {
+	u, err := s.repo.FindByID(ctx, userID)
 	if err != nil {
 		return err
 	}
 
 	// 驗證當前密碼
-	if err := hash.CompareHashAndPassword([]byte(u.PasswordHash), []byte(input.CurrentPassword)); err != nil {
-		return errors.New("current password is incorrect")
+	if ok, err := s.hasher.Verify(u.PasswordHash, input.CurrentPassword); err != nil || !ok {
+		return user.ErrInvalidPassword
 	}
 
-	// 驗證新密碼
-	if err := user.ValidatePassword(input.NewPassword); err != nil {
+	// 根據密碼策略驗證新密碼
+	if err := s.config.PasswordPolicy.Validate(input.NewPassword, u.Username, u.Email); err != nil {
 		return err
 	}
 
 	// 對新密碼進行哈希處理
-	hashedPassword, err := hash.GenerateFromPassword(input.NewPassword, hash.DefaultCost)
+	hashedPassword, err := s.hasher.Hash(input.NewPassword)
 	if err != nil {
 		return err
 	}
 
-	// 更新密碼和密碼修改時間
-	u.PasswordHash = string(hashedPassword)
-	u.PasswordChangedAt = time.Now()
-	return s.repo.Update(u)
+	return s.tx.Do(ctx, func(ctx context.Context) error {
+		current, err := s.reloadVerified(ctx, u)
+		if err != nil {
+			return err
+		}
+
+		// 更新密碼和密碼修改時間
+		current.PasswordHash = hashedPassword
+		current.PasswordChangedAt = time.Now()
+		return s.repo.Update(ctx, current)
+	})
+}
+
+// reloadVerified 在事務中重新讀取已在事務之外驗證過密碼的用戶
+// 密碼在驗證之後被並發修改時，驗證結果已不再有效，返回 ErrInvalidPassword；
+// 登錄時的重新哈希不改變密碼也不更新 PasswordChangedAt，因此只比較修改時間而不比較哈希
+func (s *Service) reloadVerified(ctx context.Context, verified *user.User) (*user.User, error) {
+	current, err := s.repo.FindByID(ctx, verified.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !current.PasswordChangedAt.Equal(verified.PasswordChangedAt) {
+		return nil, user.ErrInvalidPassword
+	}
+	return current, nil
 }
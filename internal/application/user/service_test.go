@@ -0,0 +1,172 @@
+package user
+
+import (
+	"blog-api/internal/domain/token"
+	"blog-api/internal/domain/user"
+	"blog-api/internal/infrastructure/auth"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// directTx 直接執行事務函數
+type directTx struct{}
+
+func (directTx) Do(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) }
+
+// prefixHasher 以 "<版本>:<密碼>" 作為哈希，"old:" 開頭的哈希需要重新哈希
+type prefixHasher struct{}
+
+func (prefixHasher) Hash(password string) (string, error) { return "new:" + password, nil }
+
+func (prefixHasher) Verify(encoded, password string) (bool, error) {
+	return encoded == "old:"+password || encoded == "new:"+password, nil
+}
+
+func (prefixHasher) NeedsRehash(encoded string) bool { return strings.HasPrefix(encoded, "old:") }
+
+func (prefixHasher) Supports(string) bool { return true }
+
+// staleUsers 在事務之外返回登錄開始時讀到的用戶，在事務中返回最新的用戶
+type staleUsers struct {
+	user.Repository
+	stale   user.User
+	current user.User
+	updated *user.User
+}
+
+func (r *staleUsers) FindByUsername(context.Context, string) (*user.User, error) {
+	u := r.stale
+	return &u, nil
+}
+
+func (r *staleUsers) FindByID(context.Context, uint) (*user.User, error) {
+	u := r.current
+	return &u, nil
+}
+
+func (r *staleUsers) Update(_ context.Context, u *user.User) error {
+	r.updated = u
+	return nil
+}
+
+func TestLoginAfterConcurrentChanges(t *testing.T) {
+	keys, err := auth.NewKeyManager(auth.KeySource{SecretKey: "secret"})
+	if err != nil {
+		t.Fatalf("NewKeyManager: %v", err)
+	}
+	changedAt := time.Date(2024, 10, 20, 14, 0, 0, 0, time.UTC)
+	stale := user.User{ID: 1, Username: "john", PasswordHash: "old:password", PasswordChangedAt: changedAt, IsActive: true}
+
+	tests := []struct {
+		name     string
+		current  func(u *user.User)
+		wantErr  error
+		wantHash string
+	}{
+		{
+			name:     "no concurrent change",
+			current:  func(*user.User) {},
+			wantHash: "new:password",
+		},
+		{
+			// 並發的登錄已經重新哈希了同一個密碼
+			name:     "concurrent rehash",
+			current:  func(u *user.User) { u.PasswordHash = "new:password" },
+			wantHash: "new:password",
+		},
+		{
+			name: "concurrent password change",
+			current: func(u *user.User) {
+				u.PasswordHash = "new:other"
+				u.PasswordChangedAt = changedAt.Add(time.Second)
+			},
+			wantErr: user.ErrInvalidPassword,
+		},
+		{
+			name:    "account deactivated",
+			current: func(u *user.User) { u.IsActive = false },
+			wantErr: user.ErrAccountInactive,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &staleUsers{stale: stale, current: stale}
+			tt.current(&repo.current)
+			s := NewService(repo, nil, directTx{}, auth.NewJWTService(keys, auth.JWTConfig{}), prefixHasher{}, nil, nil, nil, Config{})
+
+			jwt, err := s.Login(context.Background(), LoginInput{Username: "john", Password: "password"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Login error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if repo.updated != nil {
+					t.Error("user was updated after a failed login")
+				}
+				return
+			}
+			if jwt == "" {
+				t.Error("Login returned an empty token")
+			}
+			if repo.updated == nil || repo.updated.PasswordHash != tt.wantHash || repo.updated.LastLogin == nil {
+				t.Errorf("updated user = %+v, want hash %q and a login time", repo.updated, tt.wantHash)
+			}
+			if !repo.updated.PasswordChangedAt.Equal(changedAt) {
+				t.Errorf("PasswordChangedAt = %v, want it unchanged", repo.updated.PasswordChangedAt)
+			}
+		})
+	}
+}
+
+func TestLoginScopesAndAudience(t *testing.T) {
+	keys, err := auth.NewKeyManager(auth.KeySource{SecretKey: "secret"})
+	if err != nil {
+		t.Fatalf("NewKeyManager: %v", err)
+	}
+	jwtService := auth.NewJWTService(keys, auth.JWTConfig{Audiences: []string{"blog-api", "admin"}})
+	u := user.User{ID: 1, Username: "john", PasswordHash: "new:password", IsActive: true}
+
+	tests := []struct {
+		name         string
+		input        LoginInput
+		wantErr      error
+		wantScopes   token.Scopes
+		wantAudience string
+	}{
+		{"default scopes", LoginInput{}, nil, token.AllScopes, "blog-api"},
+		{"requested scopes", LoginInput{Scopes: []string{"posts:read"}, Audience: "admin"}, nil, token.Scopes{token.ScopePostsRead}, "admin"},
+		{"unknown scope", LoginInput{Scopes: []string{"posts:read", "posts:admin"}}, token.ErrInvalidScope, nil, ""},
+		{"unknown audience", LoginInput{Audience: "mobile"}, auth.ErrInvalidAudience, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &staleUsers{stale: u, current: u}
+			s := NewService(repo, nil, directTx{}, jwtService, prefixHasher{}, nil, nil, nil, Config{})
+
+			tt.input.Username, tt.input.Password = "john", "password"
+			raw, err := s.Login(context.Background(), tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Login error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if repo.updated != nil {
+					t.Error("login time was recorded for a rejected login")
+				}
+				return
+			}
+
+			claims, err := jwtService.ParseToken(raw)
+			if err != nil {
+				t.Fatalf("ParseToken: %v", err)
+			}
+			if strings.Join(claims.Scopes, " ") != strings.Join(tt.wantScopes, " ") {
+				t.Errorf("scopes = %v, want %v", claims.Scopes, tt.wantScopes)
+			}
+			if len(claims.Audience) != 1 || claims.Audience[0] != tt.wantAudience {
+				t.Errorf("audience = %v, want %s", claims.Audience, tt.wantAudience)
+			}
+		})
+	}
+}
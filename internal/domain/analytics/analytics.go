@@ -0,0 +1,91 @@
+package analytics
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// DirectReferrer 表示沒有來源頁面的訪問
+const DirectReferrer = "direct"
+
+// DailyCount 記錄文章每天的瀏覽次數
+type DailyCount struct {
+	PostID uint      `gorm:"primaryKey"`
+	Day    time.Time `gorm:"primaryKey;type:date"`
+	Views  int64     `gorm:"not null;default:0"`
+}
+
+// TableName 指定每日瀏覽次數的表名
+func (DailyCount) TableName() string {
+	return "post_view_daily"
+}
+
+// ReferrerCount 記錄文章每天來自各來源網站的瀏覽次數
+type ReferrerCount struct {
+	PostID   uint      `gorm:"primaryKey"`
+	Day      time.Time `gorm:"primaryKey;type:date"`
+	Referrer string    `gorm:"primaryKey;type:varchar(255)"`
+	Views    int64     `gorm:"not null;default:0"`
+}
+
+// TableName 指定來源統計的表名
+func (ReferrerCount) TableName() string {
+	return "post_view_referrers"
+}
+
+// DailyViews 是時間序列中的一個數據點
+type DailyViews struct {
+	Date  string `json:"date" example:"2024-10-20"`
+	Views int64  `json:"views" example:"42"`
+}
+
+// ReferrerViews 是來源統計中的一項
+type ReferrerViews struct {
+	Referrer string `json:"referrer" example:"news.ycombinator.com"`
+	Views    int64  `json:"views" example:"17"`
+}
+
+// PostStats 是文章在一段時間內的瀏覽統計
+type PostStats struct {
+	PostID     uint            `json:"postId" example:"1"`
+	From       string          `json:"from" example:"2024-09-21"`
+	To         string          `json:"to" example:"2024-10-20"`
+	TotalViews int64           `json:"totalViews" example:"420"`
+	Daily      []DailyViews    `json:"daily"`
+	Referrers  []ReferrerViews `json:"referrers"`
+}
+
+// Repository 定義瀏覽統計存儲的接口
+type Repository interface {
+	Increment(ctx context.Context, daily []DailyCount, referrers []ReferrerCount) error // 在現有計數上累加
+	FindDaily(ctx context.Context, postID uint, from, to time.Time) ([]DailyCount, error)
+	FindReferrers(ctx context.Context, postID uint, from, to time.Time, limit int) ([]ReferrerViews, error) // limit 不大於 0 時返回所有來源
+}
+
+// botMarkers 是常見爬蟲和自動化工具的 User-Agent 特徵
+var botMarkers = []string{
+	"bot", "crawler", "spider", "slurp", "crawl", "fetch", "preview",
+	"headless", "lighthouse", "facebookexternalhit", "embedly", "quora link",
+	"curl", "wget", "python-requests", "python-urllib", "go-http-client", "httpclient", "okhttp", "java/", "libwww",
+}
+
+// IsBot 根據 User-Agent 判斷訪問是否來自爬蟲或自動化工具，缺少 User-Agent 時也視為爬蟲
+func IsBot(userAgent string) bool {
+	ua := strings.ToLower(strings.TrimSpace(userAgent))
+	if ua == "" {
+		return true
+	}
+	for _, marker := range botMarkers {
+		if strings.Contains(ua, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// Day 返回時間所在的 UTC 日期
+func Day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,60 @@
+package bookmark
+
+import (
+	"blog-api/internal/domain/post"
+	"context"
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+// Bookmark 代表用戶收藏的文章，只對收藏者本人可見
+type Bookmark struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint       `json:"-" gorm:"not null;uniqueIndex:idx_bookmarks_user_post;index:idx_bookmarks_user_folder"`
+	PostID    uint       `json:"postId" gorm:"not null;uniqueIndex:idx_bookmarks_user_post"`
+	Folder    string     `json:"folder" gorm:"type:varchar(100);not null;default:'';index:idx_bookmarks_user_folder"`
+	Note      string     `json:"note" gorm:"type:text;not null;default:''"`
+	CreatedAt time.Time  `json:"createdAt" gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time  `json:"updatedAt" gorm:"default:CURRENT_TIMESTAMP"`
+	Post      *post.Post `json:"post,omitempty" gorm:"-"`
+}
+
+// Folder 是收藏夾的摘要
+type Folder struct {
+	Name  string `json:"name" example:"to-read"`
+	Count int64  `json:"count" example:"3"`
+}
+
+// 收藏字段的長度限制
+const (
+	MaxFolderLength = 100
+	MaxNoteLength   = 2000
+)
+
+// 定義一些常見的錯誤
+var (
+	ErrBookmarkNotFound = errors.New("bookmark not found")
+	ErrInvalidFolder    = errors.New("folder name must be at most 100 characters")
+	ErrInvalidNote      = errors.New("note must be at most 2000 characters")
+)
+
+// Repository 定義收藏存儲的接口
+type Repository interface {
+	Save(ctx context.Context, b *Bookmark) error // 同一文章已收藏時更新收藏夾和備註
+	Delete(ctx context.Context, userID, postID uint) error
+	FindByUserID(ctx context.Context, userID uint, folder *string, beforeID uint, limit int) ([]Bookmark, error) // folder 為 nil 時返回所有收藏夾
+	FindFolders(ctx context.Context, userID uint) ([]Folder, error)
+	FindBookmarkedPostIDs(ctx context.Context, userID uint, postIDs []uint) (map[uint]bool, error)
+}
+
+// Validate 驗證收藏夾名稱和備註
+func (b *Bookmark) Validate() error {
+	if utf8.RuneCountInString(b.Folder) > MaxFolderLength {
+		return ErrInvalidFolder
+	}
+	if utf8.RuneCountInString(b.Note) > MaxNoteLength {
+		return ErrInvalidNote
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Status 表示導出任務的狀態
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusExpired   Status = "expired"
+)
+
+// Job 代表一個個人數據導出任務
+type Job struct {
+	ID          uint       `json:"id" gorm:"primaryKey" example:"1"`
+	UserID      uint       `json:"-" gorm:"not null;index"`
+	Status      Status     `json:"status" gorm:"type:varchar(20);not null" example:"completed"`
+	FilePath    string     `json:"-" gorm:"type:text"`
+	Size        int64      `json:"size,omitempty" example:"20480"`
+	Error       string     `json:"error,omitempty" gorm:"type:text"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty" example:"2024-10-27T14:00:00Z"` // 檔案保留到此時間
+	CreatedAt   time.Time  `json:"createdAt" gorm:"default:CURRENT_TIMESTAMP" example:"2024-10-20T14:00:00Z"`
+	CompletedAt *time.Time `json:"completedAt,omitempty" example:"2024-10-20T14:00:05Z"`
+}
+
+// 定義一些常見的錯誤
+var (
+	ErrJobNotFound       = errors.New("export job not found")
+	ErrJobNotReady       = errors.New("export is not ready for download")
+	ErrExportExpired     = errors.New("export has expired")
+	ErrInvalidSignature  = errors.New("invalid download link")
+	ErrJobAlreadyRunning = errors.New("an export is already in progress")
+	ErrJobInterrupted    = errors.New("export was interrupted")
+)
+
+// Repository 定義導出任務存儲的接口
+type Repository interface {
+	Create(ctx context.Context, job *Job) error
+	FindByID(ctx context.Context, id uint) (*Job, error)
+	FindActiveByUserID(ctx context.Context, userID uint) (*Job, error)
+	FindExpired(ctx context.Context, before time.Time) ([]Job, error)
+	FindStale(ctx context.Context, before time.Time) ([]Job, error)
+	FailUnfinished(ctx context.Context, id uint, reason error) (bool, error) // 任務已結束時不修改並返回 false
+	Update(ctx context.Context, job *Job) error
+	ExpireByUserID(ctx context.Context, userID uint) error
+}
+
+// Start 將任務標記為執行中
+func (j *Job) Start() {
+	j.Status = StatusRunning
+}
+
+// Complete 將任務標記為完成，檔案保留到 expiresAt
+func (j *Job) Complete(filePath string, size int64, expiresAt time.Time) {
+	now := time.Now()
+	j.Status = StatusCompleted
+	j.FilePath = filePath
+	j.Size = size
+	j.ExpiresAt = &expiresAt
+	j.CompletedAt = &now
+}
+
+// Fail 將任務標記為失敗
+func (j *Job) Fail(err error) {
+	now := time.Now()
+	j.Status = StatusFailed
+	j.Error = err.Error()
+	j.CompletedAt = &now
+}
+
+// Expire 將任務標記為已過期，檔案應已被刪除
+func (j *Job) Expire() {
+	j.Status = StatusExpired
+	j.FilePath = ""
+}
+
+// Downloadable 檢查任務的檔案是否可以下載
+func (j *Job) Downloadable(now time.Time) error {
+	if j.Status == StatusExpired || (j.ExpiresAt != nil && now.After(*j.ExpiresAt)) {
+		return ErrExportExpired
+	}
+	if j.Status != StatusCompleted {
+		return ErrJobNotReady
+	}
+	return nil
+}
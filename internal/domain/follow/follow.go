@@ -0,0 +1,29 @@
+package follow
+
+import (
+	"blog-api/internal/domain/user"
+	"context"
+	"errors"
+	"time"
+)
+
+// Follow 代表一個用戶關注另一個用戶（作者）的關係
+type Follow struct {
+	FollowerID uint      `json:"followerId" gorm:"primaryKey"`
+	FolloweeID uint      `json:"followeeId" gorm:"primaryKey;index"`
+	CreatedAt  time.Time `json:"createdAt" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// 定義一些常見的錯誤
+var (
+	ErrSelfFollow = errors.New("users cannot follow themselves")
+)
+
+// Repository 定義關注關係存儲的接口
+type Repository interface {
+	Create(ctx context.Context, f *Follow) error // 已存在的關注關係不會報錯
+	Delete(ctx context.Context, followerID, followeeID uint) error
+	Exists(ctx context.Context, followerID, followeeID uint) (bool, error)
+	FindFollowers(ctx context.Context, userID uint, page, pageSize int) ([]user.User, error)
+	FindFollowing(ctx context.Context, userID uint, page, pageSize int) ([]user.User, error)
+}
@@ -1,35 +1,171 @@
 package post
 
 import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
 )
 
 // Post 文章
 type Post struct {
-	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement;index:idx_posts_user_id_id,priority:2"`
 	Title     string    `json:"title" binding:"required" gorm:"type:varchar(255);not null"`
 	Content   string    `json:"content" binding:"required" gorm:"type:text;not null"`
-	UserID    uint      `json:"user_id" gorm:"not null"`
-	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP"`
+	UserID    uint      `json:"user_id" gorm:"not null;index:idx_posts_user_id_id,priority:1"`
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP;index"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP;autoUpdateTime"`
+	// Tags 是文章的標籤，已規範化為小寫且不重複
+	Tags Tags `json:"tags" gorm:"type:jsonb;not null;default:'[]'"`
+	// Version 在每次更新時遞增，用於檢測並發編輯
+	Version uint    `json:"version" gorm:"not null;default:1" example:"1"`
+	Author  *Author `json:"author,omitempty" gorm:"-"`
+	// ReactionCounts 是各類型回應的非規範化計數，只能通過回應存儲原子地更新
+	ReactionCounts ReactionCounts `json:"reaction_counts" gorm:"type:jsonb;not null;default:'{}'"`
+	// IsBookmarked 只在已認證的請求中返回
+	IsBookmarked *bool `json:"is_bookmarked,omitempty" gorm:"-"`
+}
+
+// ReactionCounts 記錄每種回應類型的數量，以 JSONB 存儲
+type ReactionCounts map[string]int64
+
+// Value 實現 driver.Valuer 接口
+func (c ReactionCounts) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(c)
+	return string(b), err
+}
+
+// Scan 實現 sql.Scanner 接口
+func (c *ReactionCounts) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*c = ReactionCounts{}
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("cannot scan %T into ReactionCounts", src)
+	}
+	counts := ReactionCounts{}
+	if err := json.Unmarshal(data, &counts); err != nil {
+		return err
+	}
+	*c = counts
+	return nil
+}
+
+// Tags 是文章的標籤列表，以 JSONB 數組存儲
+type Tags []string
+
+// Value 實現 driver.Valuer 接口
+func (t Tags) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(t))
+	return string(b), err
+}
+
+// Scan 實現 sql.Scanner 接口
+func (t *Tags) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*t = Tags{}
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("cannot scan %T into Tags", src)
+	}
+	tags := Tags{}
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return err
+	}
+	*t = tags
+	return nil
+}
+
+// 標籤的數量和長度限制
+const (
+	MaxTags      = 10
+	MaxTagLength = 50
+)
+
+// tagPattern 標籤只能包含小寫字母（包括中文等無大小寫的文字）、數字和單個連字符，例如 "go" 或 "distributed-systems"
+var tagPattern = regexp.MustCompile(`^[\p{Ll}\p{Lo}0-9]+(-[\p{Ll}\p{Lo}0-9]+)*$`)
+
+// Author 是嵌入在文章響應中的作者摘要，只包含公開信息
+type Author struct {
+	ID        uint   `json:"id" example:"1"`
+	Username  string `json:"username" example:"johndoe"`
+	FirstName string `json:"firstName" example:"John"`
+	LastName  string `json:"lastName" example:"Doe"`
+	AvatarURL string `json:"avatarUrl" example:"/media/avatars/9f86d081884c7d65-128.png"`
 }
 
 // 定義一些常見的錯誤
 var (
-	ErrPostNotFound   = errors.New("post not found")
-	ErrUnauthorized   = errors.New("unauthorized to modify this post")
-	ErrInvalidTitle   = errors.New("invalid post title")
-	ErrInvalidContent = errors.New("invalid post content")
+	ErrPostNotFound    = errors.New("post not found")
+	ErrUnauthorized    = errors.New("unauthorized to modify this post")
+	ErrInvalidTitle    = errors.New("invalid post title")
+	ErrInvalidContent  = errors.New("invalid post content")
+	ErrAuthorNotFound  = errors.New("author not found")
+	ErrVersionConflict = errors.New("post has been modified since it was read")
+	ErrInvalidPatch    = errors.New("invalid patch document")
+	ErrPatchTestFailed = errors.New("patch test operation failed")
+	ErrInvalidTags     = errors.New("a post can have at most 10 tags of lowercase letters, digits and hyphens, each at most 50 characters")
+	ErrTagNotFound     = errors.New("tag not found")
 )
 
+// ConflictError 表示文章的當前狀態與客戶端的預期不符，Current 為服務器上的當前版本
+// Err 為具體原因：ErrVersionConflict 或 ErrPatchTestFailed
+type ConflictError struct {
+	Current *Post
+	Err     error
+}
+
+// Error 實現 error 接口
+func (e *ConflictError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap 使 errors.Is 可以判斷衝突的原因
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
 // Repository 定義文章存儲的接口
 type Repository interface {
-	FindAll(page, pageSize int) ([]Post, error)
-	FindByID(id uint) (*Post, error)
-	Create(post *Post) error
-	Update(post *Post) error
-	Delete(id uint) error
+	FindAll(ctx context.Context, page, pageSize int) ([]Post, error)
+	FindByUserID(ctx context.Context, userID uint, page, pageSize int) ([]Post, error)
+	FindByTag(ctx context.Context, tag string, page, pageSize int) ([]Post, error)
+	CountByUserID(ctx context.Context, userID uint) (int64, error)
+	FindFeed(ctx context.Context, followerID, beforeID uint, limit int) ([]Post, error)
+	FindByID(ctx context.Context, id uint) (*Post, error)
+	FindByIDs(ctx context.Context, ids []uint) ([]Post, error)
+	Create(ctx context.Context, post *Post) error
+	Update(ctx context.Context, post *Post) error // 只在版本未變時更新並遞增版本，否則返回 ErrVersionConflict
+	Delete(ctx context.Context, id uint) error
+	ReassignAuthor(ctx context.Context, fromUserID, toUserID uint) error
+}
+
+// AuthorRepository 定義查詢文章作者摘要的接口
+type AuthorRepository interface {
+	FindByIDs(ctx context.Context, ids []uint) (map[uint]Author, error)
+	FindByUsername(ctx context.Context, username string) (*Author, error)
 }
 
 // ValidateTitle 驗證文章標題是否符合要求
@@ -48,6 +184,35 @@ func ValidateContent(content string) error {
 	return nil
 }
 
+// NormalizeTag 將標籤轉換為小寫並去掉首尾空白，格式無效時返回 ErrInvalidTags
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if len([]rune(tag)) > MaxTagLength || !tagPattern.MatchString(tag) {
+		return "", ErrInvalidTags
+	}
+	return tag, nil
+}
+
+// NormalizeTags 規範化所有標籤並去除重複，保持原有順序
+func NormalizeTags(tags []string) (Tags, error) {
+	normalized := make(Tags, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > MaxTags {
+		return nil, ErrInvalidTags
+	}
+	return normalized, nil
+}
+
 // IsAuthor 檢查給定的用戶ID是否為文章作者
 func (p *Post) IsAuthor(userID uint) bool {
 	return p.UserID == userID
@@ -66,3 +231,13 @@ func (p *Post) UpdateContent(newTitle, newContent string) error {
 	p.UpdatedAt = time.Now()
 	return nil
 }
+
+// SetTags 規範化並替換文章的標籤
+func (p *Post) SetTags(tags []string) error {
+	normalized, err := NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	p.Tags = normalized
+	return nil
+}
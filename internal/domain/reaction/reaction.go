@@ -0,0 +1,73 @@
+package reaction
+
+import (
+	"blog-api/internal/domain/post"
+	"context"
+	"errors"
+	"time"
+)
+
+// Type 代表一種表情回應
+type Type string
+
+// 支持的表情回應類型
+const (
+	TypeLike      Type = "like"
+	TypeLove      Type = "love"
+	TypeLaugh     Type = "laugh"
+	TypeWow       Type = "wow"
+	TypeSad       Type = "sad"
+	TypeCelebrate Type = "celebrate"
+)
+
+// Emojis 回應類型對應的表情符號
+var Emojis = map[Type]string{
+	TypeLike:      "👍",
+	TypeLove:      "❤️",
+	TypeLaugh:     "😂",
+	TypeWow:       "😮",
+	TypeSad:       "😢",
+	TypeCelebrate: "🎉",
+}
+
+// Reaction 代表用戶對文章的一個回應，每個用戶對每種類型最多一個
+type Reaction struct {
+	PostID    uint      `json:"postId" gorm:"primaryKey"`
+	UserID    uint      `json:"userId" gorm:"primaryKey;index"`
+	Type      Type      `json:"type" gorm:"primaryKey;type:varchar(20)"`
+	CreatedAt time.Time `json:"createdAt" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// TableName 指定回應的表名
+func (Reaction) TableName() string {
+	return "post_reactions"
+}
+
+// Reactor 是回應列表中的一項，包含回應者的公開信息
+type Reactor struct {
+	User      post.Author `json:"user"`
+	Type      Type        `json:"type" example:"like"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// 定義一些常見的錯誤
+var (
+	ErrInvalidType = errors.New("invalid reaction type")
+)
+
+// Repository 定義回應存儲的接口
+// Add 和 Remove 必須與文章上的計數器在同一事務中原子地更新
+type Repository interface {
+	Add(ctx context.Context, r *Reaction) error                                                              // 重複回應不會報錯，也不會重複計數
+	Remove(ctx context.Context, postID, userID uint, reactionType Type) error                                // 未回應時不會報錯
+	FindReactors(ctx context.Context, postID uint, reactionType Type, page, pageSize int) ([]Reactor, error) // reactionType 為空時返回所有類型
+	FindByUserID(ctx context.Context, userID uint, page, pageSize int) ([]Reaction, error)
+}
+
+// ValidateType 驗證回應類型是否受支持
+func ValidateType(t Type) error {
+	if _, ok := Emojis[t]; !ok {
+		return ErrInvalidType
+	}
+	return nil
+}
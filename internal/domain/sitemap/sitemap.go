@@ -0,0 +1,27 @@
+package sitemap
+
+import (
+	"context"
+	"time"
+)
+
+// MaxURLsPerSitemap 是單個網站地圖文件允許的最大 URL 數量
+const MaxURLsPerSitemap = 50000
+
+// PostEntry 是網站地圖中的一篇文章
+type PostEntry struct {
+	ID        uint
+	UpdatedAt time.Time
+}
+
+// AuthorEntry 是網站地圖中的一個作者頁面，最後修改時間取其最近更新的文章
+type AuthorEntry struct {
+	Username  string
+	UpdatedAt time.Time
+}
+
+// Repository 定義讀取網站地圖數據的接口，只讀取生成 URL 所需的字段
+type Repository interface {
+	FindPostEntries(ctx context.Context) ([]PostEntry, error)
+	FindAuthorEntries(ctx context.Context) ([]AuthorEntry, error)
+}
@@ -0,0 +1,127 @@
+package token
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 定義權限範圍
+const (
+	ScopePostsRead    = "posts:read"
+	ScopePostsWrite   = "posts:write"
+	ScopeProfileRead  = "profile:read"
+	ScopeProfileWrite = "profile:write"
+	ScopeTokensManage = "tokens:manage"
+)
+
+// AllScopes 列出系統支持的所有權限範圍
+var AllScopes = Scopes{ScopePostsRead, ScopePostsWrite, ScopeProfileRead, ScopeProfileWrite, ScopeTokensManage}
+
+// AccessToken 代表用戶的個人訪問令牌（PAT）
+type AccessToken struct {
+	ID         uint       `json:"id" gorm:"primaryKey" example:"1"`
+	UserID     uint       `json:"-" gorm:"not null;index"`
+	Name       string     `json:"name" gorm:"type:varchar(100);not null" example:"ci-publisher"`
+	Prefix     string     `json:"prefix" gorm:"type:varchar(32);uniqueIndex;not null" example:"bpat_3f9a1c2e5b7d9f01"`
+	TokenHash  string     `json:"-" gorm:"type:varchar(64);not null"` // 僅保存令牌的 SHA-256 哈希
+	Scopes     Scopes     `json:"scopes" gorm:"type:text;not null" swaggertype:"array,string" example:"posts:read,posts:write"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty" example:"2025-10-20T15:00:00Z"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty" example:"2024-10-21T09:00:00Z"` // 精確到 TouchInterval
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt" gorm:"default:CURRENT_TIMESTAMP" example:"2024-10-20T14:00:00Z"`
+}
+
+// 定義一些常見的錯誤
+var (
+	ErrTokenNotFound     = errors.New("access token not found")
+	ErrTokenRevoked      = errors.New("access token has been revoked")
+	ErrTokenExpired      = errors.New("access token has expired")
+	ErrInvalidName       = errors.New("invalid access token name")
+	ErrInvalidScope      = errors.New("invalid access token scope")
+	ErrInvalidExpiry     = errors.New("access token expiry must be in the future")
+	ErrInsufficientScope = errors.New("insufficient scope")
+)
+
+// Repository 定義訪問令牌存儲的接口
+type Repository interface {
+	Create(ctx context.Context, t *AccessToken) error
+	FindByID(ctx context.Context, id uint) (*AccessToken, error)
+	FindByPrefix(ctx context.Context, prefix string) (*AccessToken, error)
+	FindByUserID(ctx context.Context, userID uint) ([]AccessToken, error)
+	Revoke(ctx context.Context, id uint, at time.Time) error // 已撤銷的令牌保持原來的撤銷時間
+	Touch(ctx context.Context, id uint, at time.Time) error  // 只更新未撤銷令牌的最後使用時間
+}
+
+// ValidateName 驗證令牌名稱是否符合要求
+func ValidateName(name string) error {
+	if len(strings.TrimSpace(name)) == 0 || len(name) > 100 {
+		return ErrInvalidName
+	}
+	return nil
+}
+
+// IsActive 檢查令牌是否仍然可用
+func (t *AccessToken) IsActive(now time.Time) error {
+	if t.RevokedAt != nil {
+		return ErrTokenRevoked
+	}
+	if t.ExpiresAt != nil && !now.Before(*t.ExpiresAt) {
+		return ErrTokenExpired
+	}
+	return nil
+}
+
+// TouchInterval 最後使用時間的更新間隔，避免每個請求都寫入數據庫
+const TouchInterval = time.Minute
+
+// NeedsTouch 檢查是否需要更新最後使用時間
+func (t *AccessToken) NeedsTouch(now time.Time) bool {
+	return t.LastUsedAt == nil || now.Sub(*t.LastUsedAt) >= TouchInterval
+}
+
+// Scopes 是一組權限範圍，在數據庫中以空格分隔的字符串保存
+type Scopes []string
+
+// Validate 檢查每個權限範圍是否為系統支持的範圍
+func (s Scopes) Validate() error {
+	for _, scope := range s {
+		if !AllScopes.Has(scope) {
+			return fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+	}
+	return nil
+}
+
+// Has 檢查是否包含指定的權限範圍
+func (s Scopes) Has(scope string) bool {
+	for _, v := range s {
+		if v == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Value 實現 driver.Valuer 接口
+func (s Scopes) Value() (driver.Value, error) {
+	return strings.Join(s, " "), nil
+}
+
+// Scan 實現 sql.Scanner 接口
+func (s *Scopes) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*s = nil
+	case string:
+		*s = strings.Fields(v)
+	case []byte:
+		*s = strings.Fields(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into Scopes", src)
+	}
+	return nil
+}
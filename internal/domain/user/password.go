@@ -0,0 +1,121 @@
+package user
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// 密碼策略規則的標識，供前端顯示對應的提示
+const (
+	RuleMinLength        = "min_length"
+	RuleMaxLength        = "max_length"
+	RuleContainsUsername = "contains_username"
+	RuleContainsEmail    = "contains_email"
+	RuleBreached         = "breached"
+)
+
+// BreachedPasswordChecker 定義檢查密碼是否出現在已洩露密碼列表中的接口
+type BreachedPasswordChecker interface {
+	IsBreached(password string) (bool, error)
+}
+
+// PasswordPolicy 定義密碼需要滿足的規則
+type PasswordPolicy struct {
+	MinLength        int
+	MaxLength        int // 為 0 時不限制
+	DisallowUsername bool
+	DisallowEmail    bool
+	Breached         BreachedPasswordChecker // 為 nil 時不檢查
+}
+
+// DefaultPasswordPolicy 默認的密碼策略
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:        8,
+	MaxLength:        128,
+	DisallowUsername: true,
+	DisallowEmail:    true,
+}
+
+// PasswordViolation 描述一條未通過的密碼規則
+type PasswordViolation struct {
+	Rule    string `json:"rule" example:"min_length"`
+	Message string `json:"message" example:"password must be at least 8 characters long"`
+}
+
+// PasswordPolicyError 包含所有未通過的密碼規則
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+// Error 實現 error 接口
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return "password does not meet policy: " + strings.Join(messages, "; ")
+}
+
+// Validate 根據策略驗證密碼，username 和 email 用於檢查密碼是否包含個人信息
+// 所有規則都會被檢查，違反的規則以 *PasswordPolicyError 返回
+func (p PasswordPolicy) Validate(password, username, email string) error {
+	var violations []PasswordViolation
+	length := utf8.RuneCountInString(password)
+
+	if length < p.MinLength {
+		violations = append(violations, PasswordViolation{
+			Rule:    RuleMinLength,
+			Message: fmt.Sprintf("password must be at least %d characters long", p.MinLength),
+		})
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, PasswordViolation{
+			Rule:    RuleMaxLength,
+			Message: fmt.Sprintf("password must be at most %d characters long", p.MaxLength),
+		})
+	}
+
+	lower := strings.ToLower(password)
+	if p.DisallowUsername && containsPersonalInfo(lower, username) {
+		violations = append(violations, PasswordViolation{
+			Rule:    RuleContainsUsername,
+			Message: "password must not contain the username",
+		})
+	}
+	if p.DisallowEmail && email != "" {
+		local, _, _ := strings.Cut(email, "@")
+		if containsPersonalInfo(lower, email) || containsPersonalInfo(lower, local) {
+			violations = append(violations, PasswordViolation{
+				Rule:    RuleContainsEmail,
+				Message: "password must not contain the email address",
+			})
+		}
+	}
+
+	if p.Breached != nil {
+		breached, err := p.Breached.IsBreached(password)
+		if err != nil {
+			return err
+		}
+		if breached {
+			violations = append(violations, PasswordViolation{
+				Rule:    RuleBreached,
+				Message: "password has appeared in a data breach",
+			})
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// containsPersonalInfo 檢查小寫密碼是否包含給定的個人信息，過短的值不參與比較
+func containsPersonalInfo(lowerPassword, value string) bool {
+	if utf8.RuneCountInString(value) < 3 {
+		return false
+	}
+	return strings.Contains(lowerPassword, strings.ToLower(value))
+}
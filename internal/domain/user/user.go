@@ -1,8 +1,14 @@
 package user
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"net/url"
 	"time"
+	"unicode/utf8"
 )
 
 // User 代表系統中的用戶實體
@@ -14,43 +20,152 @@ type User struct {
 	PasswordChangedAt time.Time  `json:"passwordChangedAt" gorm:"not null" example:"2024-10-20T15:00:00Z"`
 	FirstName         string     `json:"firstName" example:"John"`
 	LastName          string     `json:"lastName" example:"Doe"`
+	Bio               string     `json:"bio" gorm:"type:text" example:"Writing about Go and distributed systems."`
+	Website           string     `json:"website" gorm:"type:varchar(255)" example:"https://johndoe.dev"`
+	AvatarHash        string     `json:"-" gorm:"type:varchar(64)"`                                                      // 上傳頭像的內容哈希，為空時使用生成的默認頭像
+	PendingEmail      string     `json:"pendingEmail,omitempty" gorm:"type:varchar(255)" example:"john.doe@example.com"` // 等待驗證的新郵箱
+	EmailTokenHash    string     `json:"-" gorm:"type:varchar(64);index"`
+	EmailTokenExpires *time.Time `json:"-"`
 	CreatedAt         time.Time  `json:"createdAt" gorm:"default:CURRENT_TIMESTAMP" example:"2024-10-20T14:00:00Z"`
 	UpdatedAt         time.Time  `json:"updatedAt" gorm:"default:CURRENT_TIMESTAMP" example:"2024-10-20T14:30:00Z"`
 	LastLogin         *time.Time `json:"lastLogin,omitempty" example:"2024-10-20T16:00:00Z"`
 	IsActive          bool       `json:"isActive" gorm:"default:true" example:"true"`
 }
 
+// PublicProfile 是用戶的公開資料，不包含郵箱、狀態等私有字段
+type PublicProfile struct {
+	Username  string    `json:"username" example:"johndoe"`
+	FirstName string    `json:"firstName" example:"John"`
+	LastName  string    `json:"lastName" example:"Doe"`
+	Bio       string    `json:"bio" example:"Writing about Go and distributed systems."`
+	Website   string    `json:"website" example:"https://johndoe.dev"`
+	AvatarURL string    `json:"avatarUrl" example:"/media/avatars/9f86d081884c7d65-128.png"`
+	CreatedAt time.Time `json:"createdAt" example:"2024-10-20T14:00:00Z"`
+	// IsFollowing 表示當前用戶是否關注了該作者，只在已認證的請求中返回
+	IsFollowing *bool `json:"isFollowing,omitempty"`
+}
+
 // 定義一些常見的錯誤
 var (
 	ErrUserNotFound      = errors.New("user not found")
 	ErrInvalidPassword   = errors.New("invalid password")
 	ErrDuplicateUsername = errors.New("username already exists")
 	ErrDuplicateEmail    = errors.New("email already exists")
+	ErrInvalidName       = errors.New("name must be at most 50 characters")
+	ErrInvalidBio        = errors.New("bio must be at most 500 characters")
+	ErrInvalidWebsite    = errors.New("website must be an http or https URL of at most 255 characters")
+	ErrInvalidEmailToken = errors.New("invalid or expired email verification token")
+	ErrDeletionTarget    = errors.New("posts cannot be transferred to this user")
+	ErrUserHasPosts      = errors.New("account cannot be deleted while it still has posts")
+	ErrAccountInactive   = errors.New("account is not active")
+)
+
+// AccountDeletionPolicy 定義刪除賬戶時如何處理該用戶的文章
+type AccountDeletionPolicy string
+
+const (
+	// DeletionAnonymize 保留文章，並清除用戶的個人信息
+	DeletionAnonymize AccountDeletionPolicy = "anonymize"
+	// DeletionTransfer 將文章轉移給指定用戶後刪除賬戶
+	DeletionTransfer AccountDeletionPolicy = "transfer"
+	// DeletionGhost 將文章轉移給停用的 ghost 用戶後刪除賬戶
+	DeletionGhost AccountDeletionPolicy = "ghost"
+	// DeletionCascade 刪除賬戶及其所有文章
+	DeletionCascade AccountDeletionPolicy = "cascade"
+	// DeletionBlock 用戶仍有文章時拒絕刪除賬戶
+	DeletionBlock AccountDeletionPolicy = "block"
 )
 
+// Valid 檢查是否為支持的刪除策略
+func (p AccountDeletionPolicy) Valid() bool {
+	switch p {
+	case DeletionAnonymize, DeletionTransfer, DeletionGhost, DeletionCascade, DeletionBlock:
+		return true
+	}
+	return false
+}
+
+// DefaultGhostUsername 是 DeletionGhost 策略下接收文章的用戶名
+const DefaultGhostUsername = "ghost"
+
 // Repository 定義了用戶資料持久化的接口
 type Repository interface {
-	Create(user *User) error
-	FindByID(id uint) (*User, error)
-	FindByUsername(username string) (*User, error)
-	FindByEmail(email string) (*User, error)
-	Update(user *User) error
-	Delete(id uint) error
+	Create(ctx context.Context, user *User) error
+	FindByID(ctx context.Context, id uint) (*User, error)
+	FindByUsername(ctx context.Context, username string) (*User, error)
+	FindByEmail(ctx context.Context, email string) (*User, error)
+	FindByEmailTokenHash(ctx context.Context, tokenHash string) (*User, error)
+	CountByAvatarHash(ctx context.Context, avatarHash string) (int64, error)
+	SetAvatarHash(ctx context.Context, id uint, avatarHash string) (string, error) // 只寫入頭像哈希，返回之前的哈希
+	Update(ctx context.Context, user *User) error
+	Delete(ctx context.Context, id uint) error          // 用戶仍有文章時返回 ErrUserHasPosts，由數據庫外鍵保證
+	DeleteWithPosts(ctx context.Context, id uint) error // 在同一事務中刪除用戶及其文章
 }
 
-// ValidatePassword 驗證密碼是否符合要求
+// ValidatePassword 使用默認策略驗證密碼是否符合要求
 func ValidatePassword(password string) error {
-	if len(password) < 8 {
-		return errors.New("password must be at least 8 characters long")
+	return DefaultPasswordPolicy.Validate(password, "", "")
+}
+
+// ValidateProfile 驗證用戶可編輯的資料是否符合要求
+func ValidateProfile(firstName, lastName, bio, website string) error {
+	if utf8.RuneCountInString(firstName) > 50 || utf8.RuneCountInString(lastName) > 50 {
+		return ErrInvalidName
+	}
+	if utf8.RuneCountInString(bio) > 500 {
+		return ErrInvalidBio
+	}
+	if website != "" {
+		u, err := url.Parse(website)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(website) > 255 {
+			return ErrInvalidWebsite
+		}
 	}
 	return nil
 }
 
+// 頭像的尺寸和訪問路徑，URL 中包含內容哈希，因此可以長期緩存
+const (
+	DefaultAvatarSize  = 128
+	UploadedAvatarPath = "/media/avatars/"
+	IdenticonPath      = "/avatars/identicon/"
+)
+
+// AvatarSizes 頭像提供的所有尺寸
+var AvatarSizes = []int{64, 128, 256}
+
+// IdenticonKey 返回由用戶名決定的默認頭像標識
+func IdenticonKey(username string) string {
+	sum := sha256.Sum256([]byte(username))
+	return hex.EncodeToString(sum[:8])
+}
+
+// AvatarURL 返回用戶頭像的 URL，未上傳頭像時返回生成的默認頭像
+func AvatarURL(username, avatarHash string) string {
+	if avatarHash != "" {
+		return fmt.Sprintf("%s%s-%d.png", UploadedAvatarPath, avatarHash, DefaultAvatarSize)
+	}
+	return fmt.Sprintf("%s%s-%d.png", IdenticonPath, IdenticonKey(username), DefaultAvatarSize)
+}
+
 // FullName 返回用戶的全名
 func (u *User) FullName() string {
 	return u.FirstName + " " + u.LastName
 }
 
+// PublicProfile 返回用戶的公開資料
+func (u *User) PublicProfile() *PublicProfile {
+	return &PublicProfile{
+		Username:  u.Username,
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		Bio:       u.Bio,
+		Website:   u.Website,
+		AvatarURL: AvatarURL(u.Username, u.AvatarHash),
+		CreatedAt: u.CreatedAt,
+	}
+}
+
 // UpdateLastLogin 更新用戶的最後登錄時間
 func (u *User) UpdateLastLogin() {
 	now := time.Now()
@@ -62,3 +177,53 @@ func (u *User) ChangePassword(newPasswordHash string) {
 	u.PasswordHash = newPasswordHash
 	u.UpdatedAt = time.Now()
 }
+
+// RequestEmailChange 記錄待驗證的新郵箱，驗證完成前仍使用原郵箱
+func (u *User) RequestEmailChange(newEmail, tokenHash string, expiresAt time.Time) {
+	u.PendingEmail = newEmail
+	u.EmailTokenHash = tokenHash
+	u.EmailTokenExpires = &expiresAt
+}
+
+// ConfirmEmailChange 驗證令牌並將待驗證郵箱設為當前郵箱
+func (u *User) ConfirmEmailChange(now time.Time) error {
+	if u.PendingEmail == "" || u.EmailTokenExpires == nil || now.After(*u.EmailTokenExpires) {
+		return ErrInvalidEmailToken
+	}
+	u.Email = u.PendingEmail
+	u.PendingEmail = ""
+	u.EmailTokenHash = ""
+	u.EmailTokenExpires = nil
+	return nil
+}
+
+// NewGhost 創建接收已刪除賬戶文章的停用用戶，該用戶無法登錄
+func NewGhost(username string) *User {
+	return &User{
+		Username:          username,
+		Email:             username + "@deleted.invalid",
+		PasswordHash:      "!",
+		PasswordChangedAt: time.Now(),
+		FirstName:         "Deleted",
+		LastName:          "User",
+		IsActive:          false,
+	}
+}
+
+// Anonymize 清除用戶的個人信息並停用賬戶，保留記錄以便文章仍有作者
+func (u *User) Anonymize() {
+	u.Username = fmt.Sprintf("deleted-user-%d", u.ID)
+	u.Email = fmt.Sprintf("deleted-user-%d@deleted.invalid", u.ID)
+	u.PasswordHash = "!" // 不可能匹配任何密碼
+	u.PasswordChangedAt = time.Now()
+	u.FirstName = ""
+	u.LastName = ""
+	u.Bio = ""
+	u.Website = ""
+	u.AvatarHash = ""
+	u.PendingEmail = ""
+	u.EmailTokenHash = ""
+	u.EmailTokenExpires = nil
+	u.LastLogin = nil
+	u.IsActive = false
+}
@@ -1,86 +1,168 @@
 package auth
 
 import (
+	"blog-api/internal/domain/token"
 	"errors"
 	"fmt"
 	"time"
 
-	"github.com/golang-jwt/jwt"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 // 定義常量
 const (
 	TokenExpireDuration = time.Hour * 24 // 令牌有效期為 24 小時
+	DefaultAudience     = "blog-api"     // 未指定受眾時使用的默認受眾
+	DefaultIssuer       = "blog-api"
 )
 
 // Claims 自定義 JWT 聲明結構體
 type Claims struct {
-	UserID            uint      `json:"user_id"`
-	PasswordChangedAt time.Time `json:"pwd_changed_at"`
-	jwt.StandardClaims
+	UserID            uint         `json:"user_id"`
+	PasswordChangedAt time.Time    `json:"pwd_changed_at"`
+	Scopes            token.Scopes `json:"scopes"`
+	jwt.RegisteredClaims
 }
 
 // 定義錯誤
 var (
-	ErrInvalidToken = errors.New("invalid token")
-	ErrExpiredToken = errors.New("token has expired")
-	ErrNoSecretKey  = errors.New("no secret key set")
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrExpiredToken     = errors.New("token has expired")
+	ErrMalformedToken   = errors.New("token is malformed")
+	ErrInvalidSignature = errors.New("token signature is invalid")
+	ErrTokenNotYetValid = errors.New("token is not valid yet")
+	ErrInvalidIssuer    = errors.New("invalid token issuer")
+	ErrNoSecretKey      = errors.New("no secret key set")
+	ErrInvalidAudience  = errors.New("invalid token audience")
 )
 
+// JWTConfig 定義簽發和驗證令牌的配置
+type JWTConfig struct {
+	Issuer    string        // 為空時使用 DefaultIssuer
+	Audiences []string      // 允許的受眾，第一個為默認受眾，為空時僅使用 DefaultAudience
+	Leeway    time.Duration // 驗證 exp、nbf、iat 時允許的時鐘偏差
+}
+
 // JWTService 提供 JWT 相關功能
 type JWTService struct {
-	secretKey []byte
+	keys   *KeyManager
+	config JWTConfig
 }
 
 // NewJWTService 創建一個新的 JWTService 實例
-func NewJWTService(secretKey string) *JWTService {
-	return &JWTService{secretKey: []byte(secretKey)}
+func NewJWTService(keys *KeyManager, config JWTConfig) *JWTService {
+	if config.Issuer == "" {
+		config.Issuer = DefaultIssuer
+	}
+	if len(config.Audiences) == 0 {
+		config.Audiences = []string{DefaultAudience}
+	}
+	return &JWTService{keys: keys, config: config}
 }
 
-// GenerateToken 生成 JWT 令牌
-func (s *JWTService) GenerateToken(userID uint, passwordChangedAt time.Time) (string, error) {
+// JWKS 返回當前可公開的驗證公鑰
+func (s *JWTService) JWKS() JWKS {
+	return s.keys.Current().PublicJWKS()
+}
+
+// ResolveAudience 返回簽發令牌時使用的受眾，為空時返回默認受眾
+func (s *JWTService) ResolveAudience(audience string) (string, error) {
+	if audience == "" {
+		return s.config.Audiences[0], nil
+	}
+	for _, a := range s.config.Audiences {
+		if a == audience {
+			return audience, nil
+		}
+	}
+	return "", ErrInvalidAudience
+}
+
+// GenerateToken 生成 JWT 令牌，scopes 和 audience 限定令牌的權限與使用對象
+func (s *JWTService) GenerateToken(userID uint, passwordChangedAt time.Time, scopes token.Scopes, audience string) (string, error) {
+	audience, err := s.ResolveAudience(audience)
+	if err != nil {
+		return "", err
+	}
+
+	key, err := s.keys.Current().Active()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
 	claims := Claims{
 		UserID:            userID,
 		PasswordChangedAt: passwordChangedAt,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(TokenExpireDuration).Unix(),
-			IssuedAt:  time.Now().Unix(),
-			Issuer:    "blog-api",
+		Scopes:            scopes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.config.Issuer,
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(TokenExpireDuration)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.secretKey)
+	t := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	t.Header["kid"] = key.ID
+	return t.SignedString(key.signKey)
 }
 
-// ParseToken 解析 JWT 令牌
+// ParseToken 解析並嚴格驗證 JWT 令牌
+// 返回的錯誤區分過期、格式錯誤、簽名無效以及聲明無效等情況
 func (s *JWTService) ParseToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	keySet := s.keys.Current()
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{AlgHS256, AlgRS256, AlgEdDSA}),
+		jwt.WithIssuer(s.config.Issuer),
+		jwt.WithAudience(s.config.Audiences...),
+		jwt.WithLeeway(s.config.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithNotBeforeRequired(),
+		jwt.WithIssuedAt(),
+	)
+
+	claims := &Claims{}
+	_, err := parser.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, err := keySet.Lookup(kid)
+		if err != nil {
+			return nil, err
+		}
+		// 令牌的算法必須與密鑰的算法一致，防止算法混淆攻擊
+		if t.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
 		}
-		return s.secretKey, nil
+		return key.verifyKey, nil
 	})
-
 	if err != nil {
-		if ve, ok := err.(*jwt.ValidationError); ok {
-			if ve.Errors&jwt.ValidationErrorMalformed != 0 {
-				return nil, ErrInvalidToken
-			} else if ve.Errors&(jwt.ValidationErrorExpired|jwt.ValidationErrorNotValidYet) != 0 {
-				return nil, ErrExpiredToken
-			}
-		}
-		return nil, err
-	}
-
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
+		return nil, mapParseError(err)
 	}
 
-	return nil, ErrInvalidToken
+	return claims, nil
 }
 
 // ValidateToken 驗證 JWT 令牌
 func (s *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 	return s.ParseToken(tokenString)
 }
+
+// mapParseError 將 jwt 庫的錯誤轉換為本包定義的錯誤
+func mapParseError(err error) error {
+	switch {
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return ErrMalformedToken
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return ErrInvalidSignature
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return ErrExpiredToken
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return ErrTokenNotYetValid
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return ErrInvalidIssuer
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return ErrInvalidAudience
+	}
+	return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+}
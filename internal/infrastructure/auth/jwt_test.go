@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"blog-api/internal/domain/token"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// newTestJWTService 創建使用密鑰目錄中 HMAC 與 Ed25519 密鑰的服務
+func newTestJWTService(t *testing.T, active string) (*JWTService, string) {
+	t.Helper()
+	dir := t.TempDir()
+	edPrivate, _ := ed25519PEMs(t)
+	writeFile(t, dir, "hmac-1.hs256", testHMACSecret)
+	writeFile(t, dir, "ed-1.pem", edPrivate)
+	writeFile(t, dir, "active", active)
+	keys, err := NewKeyManager(KeySource{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewKeyManager: %v", err)
+	}
+	return NewJWTService(keys, JWTConfig{Audiences: []string{"blog-api", "admin"}}), dir
+}
+
+// validClaims 返回可以通過驗證的聲明
+func validClaims() Claims {
+	now := time.Now()
+	return Claims{
+		UserID: 7,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    DefaultIssuer,
+			Audience:  jwt.ClaimStrings{DefaultAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+}
+
+// signHS256 使用指定的 kid 和密鑰簽發 HS256 令牌
+func signHS256(t *testing.T, kid string, secret []byte, claims Claims) string {
+	t.Helper()
+	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if kid != "" {
+		tok.Header["kid"] = kid
+	}
+	s, err := tok.SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestGenerateTokenUsesActiveKey(t *testing.T) {
+	for _, active := range []string{"hmac-1", "ed-1"} {
+		t.Run(active, func(t *testing.T) {
+			s, _ := newTestJWTService(t, active)
+			changedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+			raw, err := s.GenerateToken(7, changedAt, token.Scopes{token.ScopePostsRead}, "admin")
+			if err != nil {
+				t.Fatalf("GenerateToken: %v", err)
+			}
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(raw, &Claims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.Header["kid"] != active {
+				t.Errorf("kid header = %v, want %s", parsed.Header["kid"], active)
+			}
+
+			claims, err := s.ParseToken(raw)
+			if err != nil {
+				t.Fatalf("ParseToken: %v", err)
+			}
+			if claims.UserID != 7 || !claims.PasswordChangedAt.Equal(changedAt) {
+				t.Errorf("claims = %+v", claims)
+			}
+			if len(claims.Scopes) != 1 || claims.Scopes[0] != token.ScopePostsRead {
+				t.Errorf("scopes = %v", claims.Scopes)
+			}
+			if len(claims.Audience) != 1 || claims.Audience[0] != "admin" {
+				t.Errorf("audience = %v", claims.Audience)
+			}
+		})
+	}
+}
+
+func TestParseTokenAfterRotation(t *testing.T) {
+	s, dir := newTestJWTService(t, "hmac-1")
+	old, err := s.GenerateToken(7, time.Time{}, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, dir, "active", "ed-1")
+	if err := s.keys.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if _, err := s.ParseToken(old); err != nil {
+		t.Errorf("token signed by the previous key: %v", err)
+	}
+
+	// 移除舊密鑰後，其簽發的令牌不再有效
+	if err := removeFile(dir, "hmac-1.hs256"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.keys.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if _, err := s.ParseToken(old); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("token signed by a removed key: error = %v, want %v", err, ErrInvalidSignature)
+	}
+}
+
+func TestParseTokenErrors(t *testing.T) {
+	s, _ := newTestJWTService(t, "hmac-1")
+	secret := []byte(testHMACSecret)
+	with := func(modify func(*Claims)) Claims {
+		c := validClaims()
+		modify(&c)
+		return c
+	}
+	past := jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	future := jwt.NewNumericDate(time.Now().Add(time.Hour))
+
+	noneToken, err := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"malformed", "not.a.token", ErrMalformedToken},
+		{"empty", "", ErrMalformedToken},
+		{"wrong secret", signHS256(t, "hmac-1", []byte("another-secret-another-secret-00"), validClaims()), ErrInvalidSignature},
+		{"unknown kid", signHS256(t, "rotated-out", secret, validClaims()), ErrInvalidSignature},
+		{"algorithm does not match key", signHS256(t, "ed-1", secret, validClaims()), ErrInvalidSignature},
+		{"alg none", noneToken, ErrInvalidSignature},
+		{"expired", signHS256(t, "hmac-1", secret, with(func(c *Claims) { c.ExpiresAt = past })), ErrExpiredToken},
+		{"not before in the future", signHS256(t, "hmac-1", secret, with(func(c *Claims) { c.NotBefore = future })), ErrTokenNotYetValid},
+		{"issued in the future", signHS256(t, "hmac-1", secret, with(func(c *Claims) { c.IssuedAt = future })), ErrTokenNotYetValid},
+		{"wrong issuer", signHS256(t, "hmac-1", secret, with(func(c *Claims) { c.Issuer = "someone-else" })), ErrInvalidIssuer},
+		{"wrong audience", signHS256(t, "hmac-1", secret, with(func(c *Claims) { c.Audience = jwt.ClaimStrings{"other"} })), ErrInvalidAudience},
+		{"missing expiry", signHS256(t, "hmac-1", secret, with(func(c *Claims) { c.ExpiresAt = nil })), ErrInvalidToken},
+		{"missing not before", signHS256(t, "hmac-1", secret, with(func(c *Claims) { c.NotBefore = nil })), ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.ParseToken(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseToken error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseTokenLeeway(t *testing.T) {
+	keys, err := NewKeyManager(KeySource{SecretKey: testHMACSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := validClaims()
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-10 * time.Second))
+	raw := signHS256(t, "", []byte(testHMACSecret), claims)
+
+	if _, err := NewJWTService(keys, JWTConfig{}).ParseToken(raw); !errors.Is(err, ErrExpiredToken) {
+		t.Errorf("without leeway: error = %v, want %v", err, ErrExpiredToken)
+	}
+	if _, err := NewJWTService(keys, JWTConfig{Leeway: time.Minute}).ParseToken(raw); err != nil {
+		t.Errorf("with leeway: %v", err)
+	}
+}
+
+func TestResolveAudience(t *testing.T) {
+	s, _ := newTestJWTService(t, "hmac-1")
+	tests := []struct {
+		audience string
+		want     string
+		wantErr  error
+	}{
+		{"", "blog-api", nil},
+		{"blog-api", "blog-api", nil},
+		{"admin", "admin", nil},
+		{"unknown", "", ErrInvalidAudience},
+	}
+	for _, tt := range tests {
+		got, err := s.ResolveAudience(tt.audience)
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("ResolveAudience(%q) = %q, %v; want %q, %v", tt.audience, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
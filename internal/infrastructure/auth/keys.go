@@ -0,0 +1,304 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 支持的簽名算法
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// 密鑰目錄中的文件約定
+const (
+	pemKeyExt      = ".pem"   // RSA 或 Ed25519 的私鑰（可簽名）或公鑰（僅驗證）
+	hmacKeyExt     = ".hs256" // HS256 共享密鑰
+	activeKeyFile  = "active" // 內容為當前簽名密鑰的 kid
+	legacyKeyID    = "default"
+	minHMACKeySize = 32
+)
+
+// 定義錯誤
+var (
+	ErrNoSigningKey   = errors.New("no active signing key")
+	ErrUnknownKeyID   = errors.New("unknown key id")
+	ErrUnsupportedKey = errors.New("unsupported key type")
+)
+
+// Key 代表一個以 kid 標識的 JWT 密鑰
+type Key struct {
+	ID        string
+	Algorithm string
+	signKey   crypto.PrivateKey // 為 nil 時僅用於驗證
+	verifyKey crypto.PublicKey
+}
+
+// CanSign 檢查密鑰是否可以用於簽名
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// KeySet 是某一時刻密鑰配置的不可變快照
+type KeySet struct {
+	keys     map[string]*Key
+	activeID string
+}
+
+// Active 返回當前的簽名密鑰
+func (ks *KeySet) Active() (*Key, error) {
+	k, ok := ks.keys[ks.activeID]
+	if !ok || !k.CanSign() {
+		return nil, ErrNoSigningKey
+	}
+	return k, nil
+}
+
+// Lookup 根據 kid 查找驗證密鑰，未提供 kid 的舊令牌使用默認密鑰
+func (ks *KeySet) Lookup(kid string) (*Key, error) {
+	if kid == "" {
+		kid = legacyKeyID
+	}
+	k, ok := ks.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+	return k, nil
+}
+
+// KeySource 描述密鑰的來源
+type KeySource struct {
+	Dir          string // 密鑰目錄，可在運行時新增或替換文件
+	ActiveKeyID  string // 未提供 active 文件時使用的簽名密鑰
+	SecretKey    string // 舊版 JWT_SECRET_KEY，作為 kid 為 default 的 HS256 密鑰
+	PrivateKey   string // PEM 格式的私鑰，通過環境變量提供
+	PrivateKeyID string
+}
+
+// KeyManager 管理 JWT 密鑰，並支持在不重新部署的情況下重新加載
+type KeyManager struct {
+	source KeySource
+	mu     sync.RWMutex
+	set    *KeySet
+}
+
+// NewKeyManager 創建 KeyManager 並加載初始密鑰
+func NewKeyManager(source KeySource) (*KeyManager, error) {
+	m := &KeyManager{source: source}
+	if err := m.Reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Current 返回當前的密鑰快照
+func (m *KeyManager) Current() *KeySet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.set
+}
+
+// Reload 從配置的來源重新加載所有密鑰
+// 加載失敗時保留原有密鑰，避免錯誤的文件導致所有用戶登出
+func (m *KeyManager) Reload() error {
+	set, err := loadKeySet(m.source)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.set = set
+	m.mu.Unlock()
+	return nil
+}
+
+// Watch 按給定的間隔定期重新加載密鑰目錄
+func (m *KeyManager) Watch(interval time.Duration) {
+	if m.source.Dir == "" || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := m.Reload(); err != nil {
+				log.Printf("Failed to reload JWT keys: %v", err)
+			}
+		}
+	}()
+}
+
+// loadKeySet 根據來源構建密鑰快照
+func loadKeySet(source KeySource) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*Key), activeID: source.ActiveKeyID}
+
+	if source.SecretKey != "" {
+		set.keys[legacyKeyID] = &Key{ID: legacyKeyID, Algorithm: AlgHS256, signKey: []byte(source.SecretKey), verifyKey: []byte(source.SecretKey)}
+		if set.activeID == "" {
+			set.activeID = legacyKeyID
+		}
+	}
+
+	if source.PrivateKey != "" {
+		kid := source.PrivateKeyID
+		if kid == "" {
+			return nil, errors.New("JWT private key from environment requires a key id")
+		}
+		k, err := parsePEMKey(kid, []byte(source.PrivateKey))
+		if err != nil {
+			return nil, err
+		}
+		set.keys[kid] = k
+	}
+
+	if source.Dir != "" {
+		if err := loadKeyDir(source.Dir, set); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := set.Active(); err != nil {
+		return nil, fmt.Errorf("%w: %q", err, set.activeID)
+	}
+	return set, nil
+}
+
+// loadKeyDir 從目錄中加載密鑰文件
+func loadKeyDir(dir string, set *KeySet) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+
+		ext := filepath.Ext(name)
+		kid := strings.TrimSuffix(name, ext)
+		switch {
+		case name == activeKeyFile:
+			set.activeID = strings.TrimSpace(string(data))
+		case ext == pemKeyExt:
+			k, err := parsePEMKey(kid, data)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			set.keys[kid] = k
+		case ext == hmacKeyExt:
+			secret := []byte(strings.TrimSpace(string(data)))
+			if len(secret) < minHMACKeySize {
+				return fmt.Errorf("%s: HMAC key must be at least %d bytes", name, minHMACKeySize)
+			}
+			set.keys[kid] = &Key{ID: kid, Algorithm: AlgHS256, signKey: secret, verifyKey: secret}
+		}
+	}
+	return nil
+}
+
+// parsePEMKey 解析 PEM 格式的 RSA 或 Ed25519 私鑰或公鑰
+func parsePEMKey(kid string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return &Key{ID: kid, Algorithm: AlgRS256, signKey: priv, verifyKey: &priv.PublicKey}, nil
+	case "PRIVATE KEY":
+		priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch p := priv.(type) {
+		case *rsa.PrivateKey:
+			return &Key{ID: kid, Algorithm: AlgRS256, signKey: p, verifyKey: &p.PublicKey}, nil
+		case ed25519.PrivateKey:
+			return &Key{ID: kid, Algorithm: AlgEdDSA, signKey: p, verifyKey: p.Public()}, nil
+		}
+	case "PUBLIC KEY":
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch p := pub.(type) {
+		case *rsa.PublicKey:
+			return &Key{ID: kid, Algorithm: AlgRS256, verifyKey: p}, nil
+		case ed25519.PublicKey:
+			return &Key{ID: kid, Algorithm: AlgEdDSA, verifyKey: p}, nil
+		}
+	}
+	return nil, ErrUnsupportedKey
+}
+
+// JWK 代表 JSON Web Key 中的一個公鑰
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+// JWKS 代表 JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKS 返回所有非對稱密鑰的公鑰集合，對稱密鑰不會被公開
+func (ks *KeySet) PublicJWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, k := range ks.keys {
+		switch pub := k.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				KeyType:   "RSA",
+				KeyID:     k.ID,
+				Algorithm: k.Algorithm,
+				Use:       "sig",
+				N:         base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				KeyType:   "OKP",
+				KeyID:     k.ID,
+				Algorithm: k.Algorithm,
+				Use:       "sig",
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].KeyID < jwks.Keys[j].KeyID })
+	return jwks
+}
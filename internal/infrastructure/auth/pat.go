@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// 個人訪問令牌的格式為 bpat_<16位十六進制標識>_<隨機密鑰>
+// 標識用於查找令牌且必須唯一，64 位隨機數在實際的令牌數量下不會碰撞；
+// 早期簽發的令牌使用 8 位標識，解析時仍然接受
+const (
+	PATPrefix         = "bpat_"
+	patIDLength       = 16
+	legacyPATIDLength = 8
+	patSecretLength   = 32
+)
+
+// GeneratePAT 生成一個新的個人訪問令牌，返回明文令牌、可見前綴和哈希值
+func GeneratePAT() (raw, prefix, tokenHash string, err error) {
+	id := make([]byte, patIDLength/2)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", "", err
+	}
+	secret := make([]byte, patSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+
+	prefix = PATPrefix + hex.EncodeToString(id)
+	raw = prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return raw, prefix, HashPAT(raw), nil
+}
+
+// IsPAT 檢查給定的字符串是否為個人訪問令牌
+func IsPAT(raw string) bool {
+	return strings.HasPrefix(raw, PATPrefix)
+}
+
+// ParsePATPrefix 從明文令牌中提取可見前綴，標識必須是十六進制且長度有效
+func ParsePATPrefix(raw string) (string, bool) {
+	if !IsPAT(raw) {
+		return "", false
+	}
+	id, secret, ok := strings.Cut(raw[len(PATPrefix):], "_")
+	if !ok || secret == "" || (len(id) != patIDLength && len(id) != legacyPATIDLength) {
+		return "", false
+	}
+	if _, err := hex.DecodeString(id); err != nil {
+		return "", false
+	}
+	return PATPrefix + id, true
+}
+
+// HashPAT 計算令牌的 SHA-256 哈希
+// 令牌本身具有足夠的熵，因此不需要使用慢哈希
+func HashPAT(raw string) string {
+	return HashOpaqueToken(raw)
+}
+
+// GenerateOpaqueToken 生成一次性的隨機令牌（如郵箱驗證鏈接），返回明文和哈希
+func GenerateOpaqueToken() (raw, tokenHash string, err error) {
+	secret := make([]byte, patSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	raw = base64.RawURLEncoding.EncodeToString(secret)
+	return raw, HashOpaqueToken(raw), nil
+}
+
+// HashOpaqueToken 計算隨機令牌的 SHA-256 哈希
+func HashOpaqueToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// ComparePAT 以常數時間比較明文令牌與保存的哈希
+func ComparePAT(raw, tokenHash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashPAT(raw)), []byte(tokenHash)) == 1
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGeneratePAT(t *testing.T) {
+	raw, prefix, tokenHash, err := GeneratePAT()
+	if err != nil {
+		t.Fatalf("GeneratePAT: %v", err)
+	}
+	if len(prefix) != len(PATPrefix)+16 || !strings.HasPrefix(raw, prefix+"_") {
+		t.Errorf("raw = %q, prefix = %q; want a 64-bit hex identifier", raw, prefix)
+	}
+	if parsed, ok := ParsePATPrefix(raw); !ok || parsed != prefix {
+		t.Errorf("ParsePATPrefix(%q) = %q, %v; want %q", raw, parsed, ok, prefix)
+	}
+	if tokenHash != HashPAT(raw) || !ComparePAT(raw, tokenHash) {
+		t.Error("token hash does not match the raw token")
+	}
+	if ComparePAT(raw+"x", tokenHash) {
+		t.Error("ComparePAT accepted a different token")
+	}
+
+	other, otherPrefix, _, err := GeneratePAT()
+	if err != nil {
+		t.Fatalf("GeneratePAT: %v", err)
+	}
+	if other == raw || otherPrefix == prefix {
+		t.Error("GeneratePAT returned the same token twice")
+	}
+}
+
+func TestParsePATPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		prefix string
+		ok     bool
+	}{
+		{"current", "bpat_0123456789abcdef_secret", "bpat_0123456789abcdef", true},
+		{"legacy 8-digit identifier", "bpat_3f9a1c2e_secret", "bpat_3f9a1c2e", true},
+		{"secret with underscores", "bpat_0123456789abcdef_a_b_c", "bpat_0123456789abcdef", true},
+		{"not a PAT", "eyJhbGciOiJIUzI1NiJ9.e30.sig", "", false},
+		{"missing secret", "bpat_0123456789abcdef_", "", false},
+		{"missing separator", "bpat_0123456789abcdef", "", false},
+		{"wrong identifier length", "bpat_0123456789_secret", "", false},
+		{"non-hex identifier", "bpat_0123456789abcdeg_secret", "", false},
+		{"empty identifier", "bpat__secret", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix, ok := ParsePATPrefix(tt.raw)
+			if prefix != tt.prefix || ok != tt.ok {
+				t.Errorf("ParsePATPrefix(%q) = %q, %v; want %q, %v", tt.raw, prefix, ok, tt.prefix, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package breach
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var (
+	// ErrInvalidLine 表示洩露密碼文件中存在無法解析的行
+	ErrInvalidLine = errors.New("invalid breached password line")
+	// ErrUnsorted 表示洩露密碼文件沒有按哈希升序排列
+	ErrUnsorted = errors.New("breached password file is not sorted by hash")
+)
+
+// FileChecker 使用本地的已洩露密碼哈希文件進行離線檢查
+// 文件每行為一個大寫或小寫的 SHA-1 十六進制哈希，可帶有 ":次數" 後綴，必須按哈希升序排列
+// （Have I Been Pwned 按哈希排序的下載格式），允許空行和 # 開頭的註釋行。
+// 列表不會讀入內存，查詢時直接在文件中二分查找，因此文件大小不受內存限制
+type FileChecker struct {
+	file *os.File
+	size int64
+}
+
+// NewFileChecker 打開已洩露密碼哈希文件，並驗證每一行的格式和順序
+// 驗證需要完整讀取一次文件，但只佔用常量內存
+func NewFileChecker(path string) (*FileChecker, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &FileChecker{file: f, size: info.Size()}, nil
+}
+
+// validate 檢查文件的每一行都是有效的哈希，且哈希按升序排列
+func validate(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	previous := ""
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		digest, ok := parseLine(scanner.Text())
+		if !ok {
+			return fmt.Errorf("%w at line %d", ErrInvalidLine, lineNo)
+		}
+		if digest == "" {
+			continue
+		}
+		if digest < previous {
+			return fmt.Errorf("%w at line %d", ErrUnsorted, lineNo)
+		}
+		previous = digest
+	}
+	return scanner.Err()
+}
+
+// parseLine 解析一行並返回大寫的哈希，空行和註釋行返回空字符串
+func parseLine(line string) (digest string, ok bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", true
+	}
+	digest, count, hasCount := strings.Cut(line, ":")
+	if len(digest) != sha1.Size*2 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(digest); err != nil {
+		return "", false
+	}
+	if hasCount && !isDigits(count) {
+		return "", false
+	}
+	return strings.ToUpper(digest), true
+}
+
+// isDigits 檢查字符串是否為非空的十進制數字
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsBreached 檢查密碼是否出現在已洩露密碼列表中
+func (c *FileChecker) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	target := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	// 查找第一個哈希不小於目標的行所在的偏移量，record 隨偏移量單調不減
+	lo, hi := int64(0), c.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		digest, err := c.record(mid)
+		if err != nil {
+			return false, err
+		}
+		if digest == "" || digest >= target {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	digest, err := c.record(lo)
+	if err != nil {
+		return false, err
+	}
+	return digest == target, nil
+}
+
+// record 返回從 offset 或之後開始的第一行哈希，沒有更多的哈希時返回空字符串
+func (c *FileChecker) record(offset int64) (string, error) {
+	start := offset
+	if offset > 0 {
+		// 從前一個字節開始讀取並跳過到換行符為止的內容，使恰好從 offset 開始的行不被跳過
+		start = offset - 1
+	}
+	r := bufio.NewReader(io.NewSectionReader(c.file, start, c.size-start))
+	if offset > 0 {
+		if _, err := r.ReadString('\n'); err != nil {
+			if err == io.EOF {
+				return "", nil
+			}
+			return "", err
+		}
+	}
+	for {
+		line, err := r.ReadString('\n')
+		if line != "" {
+			digest, ok := parseLine(line)
+			if !ok {
+				return "", ErrInvalidLine // 文件在加載之後被修改
+			}
+			if digest != "" {
+				return digest, nil
+			}
+		}
+		if err == io.EOF {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// Close 關閉已洩露密碼哈希文件
+func (c *FileChecker) Close() error {
+	return c.file.Close()
+}
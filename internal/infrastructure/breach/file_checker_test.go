@@ -0,0 +1,131 @@
+package breach
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// sha1Hex 返回密碼的大寫 SHA-1 哈希
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// writeList 將內容寫入臨時文件並返回路徑
+func writeList(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// openList 使用給定內容創建 FileChecker
+func openList(t *testing.T, content string) *FileChecker {
+	t.Helper()
+	c, err := NewFileChecker(writeList(t, content))
+	if err != nil {
+		t.Fatalf("NewFileChecker: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestIsBreached(t *testing.T) {
+	breached := []string{"password", "123456", "qwerty", "letmein", "dragon", "monkey", "football"}
+	var lines []string
+	for i, p := range breached {
+		digest := sha1Hex(p)
+		if i%2 == 0 {
+			digest = strings.ToLower(digest)
+		}
+		lines = append(lines, fmt.Sprintf("%s:%d", digest, i+1))
+	}
+	sort.Slice(lines, func(i, j int) bool { return strings.ToUpper(lines[i]) < strings.ToUpper(lines[j]) })
+
+	layouts := map[string]string{
+		"unix newlines":      strings.Join(lines, "\n") + "\n",
+		"windows newlines":   strings.Join(lines, "\r\n") + "\r\n",
+		"no final newline":   strings.Join(lines, "\n"),
+		"comments and blank": "# breached passwords\n\n" + strings.Join(lines[:3], "\n") + "\n\n# more\n" + strings.Join(lines[3:], "\n") + "\n",
+	}
+	for name, content := range layouts {
+		t.Run(name, func(t *testing.T) {
+			c := openList(t, content)
+			for _, p := range breached {
+				if ok, err := c.IsBreached(p); err != nil || !ok {
+					t.Errorf("IsBreached(%q) = %v, %v; want true", p, ok, err)
+				}
+			}
+			for _, p := range []string{"correct horse battery staple", "", "passw0rd"} {
+				if ok, err := c.IsBreached(p); err != nil || ok {
+					t.Errorf("IsBreached(%q) = %v, %v; want false", p, ok, err)
+				}
+			}
+		})
+	}
+}
+
+func TestIsBreachedEmptyList(t *testing.T) {
+	c := openList(t, "")
+	if ok, err := c.IsBreached("password"); err != nil || ok {
+		t.Errorf("IsBreached = %v, %v; want false", ok, err)
+	}
+}
+
+func TestNewFileCheckerRejectsInvalidFiles(t *testing.T) {
+	low, high := sha1Hex("123456"), sha1Hex("password")
+	if low > high {
+		low, high = high, low
+	}
+	tests := []struct {
+		name    string
+		content string
+		wantErr error
+	}{
+		{"short hash", low[:39] + "\n", ErrInvalidLine},
+		{"long hash", low + "0\n", ErrInvalidLine},
+		{"not hex", "Z" + low[1:] + "\n", ErrInvalidLine},
+		{"invalid count", low + ":many\n", ErrInvalidLine},
+		{"empty count", low + ":\n", ErrInvalidLine},
+		{"plaintext password", "password\n", ErrInvalidLine},
+		{"unsorted", high + "\n" + low + "\n", ErrUnsorted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFileChecker(writeList(t, tt.content))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewFileChecker error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := NewFileChecker(filepath.Join(t.TempDir(), "missing.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file: error = %v, want %v", err, os.ErrNotExist)
+	}
+}
+
+func TestIsBreachedLargeList(t *testing.T) {
+	var lines []string
+	for i := 0; i < 2000; i += 2 {
+		lines = append(lines, sha1Hex(fmt.Sprint(i))+":1")
+	}
+	sort.Strings(lines)
+	c := openList(t, strings.Join(lines, "\n")+"\n")
+
+	// 偶數在列表中，奇數不在
+	for i := 0; i < 2000; i++ {
+		ok, err := c.IsBreached(fmt.Sprint(i))
+		if err != nil || ok != (i%2 == 0) {
+			t.Fatalf("IsBreached(%d) = %v, %v; want %v", i, ok, err, i%2 == 0)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package hash
+
+import (
+	"strings"
+	"testing"
+)
+
+// testArgon2Params 是讓測試快速運行的最小參數
+var testArgon2Params = Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 8, KeyLength: 16}
+
+func TestArgon2idHashAndVerify(t *testing.T) {
+	h := NewArgon2idHasher(testArgon2Params)
+	encoded, err := h.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=64,t=1,p=1$") || !h.Supports(encoded) {
+		t.Errorf("Hash = %q, want a PHC string with the configured parameters", encoded)
+	}
+
+	if ok, err := h.Verify(encoded, "correct horse"); !ok || err != nil {
+		t.Errorf("Verify(correct) = %v, %v; want true", ok, err)
+	}
+	if ok, err := h.Verify(encoded, "wrong horse"); ok || err != nil {
+		t.Errorf("Verify(wrong) = %v, %v; want false", ok, err)
+	}
+
+	other, err := h.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if other == encoded {
+		t.Error("two hashes of the same password share a salt")
+	}
+}
+
+func TestDecodeArgon2idRoundTrip(t *testing.T) {
+	salt, key := []byte("saltsalt"), []byte("0123456789abcdef")
+	params, gotSalt, gotKey, err := decodeArgon2id(encodeArgon2id(testArgon2Params, salt, key))
+	if err != nil {
+		t.Fatalf("decodeArgon2id: %v", err)
+	}
+	if params != testArgon2Params || string(gotSalt) != string(salt) || string(gotKey) != string(key) {
+		t.Errorf("decodeArgon2id = %+v, %q, %q", params, gotSalt, gotKey)
+	}
+}
+
+func TestDecodeArgon2idRejectsMalformedHashes(t *testing.T) {
+	const salt, key = "c2FsdHNhbHQ", "MDEyMzQ1Njc4OWFiY2RlZg"
+	tests := map[string]string{
+		"wrong algorithm": "$argon2i$v=19$m=64,t=1,p=1$" + salt + "$" + key,
+		"wrong version":   "$argon2id$v=16$m=64,t=1,p=1$" + salt + "$" + key,
+		"missing part":    "$argon2id$v=19$m=64,t=1,p=1$" + salt,
+		"bad params":      "$argon2id$v=19$m=x,t=1,p=1$" + salt + "$" + key,
+		"zero memory":     "$argon2id$v=19$m=0,t=1,p=1$" + salt + "$" + key,
+		"zero iterations": "$argon2id$v=19$m=64,t=0,p=1$" + salt + "$" + key,
+		"zero threads":    "$argon2id$v=19$m=64,t=1,p=0$" + salt + "$" + key,
+		"bad salt":        "$argon2id$v=19$m=64,t=1,p=1$!!$" + key,
+		"empty salt":      "$argon2id$v=19$m=64,t=1,p=1$$" + key,
+		"empty key":       "$argon2id$v=19$m=64,t=1,p=1$" + salt + "$",
+	}
+	h := NewArgon2idHasher(testArgon2Params)
+	for name, encoded := range tests {
+		if _, _, _, err := decodeArgon2id(encoded); err != ErrMalformedHash {
+			t.Errorf("%s: decodeArgon2id error = %v, want %v", name, err, ErrMalformedHash)
+		}
+		// 無法解析的哈希絕不能通過驗證
+		if ok, _ := h.Verify(encoded, ""); ok {
+			t.Errorf("%s: Verify accepted a malformed hash", name)
+		}
+	}
+}
+
+func TestArgon2idNeedsRehash(t *testing.T) {
+	h := NewArgon2idHasher(testArgon2Params)
+	encoded, err := h.Hash("password")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if h.NeedsRehash(encoded) {
+		t.Error("NeedsRehash = true for a hash with the current parameters")
+	}
+
+	stronger := testArgon2Params
+	stronger.Iterations = 2
+	if !NewArgon2idHasher(stronger).NeedsRehash(encoded) {
+		t.Error("NeedsRehash = false after the iterations changed")
+	}
+	longerSalt := testArgon2Params
+	longerSalt.SaltLength = 16
+	if !NewArgon2idHasher(longerSalt).NeedsRehash(encoded) {
+		t.Error("NeedsRehash = false after the salt length changed")
+	}
+	if !h.NeedsRehash("$argon2id$broken") {
+		t.Error("NeedsRehash = false for a malformed hash")
+	}
+}
+
+func TestArgon2ParamsValidate(t *testing.T) {
+	if err := DefaultArgon2Params.Validate(); err != nil {
+		t.Errorf("DefaultArgon2Params.Validate() = %v", err)
+	}
+	if err := testArgon2Params.Validate(); err != nil {
+		t.Errorf("testArgon2Params.Validate() = %v", err)
+	}
+
+	tests := map[string]func(p *Argon2Params){
+		"zero iterations":   func(p *Argon2Params) { p.Iterations = 0 },
+		"zero parallelism":  func(p *Argon2Params) { p.Parallelism = 0 },
+		"memory per thread": func(p *Argon2Params) { p.Memory, p.Parallelism = 15, 2 },
+		"zero salt":         func(p *Argon2Params) { p.SaltLength = 0 },
+		"zero key":          func(p *Argon2Params) { p.KeyLength = 0 },
+	}
+	for name, modify := range tests {
+		p := testArgon2Params
+		modify(&p)
+		if err := p.Validate(); err != ErrInvalidArgon2Params {
+			t.Errorf("%s: Validate() = %v, want %v", name, err, ErrInvalidArgon2Params)
+		}
+	}
+}
+
+func TestPasswordHasherUpgradesBcrypt(t *testing.T) {
+	bcryptHasher := NewBcryptHasher(4)
+	h := NewPasswordHasher(NewArgon2idHasher(testArgon2Params), bcryptHasher)
+
+	legacy, err := bcryptHasher.Hash("password")
+	if err != nil {
+		t.Fatalf("bcrypt Hash: %v", err)
+	}
+	if ok, err := h.Verify(legacy, "password"); !ok || err != nil {
+		t.Errorf("Verify(bcrypt) = %v, %v; want true", ok, err)
+	}
+	if !h.NeedsRehash(legacy) {
+		t.Error("NeedsRehash(bcrypt) = false, want true")
+	}
+
+	current, err := h.Hash("password")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if !strings.HasPrefix(current, argon2idPrefix) || h.NeedsRehash(current) {
+		t.Errorf("Hash = %q, want a current argon2id hash", current)
+	}
+	if !IsHashedPassword([]byte(current)) || !IsHashedPassword([]byte(legacy)) || IsHashedPassword([]byte("password")) {
+		t.Error("IsHashedPassword does not recognise the supported formats")
+	}
+
+	if _, err := h.Verify("plain", "plain"); err != ErrUnknownHashFormat {
+		t.Errorf("Verify(plain) error = %v, want %v", err, ErrUnknownHashFormat)
+	}
+}
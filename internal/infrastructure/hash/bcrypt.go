@@ -49,8 +49,51 @@ func GenerateFromPasswordWithDefault(password string) ([]byte, error) {
 	return GenerateFromPassword(password, DefaultCost)
 }
 
-// IsHashedPassword 檢查給定的字節序列是否可能是 bcrypt 哈希
-func IsHashedPassword(data []byte) bool {
+// BcryptHasher 使用 bcrypt 算法對密碼進行哈希
+// 注意 bcrypt 只使用密碼的前 72 個字節，新哈希應優先使用 argon2id
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher 創建一個新的 BcryptHasher 實例
+func NewBcryptHasher(cost int) *BcryptHasher {
+	return &BcryptHasher{cost: cost}
+}
+
+// Hash 使用 bcrypt 對密碼進行哈希
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := GenerateFromPassword(password, h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Verify 檢查密碼是否與 bcrypt 哈希匹配
+func (h *BcryptHasher) Verify(encoded, password string) (bool, error) {
+	err := CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// NeedsRehash 當哈希的代價與當前配置不同時返回 true
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := Cost([]byte(encoded))
+	return err != nil || cost != h.cost
+}
+
+// Supports 檢查是否為 bcrypt 格式的哈希
+func (h *BcryptHasher) Supports(encoded string) bool {
+	return isBcryptHash([]byte(encoded))
+}
+
+// isBcryptHash 檢查給定的字節序列是否可能是 bcrypt 哈希
+func isBcryptHash(data []byte) bool {
 	if len(data) < 4 {
 		return false
 	}
@@ -0,0 +1,73 @@
+package hash
+
+import (
+	"errors"
+	"strings"
+)
+
+// 定義可能的錯誤
+var (
+	ErrUnknownHashFormat = errors.New("unknown password hash format")
+	ErrMalformedHash     = errors.New("malformed password hash")
+)
+
+// Hasher 定義密碼哈希算法的接口
+type Hasher interface {
+	// Hash 對密碼進行哈希，返回自描述格式的編碼字符串
+	Hash(password string) (string, error)
+	// Verify 檢查密碼是否與編碼的哈希匹配
+	Verify(encoded, password string) (bool, error)
+	// NeedsRehash 檢查哈希是否使用了過時的參數，需要重新哈希
+	NeedsRehash(encoded string) bool
+	// Supports 檢查該算法能否處理給定格式的哈希
+	Supports(encoded string) bool
+}
+
+// PasswordHasher 使用首選算法生成新哈希，並能驗證所有受支持格式的舊哈希
+type PasswordHasher struct {
+	preferred Hasher
+	verifiers []Hasher
+}
+
+// NewPasswordHasher 創建 PasswordHasher，legacy 中的算法僅用於驗證
+func NewPasswordHasher(preferred Hasher, legacy ...Hasher) *PasswordHasher {
+	return &PasswordHasher{preferred: preferred, verifiers: append([]Hasher{preferred}, legacy...)}
+}
+
+// Hash 使用首選算法對密碼進行哈希
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+// Verify 根據哈希的格式選擇算法進行驗證
+func (h *PasswordHasher) Verify(encoded, password string) (bool, error) {
+	for _, v := range h.verifiers {
+		if v.Supports(encoded) {
+			return v.Verify(encoded, password)
+		}
+	}
+	return false, ErrUnknownHashFormat
+}
+
+// NeedsRehash 當哈希不是首選算法或參數已過時時返回 true
+func (h *PasswordHasher) NeedsRehash(encoded string) bool {
+	if !h.preferred.Supports(encoded) {
+		return true
+	}
+	return h.preferred.NeedsRehash(encoded)
+}
+
+// Supports 檢查是否有任一算法支持給定的哈希格式
+func (h *PasswordHasher) Supports(encoded string) bool {
+	for _, v := range h.verifiers {
+		if v.Supports(encoded) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsHashedPassword 檢查給定的字節序列是否為受支持格式的密碼哈希
+func IsHashedPassword(data []byte) bool {
+	return isBcryptHash(data) || strings.HasPrefix(string(data), argon2idPrefix)
+}
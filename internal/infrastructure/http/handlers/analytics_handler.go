@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	appAnalytics "blog-api/internal/application/analytics"
+	"blog-api/internal/infrastructure/http/middlewares"
+	"blog-api/internal/infrastructure/http/problem"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// analyticsQuery 是文章瀏覽統計的查詢參數，Days 為 0 時使用默認天數
+type analyticsQuery struct {
+	Days int `form:"days" binding:"omitempty,min=1,max=365"`
+}
+
+type AnalyticsHandler struct {
+	analyticsService *appAnalytics.Service
+}
+
+func NewAnalyticsHandler(analyticsService *appAnalytics.Service) *AnalyticsHandler {
+	return &AnalyticsHandler{analyticsService: analyticsService}
+}
+
+// GetPostAnalytics 返回文章的瀏覽統計
+// @Summary 獲取文章瀏覽統計
+// @Description 返回文章最近若干天的每日瀏覽次數和主要來源，只有作者可以查看。統計數據會延遲數秒寫入
+// @Tags posts
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param days query int false "統計天數，最多365天" default(30)
+// @Security BearerAuth
+// @Success 200 {object} analytics.PostStats
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /posts/{id}/analytics [get]
+func (h *AnalyticsHandler) GetPostAnalytics(c *gin.Context) {
+	var path idPath
+	if !bindPath(c, &path) {
+		return
+	}
+	var query analyticsQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+
+	stats, err := h.analyticsService.GetPostStats(c.Request.Context(), path.ID, userID, query.Days)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"blog-api/internal/application/avatar"
+	"blog-api/internal/infrastructure/http/middlewares"
+	"blog-api/internal/infrastructure/http/problem"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxAvatarUploadSize 頭像上傳的最大文件大小
+	maxAvatarUploadSize = 5 << 20
+	// immutableCacheControl 內容哈希 URL 的內容永不改變，可以長期緩存
+	immutableCacheControl = "public, max-age=31536000, immutable"
+)
+
+// AvatarHandler 處理與頭像相關的 HTTP 請求
+type AvatarHandler struct {
+	avatarService *avatar.Service
+}
+
+// NewAvatarHandler 創建一個新的 AvatarHandler 實例
+func NewAvatarHandler(avatarService *avatar.Service) *AvatarHandler {
+	return &AvatarHandler{avatarService: avatarService}
+}
+
+// UploadAvatar 上傳頭像
+// @Summary 上傳頭像
+// @Description 上傳 PNG、JPEG 或 GIF 圖片作為頭像，圖片會被居中裁剪為正方形並生成多個尺寸
+// @Tags user
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param avatar formData file true "頭像圖片（最大 5 MB）"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /profile/avatar [put]
+func (h *AvatarHandler) UploadAvatar(c *gin.Context) {
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAvatarUploadSize)
+	file, err := c.FormFile("avatar")
+	if err != nil {
+		problem.Write(c, problem.New(http.StatusBadRequest, "avatar_required", "an avatar image of at most 5 MB is required").
+			WithFields(problem.FieldError{Field: "avatar", Code: "required", Message: "avatar is required"}))
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		problem.Write(c, problem.New(http.StatusBadRequest, "avatar_required", "failed to read the uploaded avatar"))
+		return
+	}
+	defer f.Close()
+
+	url, err := h.avatarService.Upload(c.Request.Context(), userID, f)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"avatarUrl": url})
+}
+
+// DeleteAvatar 移除頭像
+// @Summary 移除頭像
+// @Description 移除上傳的頭像，之後使用根據用戶名生成的默認頭像
+// @Tags user
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /profile/avatar [delete]
+func (h *AvatarHandler) DeleteAvatar(c *gin.Context) {
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+
+	if err := h.avatarService.Remove(c.Request.Context(), userID); err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetUploadedAvatar 返回上傳的頭像文件
+func (h *AvatarHandler) GetUploadedAvatar(c *gin.Context) {
+	data, err := h.avatarService.Uploaded(c.Param("file"))
+	if err != nil {
+		respondAvatarNotFound(c)
+		return
+	}
+	servePNG(c, data)
+}
+
+// GetIdenticon 返回生成的默認頭像
+func (h *AvatarHandler) GetIdenticon(c *gin.Context) {
+	data, err := h.avatarService.Identicon(c.Param("file"))
+	if err != nil {
+		respondAvatarNotFound(c)
+		return
+	}
+	servePNG(c, data)
+}
+
+// respondAvatarNotFound 在頭像文件名無效或文件不存在時返回 404
+func respondAvatarNotFound(c *gin.Context) {
+	problem.Write(c, problem.New(http.StatusNotFound, "avatar_not_found", avatar.ErrNotFound.Error()))
+}
+
+// servePNG 以長期緩存頭返回 PNG 圖片，文件名即內容標識，因此直接作為 ETag
+func servePNG(c *gin.Context, data []byte) {
+	etag := `"` + c.Param("file") + `"`
+	c.Header("Cache-Control", immutableCacheControl)
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "image/png", data)
+}
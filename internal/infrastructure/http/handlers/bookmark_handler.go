@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	appBookmark "blog-api/internal/application/bookmark"
+	"blog-api/internal/domain/bookmark"
+	"blog-api/internal/infrastructure/http/middlewares"
+	"blog-api/internal/infrastructure/http/problem"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BookmarkInput 用於接收收藏的可選信息
+type BookmarkInput struct {
+	Folder string `json:"folder" example:"to-read"`
+	Note   string `json:"note" example:"Read this before the meeting"`
+}
+
+// BookmarkListResponse 是收藏列表的分頁響應
+type BookmarkListResponse struct {
+	Bookmarks  []bookmark.Bookmark `json:"bookmarks"`
+	NextCursor string              `json:"nextCursor,omitempty"` // 為空表示沒有更多收藏
+}
+
+// defaultBookmarkLimit 收藏列表每頁的默認數量
+const defaultBookmarkLimit = 20
+
+// bookmarkQuery 是收藏列表的查詢參數，Folder 為 nil 時不按收藏夾過濾
+type bookmarkQuery struct {
+	cursorQuery
+	Folder *string `form:"folder" binding:"omitempty,max=100"`
+}
+
+type BookmarkHandler struct {
+	bookmarkService *appBookmark.Service
+}
+
+func NewBookmarkHandler(bookmarkService *appBookmark.Service) *BookmarkHandler {
+	return &BookmarkHandler{bookmarkService: bookmarkService}
+}
+
+// SaveBookmark 收藏文章
+// @Summary 收藏文章
+// @Description 將文章加入當前用戶的收藏，可指定收藏夾和備註；已收藏時更新收藏夾和備註
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param bookmark body BookmarkInput false "收藏夾和備註"
+// @Security BearerAuth
+// @Success 200 {object} bookmark.Bookmark
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Router /posts/{id}/bookmark [put]
+func (h *BookmarkHandler) SaveBookmark(c *gin.Context) {
+	var input BookmarkInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			problem.Error(c, err)
+			return
+		}
+	}
+
+	var path idPath
+	if !bindPath(c, &path) {
+		return
+	}
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+	b, err := h.bookmarkService.Save(c.Request.Context(), userID, path.ID, appBookmark.SaveInput{Folder: input.Folder, Note: input.Note})
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, b)
+}
+
+// DeleteBookmark 取消收藏文章
+// @Summary 取消收藏文章
+// @Description 將文章從當前用戶的收藏中移除
+// @Tags bookmarks
+// @Param id path int true "文章ID"
+// @Security BearerAuth
+// @Success 204
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /posts/{id}/bookmark [delete]
+func (h *BookmarkHandler) DeleteBookmark(c *gin.Context) {
+	var path idPath
+	if !bindPath(c, &path) {
+		return
+	}
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+	if err := h.bookmarkService.Remove(c.Request.Context(), userID, path.ID); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListBookmarks 返回當前用戶的收藏列表
+// @Summary 獲取收藏列表
+// @Description 返回當前用戶的收藏，按收藏時間倒序，使用游標分頁，可按收藏夾過濾
+// @Tags bookmarks
+// @Produce json
+// @Param folder query string false "收藏夾名稱，空字符串表示未分類"
+// @Param cursor query string false "上一頁返回的 nextCursor"
+// @Param limit query int false "每頁數量，1 到 100" default(20)
+// @Security BearerAuth
+// @Success 200 {object} BookmarkListResponse
+// @Failure 400 {object} problem.Problem
+// @Router /bookmarks [get]
+func (h *BookmarkHandler) ListBookmarks(c *gin.Context) {
+	var query bookmarkQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	beforeID, limit, ok := query.resolve(c, defaultBookmarkLimit)
+	if !ok {
+		return
+	}
+
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+	// 多取一條用於判斷是否還有下一頁
+	bookmarks, err := h.bookmarkService.List(c.Request.Context(), userID, query.Folder, beforeID, limit+1)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	resp := BookmarkListResponse{Bookmarks: bookmarks}
+	if len(bookmarks) > limit {
+		resp.Bookmarks = bookmarks[:limit]
+		resp.NextCursor = encodeCursor(resp.Bookmarks[limit-1].ID)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListBookmarkFolders 返回當前用戶的收藏夾
+// @Summary 獲取收藏夾列表
+// @Description 返回當前用戶的收藏夾及每個收藏夾中的收藏數量
+// @Tags bookmarks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} bookmark.Folder
+// @Router /bookmarks/folders [get]
+func (h *BookmarkHandler) ListBookmarkFolders(c *gin.Context) {
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+	folders, err := h.bookmarkService.Folders(c.Request.Context(), userID)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, folders)
+}
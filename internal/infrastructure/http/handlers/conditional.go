@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// notModified 根據 If-None-Match 或 If-Modified-Since 判斷客戶端的緩存是否仍然有效
+// 兩者同時存在時以 If-None-Match 為準，ETag 使用弱比較
+func notModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	if inm := c.GetHeader("If-None-Match"); inm != "" {
+		etag = strings.TrimPrefix(etag, "W/")
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := c.GetHeader("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ims)
+		return err == nil && !lastModified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// versionETag 返回資源版本的弱 ETag
+// 響應體還包含反應計數、is_bookmarked 等不改變版本的字段，同一版本的表示並不逐字節相同，因此不能使用強 ETag
+func versionETag(version uint) string {
+	return fmt.Sprintf(`W/"%d"`, version)
+}
+
+// ifMatchVersion 從 If-Match 中解析客戶端讀取時的版本，ok 為 false 表示請求沒有攜帶 If-Match
+// 版本 ETag 都是弱 ETag，因此接受 W/ 前綴，按版本號而不是表示比較；
+// 無法識別的值解析為 0，不會與任何版本匹配；"*" 不表示具體的版本，視為沒有攜帶
+func ifMatchVersion(c *gin.Context) (version uint, ok bool) {
+	im := strings.TrimSpace(c.GetHeader("If-Match"))
+	if im == "" || im == "*" {
+		return 0, false
+	}
+	im = strings.TrimPrefix(im, "W/")
+	n, err := strconv.ParseUint(strings.Trim(im, `"`), 10, 32)
+	if err != nil || !strings.HasPrefix(im, `"`) {
+		return 0, true
+	}
+	return uint(n), true
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		header  string
+		version uint
+		ok      bool
+	}{
+		{"", 0, false},
+		{"*", 0, false},
+		{`W/"3"`, 3, true},
+		{`"3"`, 3, true},
+		{` W/"12" `, 12, true},
+		{`3`, 0, true},
+		{`"abc"`, 0, true},
+		{`W/"99999999999"`, 0, true},
+	}
+	for _, tt := range tests {
+		c, _ := paramsContext("/", nil)
+		if tt.header != "" {
+			c.Request.Header.Set("If-Match", tt.header)
+		}
+		version, ok := ifMatchVersion(c)
+		if version != tt.version || ok != tt.ok {
+			t.Errorf("ifMatchVersion(%q) = %d, %v; want %d, %v", tt.header, version, ok, tt.version, tt.ok)
+		}
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	lastModified := time.Date(2024, 10, 20, 14, 0, 0, 500, time.UTC)
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    bool
+	}{
+		{"no validators", nil, false},
+		{"same weak etag", map[string]string{"If-None-Match": `W/"3"`}, true},
+		{"strong form of the weak etag", map[string]string{"If-None-Match": `"3"`}, true},
+		{"one of several", map[string]string{"If-None-Match": `"1", W/"3"`}, true},
+		{"wildcard", map[string]string{"If-None-Match": "*"}, true},
+		{"different etag", map[string]string{"If-None-Match": `W/"4"`}, false},
+		{"etag takes precedence", map[string]string{"If-None-Match": `W/"4"`, "If-Modified-Since": lastModified.Format(http.TimeFormat)}, false},
+		{"not modified since", map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)}, true},
+		{"modified since", map[string]string{"If-Modified-Since": lastModified.Add(-time.Second).Format(http.TimeFormat)}, false},
+		{"invalid date", map[string]string{"If-Modified-Since": "yesterday"}, false},
+	}
+	for _, tt := range tests {
+		c, _ := paramsContext("/", nil)
+		for k, v := range tt.headers {
+			c.Request.Header.Set(k, v)
+		}
+		if got := notModified(c, versionETag(3), lastModified); got != tt.want {
+			t.Errorf("%s: notModified = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+)
+
+// ErrInvalidCursor 表示分頁游標無法解析
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// encodeCursor 將最後一條記錄的ID編碼為不透明的分頁游標
+func encodeCursor(id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(uint64(id), 10)))
+}
+
+// decodeCursor 解析分頁游標，空游標表示從第一頁開始
+func decodeCursor(cursor string) (uint, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseUint(string(raw), 10, 32)
+	if err != nil || id == 0 {
+		return 0, ErrInvalidCursor
+	}
+	return uint(id), nil
+}
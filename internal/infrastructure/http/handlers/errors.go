@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"blog-api/internal/infrastructure/http/problem"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondUnauthorized 在上下文中沒有已認證的用戶時返回 401
+func respondUnauthorized(c *gin.Context) {
+	problem.Write(c, problem.New(http.StatusUnauthorized, "authorization_required", "authentication is required"))
+}
+
+// respondInvalidParam 在路徑或查詢參數無法解析時返回 400，並指出出錯的參數
+func respondInvalidParam(c *gin.Context, param, detail string) {
+	problem.Write(c, problem.InvalidParams(problem.FieldError{Field: param, Code: "invalid", Message: detail}))
+}
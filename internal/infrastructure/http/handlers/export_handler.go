@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	appExport "blog-api/internal/application/export"
+	"blog-api/internal/domain/export"
+	"blog-api/internal/infrastructure/http/middlewares"
+	"blog-api/internal/infrastructure/http/problem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHandler 處理個人數據導出相關的 HTTP 請求
+type ExportHandler struct {
+	exportService *appExport.Service
+}
+
+// NewExportHandler 創建一個新的 ExportHandler 實例
+func NewExportHandler(exportService *appExport.Service) *ExportHandler {
+	return &ExportHandler{exportService: exportService}
+}
+
+// ExportStatusResponse 導出任務的狀態，完成後包含有時效的下載鏈接
+type ExportStatusResponse struct {
+	*export.Job
+	DownloadURL string `json:"downloadUrl,omitempty" example:"/api/v1/exports/1/download?expires=1729436400&signature=3b1f..."`
+}
+
+// downloadQuery 是簽名下載鏈接的查詢參數
+type downloadQuery struct {
+	Expires   int64  `form:"expires" binding:"required"`
+	Signature string `form:"signature" binding:"required"`
+}
+
+// RequestExport 請求導出個人數據
+// @Summary 請求導出個人數據
+// @Description 為當前用戶創建異步的個人數據導出任務（資料、文章、會話等），完成後可通過狀態接口獲取下載鏈接
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} ExportStatusResponse
+// @Failure 401 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Router /account/export [post]
+func (h *ExportHandler) RequestExport(c *gin.Context) {
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+
+	job, err := h.exportService.Request(c.Request.Context(), userID)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("/api/v1/account/export/%d", job.ID))
+	c.JSON(http.StatusAccepted, ExportStatusResponse{Job: job})
+}
+
+// GetExport 獲取導出任務狀態
+// @Summary 獲取導出任務狀態
+// @Description 返回導出任務的狀態，任務完成後包含有時效的下載鏈接
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "任務ID"
+// @Success 200 {object} ExportStatusResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /account/export/{id} [get]
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	var path idPath
+	if !bindPath(c, &path) {
+		return
+	}
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+
+	job, err := h.exportService.GetJob(c.Request.Context(), userID, path.ID)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	resp := ExportStatusResponse{Job: job}
+	if link, err := h.exportService.SignDownload(job); err == nil {
+		query := url.Values{}
+		query.Set("expires", strconv.FormatInt(link.Expires, 10))
+		query.Set("signature", link.Signature)
+		resp.DownloadURL = fmt.Sprintf("/api/v1/exports/%d/download?%s", job.ID, query.Encode())
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DownloadExport 下載導出檔案
+// @Summary 下載導出檔案
+// @Description 使用狀態接口返回的簽名鏈接下載導出檔案，鏈接過期後需要重新獲取
+// @Tags user
+// @Produce application/zip
+// @Param id path int true "任務ID"
+// @Param expires query int true "鏈接過期時間（Unix 秒）"
+// @Param signature query string true "鏈接簽名"
+// @Success 200 {file} file
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 410 {object} problem.Problem
+// @Router /exports/{id}/download [get]
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	var path idPath
+	if !bindPath(c, &path) {
+		return
+	}
+	var query downloadQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	file, err := h.exportService.OpenDownload(c.Request.Context(), path.ID, query.Expires, query.Signature)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.FileAttachment(file, fmt.Sprintf("blog-export-%d.zip", path.ID))
+}
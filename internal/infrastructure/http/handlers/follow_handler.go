@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	appFollow "blog-api/internal/application/follow"
+	"blog-api/internal/domain/user"
+	"blog-api/internal/infrastructure/http/middlewares"
+	"blog-api/internal/infrastructure/http/problem"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FollowHandler struct {
+	followService *appFollow.Service
+}
+
+func NewFollowHandler(followService *appFollow.Service) *FollowHandler {
+	return &FollowHandler{followService: followService}
+}
+
+// Follow 關注作者
+// @Summary 關注作者
+// @Description 關注指定的作者，重複關注不會報錯
+// @Tags users
+// @Param username path string true "作者用戶名"
+// @Security BearerAuth
+// @Success 204
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Router /users/{username}/follow [post]
+func (h *FollowHandler) Follow(c *gin.Context) {
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+	if err := h.followService.Follow(c.Request.Context(), userID, c.Param("username")); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Unfollow 取消關注作者
+// @Summary 取消關注作者
+// @Description 取消關注指定的作者，未關注時不會報錯
+// @Tags users
+// @Param username path string true "作者用戶名"
+// @Security BearerAuth
+// @Success 204
+// @Failure 404 {object} problem.Problem
+// @Router /users/{username}/follow [delete]
+func (h *FollowHandler) Unfollow(c *gin.Context) {
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+	if err := h.followService.Unfollow(c.Request.Context(), userID, c.Param("username")); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetFollowers 返回關注指定作者的用戶列表
+// @Summary 獲取關注者列表
+// @Description 返回關注指定作者的分頁用戶列表，每頁20個
+// @Tags users
+// @Produce json
+// @Param username path string true "作者用戶名"
+// @Param page query int false "頁碼" default(1)
+// @Success 200 {array} user.PublicProfile
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /users/{username}/followers [get]
+func (h *FollowHandler) GetFollowers(c *gin.Context) {
+	var query pageQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	h.respondProfiles(c, func() ([]user.PublicProfile, error) {
+		return h.followService.GetFollowers(c.Request.Context(), c.Param("username"), query.Page)
+	})
+}
+
+// GetFollowing 返回指定作者關注的用戶列表
+// @Summary 獲取關注中列表
+// @Description 返回指定作者關注的分頁用戶列表，每頁20個
+// @Tags users
+// @Produce json
+// @Param username path string true "作者用戶名"
+// @Param page query int false "頁碼" default(1)
+// @Success 200 {array} user.PublicProfile
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /users/{username}/following [get]
+func (h *FollowHandler) GetFollowing(c *gin.Context) {
+	var query pageQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	h.respondProfiles(c, func() ([]user.PublicProfile, error) {
+		return h.followService.GetFollowing(c.Request.Context(), c.Param("username"), query.Page)
+	})
+}
+
+// respondProfiles 返回用戶公開資料列表
+func (h *FollowHandler) respondProfiles(c *gin.Context, fetch func() ([]user.PublicProfile, error)) {
+	profiles, err := fetch()
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, profiles)
+}
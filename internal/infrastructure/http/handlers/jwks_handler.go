@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"blog-api/internal/infrastructure/auth"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSHandler 公開 JWT 驗證公鑰，供其他服務驗證令牌
+type JWKSHandler struct {
+	jwtService *auth.JWTService
+}
+
+// NewJWKSHandler 創建一個新的 JWKSHandler 實例
+func NewJWKSHandler(jwtService *auth.JWTService) *JWKSHandler {
+	return &JWKSHandler{jwtService: jwtService}
+}
+
+// GetJWKS 返回 JSON Web Key Set
+// @Summary 獲取 JWT 公鑰
+// @Description 返回當前用於驗證 JWT 的非對稱公鑰（JWKS 格式），不包含對稱密鑰
+// @Tags auth
+// @Produce json
+// @Success 200 {object} auth.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	// 允許短時間緩存，輪換密鑰後其他服務能在數分鐘內獲取新公鑰
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwtService.JWKS())
+}
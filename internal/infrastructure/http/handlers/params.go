@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"blog-api/internal/infrastructure/http/problem"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// idPath 是以數字 ID 標識資源的路徑參數
+type idPath struct {
+	ID uint `uri:"id" binding:"min=1,max=2147483647"`
+}
+
+// reactionPath 是文章回應的路徑參數
+type reactionPath struct {
+	idPath
+	Type string `uri:"type" binding:"required"`
+}
+
+// pageQuery 是頁碼分頁的查詢參數
+type pageQuery struct {
+	Page int `form:"page,default=1" binding:"min=1,max=100000"`
+}
+
+// cursorQuery 是游標分頁的查詢參數，Limit 為 0 時使用默認值
+type cursorQuery struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// resolve 解析游標並返回起始 ID 和每頁數量，游標無效時寫入 400 響應並返回 false
+func (q cursorQuery) resolve(c *gin.Context, defaultLimit int) (uint, int, bool) {
+	beforeID, err := decodeCursor(q.Cursor)
+	if err != nil {
+		respondInvalidParam(c, "cursor", "cursor is invalid")
+		return 0, 0, false
+	}
+	if q.Limit == 0 {
+		return beforeID, defaultLimit, true
+	}
+	return beforeID, q.Limit, true
+}
+
+// bindPath 綁定並驗證路徑參數，失敗時寫入 400 響應並返回 false
+// 路徑參數和查詢參數綁定到不同的結構體，避免查詢字符串覆蓋路徑中的 ID
+func bindPath(c *gin.Context, obj interface{}) bool {
+	return checkParams(c, obj, "uri", c.ShouldBindUri(obj))
+}
+
+// bindQuery 綁定並驗證查詢參數，失敗時寫入 400 響應並返回 false
+func bindQuery(c *gin.Context, obj interface{}) bool {
+	return checkParams(c, obj, "form", c.ShouldBindQuery(obj))
+}
+
+// checkParams 將參數綁定錯誤轉換為指出具體參數的 400 響應
+func checkParams(c *gin.Context, obj interface{}, tag string, err error) bool {
+	if err == nil {
+		return true
+	}
+
+	var numErr *strconv.NumError
+	var validationErrs validator.ValidationErrors
+	switch {
+	case errors.As(err, &numErr):
+		name := numericParam(c, reflect.TypeOf(obj), tag, numErr.Num)
+		message := name + " must be a whole number"
+		if errors.Is(numErr.Err, strconv.ErrRange) {
+			message = name + " is out of range"
+		}
+		problem.Write(c, problem.InvalidParams(problem.FieldError{Field: name, Code: "type", Message: message}))
+	case errors.As(err, &validationErrs):
+		problem.Write(c, problem.InvalidParams(problem.ValidationFields(validationErrs)...))
+	default:
+		problem.Error(c, err)
+	}
+	return false
+}
+
+// numericParam 找出值為 raw 且無法解析為數字的參數名
+// Gin 的數字解析錯誤不包含參數名，因此根據結構體中數值字段的標籤查找
+func numericParam(c *gin.Context, t reflect.Type, tag, raw string) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if name := numericParam(c, f.Type, tag, raw); name != "" {
+				return name
+			}
+			continue
+		}
+		name := strings.SplitN(f.Tag.Get(tag), ",", 2)[0]
+		if name == "" || !isNumericKind(f.Type.Kind()) {
+			continue
+		}
+		value := c.Param(name)
+		if tag == "form" {
+			value = c.Query(name)
+		}
+		if value == raw {
+			return name
+		}
+	}
+	return ""
+}
+
+// isNumericKind 判斷字段是否為整數類型
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"blog-api/internal/infrastructure/http/problem"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// paramsContext 創建帶有路徑參數和查詢字符串的測試上下文
+func paramsContext(target string, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	problem.UseRequestFieldNames()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	c.Params = params
+	return c, w
+}
+
+// fieldErrors 解析 400 響應中的字段錯誤
+func fieldErrors(t *testing.T, w *httptest.ResponseRecorder) []problem.FieldError {
+	t.Helper()
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	var p problem.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if p.Code != "invalid_parameter" {
+		t.Errorf("code = %q, want invalid_parameter", p.Code)
+	}
+	return p.Errors
+}
+
+func TestBindPath(t *testing.T) {
+	c, _ := paramsContext("/posts/42", gin.Params{{Key: "id", Value: "42"}})
+	var path idPath
+	if !bindPath(c, &path) || path.ID != 42 {
+		t.Fatalf("bindPath = %+v, want ID 42", path)
+	}
+
+	tests := []struct {
+		value string
+		code  string
+		msg   string
+	}{
+		{"abc", "type", "id must be a whole number"},
+		{"-1", "type", "id must be a whole number"},
+		{"99999999999999999999", "type", "id is out of range"},
+		{"0", "min", ""},
+		{"2147483648", "max", ""},
+	}
+	for _, tt := range tests {
+		c, w := paramsContext("/posts/"+tt.value, gin.Params{{Key: "id", Value: tt.value}})
+		var path idPath
+		if bindPath(c, &path) {
+			t.Errorf("bindPath(%q) succeeded", tt.value)
+			continue
+		}
+		fields := fieldErrors(t, w)
+		if len(fields) != 1 || fields[0].Field != "id" || fields[0].Code != tt.code {
+			t.Errorf("bindPath(%q) errors = %+v, want %s error on id", tt.value, fields, tt.code)
+			continue
+		}
+		if tt.msg != "" && fields[0].Message != tt.msg {
+			t.Errorf("bindPath(%q) message = %q, want %q", tt.value, fields[0].Message, tt.msg)
+		}
+	}
+}
+
+func TestBindPathNamesEmbeddedParam(t *testing.T) {
+	c, w := paramsContext("/posts/x/reactions/like", gin.Params{{Key: "id", Value: "x"}, {Key: "type", Value: "like"}})
+	var path reactionPath
+	if bindPath(c, &path) {
+		t.Fatal("bindPath succeeded")
+	}
+	if fields := fieldErrors(t, w); len(fields) != 1 || fields[0].Field != "id" {
+		t.Errorf("errors = %+v, want a type error on id", fields)
+	}
+}
+
+func TestBindQuery(t *testing.T) {
+	c, _ := paramsContext("/posts", nil)
+	var page pageQuery
+	if !bindQuery(c, &page) || page.Page != 1 {
+		t.Fatalf("bindQuery = %+v, want default page 1", page)
+	}
+
+	tests := []struct {
+		target string
+		obj    interface{}
+		field  string
+		code   string
+	}{
+		{"/posts?page=abc", &pageQuery{}, "page", "type"},
+		{"/posts?page=0", &pageQuery{}, "page", "min"},
+		{"/posts?limit=two", &cursorQuery{}, "limit", "type"},
+		{"/posts?limit=101", &cursorQuery{}, "limit", "max"},
+	}
+	for _, tt := range tests {
+		c, w := paramsContext(tt.target, nil)
+		if bindQuery(c, tt.obj) {
+			t.Errorf("bindQuery(%s) succeeded", tt.target)
+			continue
+		}
+		if fields := fieldErrors(t, w); len(fields) != 1 || fields[0].Field != tt.field || fields[0].Code != tt.code {
+			t.Errorf("bindQuery(%s) errors = %+v, want %s error on %s", tt.target, fields, tt.code, tt.field)
+		}
+	}
+}
+
+func TestCursorQueryResolve(t *testing.T) {
+	c, _ := paramsContext("/feed", nil)
+	beforeID, limit, ok := cursorQuery{}.resolve(c, 20)
+	if !ok || beforeID != 0 || limit != 20 {
+		t.Errorf("resolve() = %d, %d, %v; want 0, 20, true", beforeID, limit, ok)
+	}
+
+	beforeID, limit, ok = cursorQuery{Cursor: encodeCursor(57), Limit: 5}.resolve(c, 20)
+	if !ok || beforeID != 57 || limit != 5 {
+		t.Errorf("resolve(cursor 57) = %d, %d, %v; want 57, 5, true", beforeID, limit, ok)
+	}
+
+	c, w := paramsContext("/feed?cursor=bad", nil)
+	if _, _, ok := (cursorQuery{Cursor: "!!"}).resolve(c, 20); ok {
+		t.Fatal("resolve accepted an invalid cursor")
+	}
+	if fields := fieldErrors(t, w); len(fields) != 1 || fields[0].Field != "cursor" {
+		t.Errorf("errors = %+v, want an error on cursor", fields)
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	for _, id := range []uint{1, 42, 4294967295} {
+		got, err := decodeCursor(encodeCursor(id))
+		if err != nil || got != id {
+			t.Errorf("decodeCursor(encodeCursor(%d)) = %d, %v", id, got, err)
+		}
+	}
+	if got, err := decodeCursor(""); err != nil || got != 0 {
+		t.Errorf("decodeCursor(\"\") = %d, %v; want 0, nil", got, err)
+	}
+	for _, cursor := range []string{"!!", encodeCursor(0), "YWJj", "NDI5NDk2NzI5Ng"} {
+		if _, err := decodeCursor(cursor); err != ErrInvalidCursor {
+			t.Errorf("decodeCursor(%q) error = %v, want %v", cursor, err, ErrInvalidCursor)
+		}
+	}
+}
+
+func TestHandlersRequireAuthenticatedUser(t *testing.T) {
+	handlers := map[string]gin.HandlerFunc{
+		"GetProfile":          (&UserHandler{}).GetProfile,
+		"ListBookmarkFolders": (&BookmarkHandler{}).ListBookmarkFolders,
+		"ListTokens":          (&TokenHandler{}).ListTokens,
+	}
+	for name, handler := range handlers {
+		c, w := paramsContext("/api/v1/me", nil)
+		handler(c)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s without a user = %d, want 401", name, w.Code)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	appPost "blog-api/internal/application/post"
+	"blog-api/internal/domain/post"
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+// PATCH 請求支持的補丁格式
+const (
+	mergePatchMediaType = "application/merge-patch+json" // RFC 7396
+	jsonPatchMediaType  = "application/json-patch+json"  // RFC 6902
+
+	// maxPatchBodySize 補丁文檔的最大大小
+	maxPatchBodySize = 1 << 20
+)
+
+// errUnsupportedPatch 表示請求的 Content-Type 不是支持的補丁格式
+var errUnsupportedPatch = errors.New("unsupported patch media type")
+
+// decodePatch 根據 Content-Type 解析補丁，application/json 按 JSON Merge Patch 處理
+// 返回的錯誤表示補丁文檔本身格式錯誤
+func decodePatch(contentType string, body []byte) (appPost.Patch, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, errUnsupportedPatch
+	}
+
+	switch mediaType {
+	case mergePatchMediaType, "application/json":
+		trimmed := bytes.TrimSpace(body)
+		if len(trimmed) == 0 || trimmed[0] != '{' {
+			return nil, errors.New("merge patch must be a JSON object")
+		}
+		return func(doc []byte) ([]byte, error) {
+			out, err := jsonpatch.MergePatch(doc, body)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", post.ErrInvalidPatch, err)
+			}
+			return out, nil
+		}, nil
+	case jsonPatchMediaType:
+		ops, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			return nil, err
+		}
+		return func(doc []byte) ([]byte, error) {
+			out, err := ops.Apply(doc)
+			if errors.Is(err, jsonpatch.ErrTestFailed) {
+				return nil, post.ErrPatchTestFailed
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", post.ErrInvalidPatch, err)
+			}
+			return out, nil
+		}, nil
+	}
+	return nil, errUnsupportedPatch
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"blog-api/internal/domain/post"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const patchTestDoc = `{"title":"Hello","content":"World","version":3}`
+
+func TestDecodePatchMergePatch(t *testing.T) {
+	for _, contentType := range []string{mergePatchMediaType, "application/json", mergePatchMediaType + "; charset=utf-8"} {
+		patch, err := decodePatch(contentType, []byte(`{"title":"Changed"}`))
+		if err != nil {
+			t.Fatalf("decodePatch(%q): %v", contentType, err)
+		}
+		out, err := patch([]byte(patchTestDoc))
+		if err != nil {
+			t.Fatalf("apply (%q): %v", contentType, err)
+		}
+		if want := `{"title":"Changed","content":"World","version":3}`; string(out) != want {
+			t.Errorf("apply (%q) = %s, want %s", contentType, out, want)
+		}
+	}
+}
+
+func TestDecodePatchMergePatchRequiresObject(t *testing.T) {
+	for _, body := range []string{``, `  `, `[]`, `"title"`, `null`} {
+		if _, err := decodePatch(mergePatchMediaType, []byte(body)); err == nil || errors.Is(err, errUnsupportedPatch) {
+			t.Errorf("decodePatch(%q) error = %v, want malformed document", body, err)
+		}
+	}
+}
+
+func TestDecodePatchJSONPatch(t *testing.T) {
+	patch, err := decodePatch(jsonPatchMediaType, []byte(`[
+		{"op":"test","path":"/version","value":3},
+		{"op":"replace","path":"/content","value":"Changed"}
+	]`))
+	if err != nil {
+		t.Fatalf("decodePatch: %v", err)
+	}
+	out, err := patch([]byte(patchTestDoc))
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if want := `{"title":"Hello","content":"Changed","version":3}`; string(out) != want {
+		t.Errorf("apply = %s, want %s", out, want)
+	}
+}
+
+func TestDecodePatchJSONPatchErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{"test failed", `[{"op":"test","path":"/version","value":2}]`, post.ErrPatchTestFailed},
+		{"missing path", `[{"op":"remove","path":"/author"}]`, post.ErrInvalidPatch},
+		{"bad index", `[{"op":"add","path":"/title/0","value":"x"}]`, post.ErrInvalidPatch},
+	}
+	for _, tt := range tests {
+		patch, err := decodePatch(jsonPatchMediaType, []byte(tt.body))
+		if err != nil {
+			t.Fatalf("%s: decodePatch: %v", tt.name, err)
+		}
+		if _, err := patch([]byte(patchTestDoc)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: apply error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	for _, body := range []string{`{"op":"add"}`, `[{"op":"rename","path":"/title"}]`} {
+		if _, err := decodePatch(jsonPatchMediaType, []byte(body)); err == nil || errors.Is(err, errUnsupportedPatch) {
+			t.Errorf("decodePatch(%s) error = %v, want malformed document", body, err)
+		}
+	}
+}
+
+func TestDecodePatchUnsupportedMediaType(t *testing.T) {
+	for _, contentType := range []string{"", "text/plain", "application/xml", ";"} {
+		if _, err := decodePatch(contentType, []byte(`{}`)); !errors.Is(err, errUnsupportedPatch) {
+			t.Errorf("decodePatch(%q) error = %v, want %v", contentType, err, errUnsupportedPatch)
+		}
+	}
+}
+
+// patchPostRequest 在未經路由的情況下調用 PatchPost，處理器在這些用例中不會觸及文章服務
+func patchPostRequest(t *testing.T, ifMatch, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/posts/1", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", mergePatchMediaType)
+	if ifMatch != "" {
+		c.Request.Header.Set("If-Match", ifMatch)
+	}
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	(&PostHandler{}).PatchPost(c)
+	return w
+}
+
+func TestPatchPostRequiresIfMatch(t *testing.T) {
+	w := patchPostRequest(t, "", `{"title":"Changed"}`)
+	if w.Code != http.StatusPreconditionRequired || !strings.Contains(w.Body.String(), "version_required") {
+		t.Errorf("response = %d %s, want 428 version_required", w.Code, w.Body)
+	}
+}
+
+func TestPatchPostRejectsLargeBody(t *testing.T) {
+	body := `{"content":"` + strings.Repeat("x", maxPatchBodySize) + `"}`
+	w := patchPostRequest(t, `W/"3"`, body)
+	if w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), "body_too_large") {
+		t.Errorf("response = %d, want 413 body_too_large", w.Code)
+	}
+}
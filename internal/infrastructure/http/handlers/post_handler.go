@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	appAnalytics "blog-api/internal/application/analytics"
 	appPost "blog-api/internal/application/post"
 	"blog-api/internal/domain/post"
 	"blog-api/internal/infrastructure/http/middlewares"
+	"blog-api/internal/infrastructure/http/problem"
+	"errors"
+	"io"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -16,49 +19,147 @@ import (
 type PostInput struct {
 	Title   string `json:"title" binding:"required" example:"My Blog Post"`
 	Content string `json:"content" binding:"required" example:"This is the content of my blog post."`
+	// Tags 是文章的標籤，更新時未提供則保留原有標籤，提供空數組則清除
+	Tags []string `json:"tags,omitempty" example:"go,databases"`
+	// Version 是客戶端讀取文章時的版本，更新時未使用 If-Match 請求頭則必須提供
+	Version *uint `json:"version,omitempty" example:"1"`
 }
 
+// FeedResponse 是個人動態的分頁響應
+type FeedResponse struct {
+	Posts      []post.Post `json:"posts"`
+	NextCursor string      `json:"nextCursor,omitempty"` // 為空表示沒有更多文章
+}
+
+// defaultFeedLimit 個人動態每頁的默認數量
+const defaultFeedLimit = 20
+
 type PostHandler struct {
-	postService *appPost.Service
+	postService  *appPost.Service
+	viewRecorder *appAnalytics.Recorder
 }
 
-func NewPostHandler(postService *appPost.Service) *PostHandler {
-	return &PostHandler{postService: postService}
+func NewPostHandler(postService *appPost.Service, viewRecorder *appAnalytics.Recorder) *PostHandler {
+	return &PostHandler{postService: postService, viewRecorder: viewRecorder}
 }
 
 // GetPosts 返回文章列表
 // @Summary 獲取文章列表
-// @Description 返回分頁的文章列表，每頁10篇
+// @Description 返回分頁的文章列表，每頁10篇；已認證時包含收藏狀態
 // @Tags posts
 // @Produce json
 // @Param page query int false "頁碼" default(1)
 // @Success 200 {array} post.Post
+// @Failure 400 {object} problem.Problem
 // @Router /posts [get]
 func (h *PostHandler) GetPosts(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	posts, err := h.postService.GetPosts(page)
+	var query pageQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	viewerID, _ := middlewares.GetOptionalUserID(c)
+	posts, err := h.postService.GetPosts(c.Request.Context(), query.Page, viewerID)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, posts)
+}
+
+// GetAuthorPosts 返回指定作者的文章列表
+// @Summary 獲取作者的文章列表
+// @Description 返回指定作者分頁的文章列表，每頁10篇；已認證時包含收藏狀態
+// @Tags users
+// @Produce json
+// @Param username path string true "作者用戶名"
+// @Param page query int false "頁碼" default(1)
+// @Success 200 {array} post.Post
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /users/{username}/posts [get]
+func (h *PostHandler) GetAuthorPosts(c *gin.Context) {
+	var query pageQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	viewerID, _ := middlewares.GetOptionalUserID(c)
+	posts, err := h.postService.GetPostsByAuthor(c.Request.Context(), c.Param("username"), query.Page, viewerID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve posts"})
+		problem.Error(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, posts)
 }
 
+// GetFeed 返回當前用戶關注作者的文章
+// @Summary 獲取個人動態
+// @Description 返回當前用戶關注的作者的文章，按發布時間倒序，使用游標分頁
+// @Tags posts
+// @Produce json
+// @Param cursor query string false "上一頁返回的 nextCursor"
+// @Param limit query int false "每頁數量，1 到 100" default(20)
+// @Security BearerAuth
+// @Success 200 {object} FeedResponse
+// @Failure 400 {object} problem.Problem
+// @Router /feed [get]
+func (h *PostHandler) GetFeed(c *gin.Context) {
+	var query cursorQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	beforeID, limit, ok := query.resolve(c, defaultFeedLimit)
+	if !ok {
+		return
+	}
+
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+	// 多取一篇用於判斷是否還有下一頁
+	posts, err := h.postService.GetFeed(c.Request.Context(), userID, beforeID, limit+1)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	resp := FeedResponse{Posts: posts}
+	if len(posts) > limit {
+		resp.Posts = posts[:limit]
+		resp.NextCursor = encodeCursor(resp.Posts[limit-1].ID)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
 // GetPost 返回單篇文章
 // @Summary 獲取文章詳情
-// @Description 根據ID返回單篇文章的詳細內容
+// @Description 根據ID返回單篇文章的詳細內容；已認證時包含收藏狀態
 // @Tags posts
 // @Produce json
 // @Param id path int true "文章ID"
 // @Success 200 {object} post.Post
+// @Header 200 {string} ETag "文章當前版本，更新時通過 If-Match 提交"
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
 // @Router /posts/{id} [get]
 func (h *PostHandler) GetPost(c *gin.Context) {
-	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
-	post, err := h.postService.GetPostByID(uint(id))
+	var path idPath
+	if !bindPath(c, &path) {
+		return
+	}
+	viewerID, _ := middlewares.GetOptionalUserID(c)
+	post, err := h.postService.GetPostByID(c.Request.Context(), path.ID, viewerID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		problem.Error(c, err)
 		return
 	}
+
+	// 作者瀏覽自己的文章不計入統計
+	if post.UserID != viewerID {
+		h.viewRecorder.Record(post.ID, c.ClientIP(), c.Request.UserAgent(), c.Request.Referer())
+	}
+	c.Header("ETag", versionETag(post.Version))
 	c.JSON(http.StatusOK, post)
 }
 
@@ -71,66 +172,187 @@ func (h *PostHandler) GetPost(c *gin.Context) {
 // @Param post body PostInput true "文章內容"
 // @Security BearerAuth
 // @Success 201 {object} post.Post
+// @Failure 400 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
 // @Router /posts [post]
 func (h *PostHandler) CreatePost(c *gin.Context) {
 	var input PostInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Error(c, err)
 		return
 	}
 
-	userID, _ := middlewares.GetUserID(c)
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
 	now := time.Now()
 	newPost := &post.Post{
 		Title:     input.Title,
 		Content:   input.Content,
+		Tags:      input.Tags,
 		UserID:    userID,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 
-	if err := h.postService.CreatePost(newPost); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create post"})
+	if err := h.postService.CreatePost(c.Request.Context(), newPost); err != nil {
+		problem.Error(c, err)
 		return
 	}
 
+	c.Header("ETag", versionETag(newPost.Version))
 	c.JSON(http.StatusCreated, newPost)
 }
 
 // UpdatePost 更新文章
 // @Summary 更新文章
-// @Description 更新現有文章，需要用戶登錄且為作者
+// @Description 更新現有文章，需要用戶登錄且為作者。必須通過 If-Match 請求頭（GetPost 返回的 ETag）或請求體中的 version 提供讀取時的版本；
+// @Description 文章在此之後已被修改時，If-Match 返回 412，version 返回 409，響應的 current 成員包含服務器上的當前版本
 // @Tags posts
 // @Accept json
 // @Produce json
 // @Param id path int true "文章ID"
+// @Param If-Match header string false "讀取文章時的 ETag"
 // @Param post body PostInput true "更新的文章內容"
 // @Security BearerAuth
 // @Success 200 {object} post.Post
+// @Header 200 {string} ETag "更新後的版本"
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 412 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 428 {object} problem.Problem
 // @Router /posts/{id} [put]
 func (h *PostHandler) UpdatePost(c *gin.Context) {
-	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	var path idPath
+	if !bindPath(c, &path) {
+		return
+	}
 	var input PostInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Error(c, err)
 		return
 	}
 
-	userID, _ := middlewares.GetUserID(c)
+	// If-Match 優先於請求體中的版本
+	version, fromHeader := ifMatchVersion(c)
+	if !fromHeader {
+		if input.Version == nil {
+			problem.Write(c, problem.New(http.StatusPreconditionRequired, "version_required", "provide the version being edited with If-Match or the version field").
+				WithFields(problem.FieldError{Field: "version", Code: "required", Message: "version is required without If-Match"}))
+			return
+		}
+		version = *input.Version
+	}
 
-	updatedPost := &post.Post{
-		ID:        uint(id),
-		Title:     input.Title,
-		Content:   input.Content,
-		UserID:    userID,
-		UpdatedAt: time.Now(),
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+
+	updatedPost, err := h.postService.UpdatePost(c.Request.Context(), &post.Post{
+		ID:      path.ID,
+		Title:   input.Title,
+		Content: input.Content,
+		Tags:    input.Tags,
+		UserID:  userID,
+		Version: version,
+	}, userID)
+	if err != nil {
+		var conflict *post.ConflictError
+		if errors.As(err, &conflict) {
+			respondPostConflict(c, err, conflict, fromHeader)
+			return
+		}
+		problem.Error(c, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(updatedPost.Version))
+	c.JSON(http.StatusOK, updatedPost)
+}
+
+// PatchPost 部分更新文章
+// @Summary 部分更新文章
+// @Description 使用 JSON Merge Patch（RFC 7396）或 JSON Patch（RFC 6902）修改文章，需要用戶登錄且為作者。補丁作用於
+// @Description {"title","content","tags","version"} 文檔，version 只讀，可用於 test 操作；驗證作用於合併後的結果。
+// @Description 必須通過 If-Match 提供讀取時的 ETag，版本不符返回 412，test 操作失敗返回 409，響應的 current 成員包含服務器上的當前版本。
+// @Description 補丁文檔最大 1 MB
+// @Tags posts
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param If-Match header string true "讀取文章時的 ETag"
+// @Param patch body appPost.PatchDocument true "補丁文檔（JSON Patch 時為操作數組）"
+// @Security BearerAuth
+// @Success 200 {object} post.Post
+// @Header 200 {string} ETag "更新後的版本"
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 412 {object} problem.Problem
+// @Failure 413 {object} problem.Problem
+// @Failure 415 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 428 {object} problem.Problem
+// @Router /posts/{id} [patch]
+func (h *PostHandler) PatchPost(c *gin.Context) {
+	var path idPath
+	if !bindPath(c, &path) {
+		return
+	}
+	// 補丁只能基於客戶端讀取過的版本，不接受盲寫
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		problem.Write(c, problem.New(http.StatusPreconditionRequired, "version_required", "provide the version being edited with If-Match"))
+		return
 	}
 
-	if err := h.postService.UpdatePost(updatedPost, userID); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxPatchBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			problem.Write(c, problem.New(http.StatusRequestEntityTooLarge, "body_too_large", "the patch document must be at most 1 MB"))
+			return
+		}
+		problem.Write(c, problem.New(http.StatusBadRequest, "malformed_body", "failed to read the request body"))
+		return
+	}
+	patch, err := decodePatch(c.ContentType(), body)
+	if err != nil {
+		if errors.Is(err, errUnsupportedPatch) {
+			c.Header("Accept-Patch", mergePatchMediaType+", "+jsonPatchMediaType)
+			problem.Write(c, problem.New(http.StatusUnsupportedMediaType, "unsupported_media_type", "Content-Type must be "+mergePatchMediaType+" or "+jsonPatchMediaType))
+			return
+		}
+		problem.Write(c, problem.New(http.StatusBadRequest, "malformed_body", "the patch document is not valid JSON"))
 		return
 	}
 
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+	updatedPost, err := h.postService.PatchPost(c.Request.Context(), path.ID, userID, version, patch)
+	if err != nil {
+		var conflict *post.ConflictError
+		if errors.As(err, &conflict) {
+			// 只有 If-Match 過時才返回 412，test 操作失敗返回 409
+			respondPostConflict(c, err, conflict, errors.Is(err, post.ErrVersionConflict))
+			return
+		}
+		problem.Error(c, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(updatedPost.Version))
 	c.JSON(http.StatusOK, updatedPost)
 }
 
@@ -142,15 +364,37 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 // @Param id path int true "文章ID"
 // @Security BearerAuth
 // @Success 204 "No Content"
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
 // @Router /posts/{id} [delete]
 func (h *PostHandler) DeletePost(c *gin.Context) {
-	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
-	userID, _ := middlewares.GetUserID(c)
+	var path idPath
+	if !bindPath(c, &path) {
+		return
+	}
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
 
-	if err := h.postService.DeletePost(uint(id), userID); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	if err := h.postService.DeletePost(c.Request.Context(), path.ID, userID); err != nil {
+		problem.Error(c, err)
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
+
+// respondPostConflict 返回衝突的問題詳情，current 成員包含服務器上的當前版本供客戶端合併
+// precondition 為 true 表示客戶端通過 If-Match 提供的版本已過時，此時返回 412
+func respondPostConflict(c *gin.Context, err error, conflict *post.ConflictError, precondition bool) {
+	p := problem.FromError(err)
+	if precondition {
+		p.Status = http.StatusPreconditionFailed
+		p.Title = http.StatusText(http.StatusPreconditionFailed)
+	}
+	c.Header("ETag", versionETag(conflict.Current.Version))
+	problem.Write(c, p)
+}
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	appPost "blog-api/internal/application/post"
+	"blog-api/internal/domain/bookmark"
+	"blog-api/internal/domain/post"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// feedPosts 是內存中的個人動態，FindFeed 按 ID 倒序返回早於 beforeID 的文章
+type feedPosts struct {
+	post.Repository
+	ids     []uint // 按 ID 倒序
+	limits  []int
+	befores []uint
+}
+
+func (r *feedPosts) FindFeed(_ context.Context, _ uint, beforeID uint, limit int) ([]post.Post, error) {
+	r.limits = append(r.limits, limit)
+	r.befores = append(r.befores, beforeID)
+	var posts []post.Post
+	for _, id := range r.ids {
+		if (beforeID == 0 || id < beforeID) && len(posts) < limit {
+			posts = append(posts, post.Post{ID: id, UserID: 2})
+		}
+	}
+	return posts, nil
+}
+
+type feedAuthors struct{ post.AuthorRepository }
+
+func (feedAuthors) FindByIDs(_ context.Context, ids []uint) (map[uint]post.Author, error) {
+	authors := make(map[uint]post.Author, len(ids))
+	for _, id := range ids {
+		authors[id] = post.Author{ID: id}
+	}
+	return authors, nil
+}
+
+type feedBookmarks struct{ bookmark.Repository }
+
+func (feedBookmarks) FindBookmarkedPostIDs(context.Context, uint, []uint) (map[uint]bool, error) {
+	return map[uint]bool{}, nil
+}
+
+// getFeed 以用戶 1 的身份請求個人動態
+func getFeed(t *testing.T, h *PostHandler, query string) FeedResponse {
+	t.Helper()
+	c, w := paramsContext("/api/v1/feed"+query, nil)
+	c.Set("userID", uint(1))
+	h.GetFeed(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GetFeed(%s) = %d %s", query, w.Code, w.Body)
+	}
+	var resp FeedResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return resp
+}
+
+func TestGetFeedPaginatesWithCursor(t *testing.T) {
+	repo := &feedPosts{ids: []uint{10, 9, 8, 7, 6, 5, 4, 3, 2, 1}}
+	h := NewPostHandler(appPost.NewService(repo, feedAuthors{}, feedBookmarks{}, nil), nil)
+
+	var pages [][]uint
+	query := "?limit=3"
+	for i := 0; i < 10; i++ {
+		resp := getFeed(t, h, query)
+		var ids []uint
+		for _, p := range resp.Posts {
+			if p.Author == nil || p.IsBookmarked == nil {
+				t.Errorf("post %d is missing the author or bookmark state", p.ID)
+			}
+			ids = append(ids, p.ID)
+		}
+		pages = append(pages, ids)
+		if resp.NextCursor == "" {
+			break
+		}
+		query = "?limit=3&cursor=" + resp.NextCursor
+	}
+
+	want := [][]uint{{10, 9, 8}, {7, 6, 5}, {4, 3, 2}, {1}}
+	if len(pages) != len(want) {
+		t.Fatalf("pages = %v, want %v", pages, want)
+	}
+	for i := range want {
+		if len(pages[i]) != len(want[i]) {
+			t.Fatalf("pages = %v, want %v", pages, want)
+		}
+		for j := range want[i] {
+			if pages[i][j] != want[i][j] {
+				t.Fatalf("pages = %v, want %v", pages, want)
+			}
+		}
+	}
+	// 每次多取一篇用於判斷是否還有下一頁
+	for _, limit := range repo.limits {
+		if limit != 4 {
+			t.Errorf("FindFeed limits = %v, want 4", repo.limits)
+			break
+		}
+	}
+	if repo.befores[0] != 0 || repo.befores[1] != 8 {
+		t.Errorf("FindFeed beforeIDs = %v, want 0 then 8", repo.befores)
+	}
+}
+
+func TestGetFeedDefaultLimit(t *testing.T) {
+	repo := &feedPosts{}
+	h := NewPostHandler(appPost.NewService(repo, feedAuthors{}, feedBookmarks{}, nil), nil)
+
+	resp := getFeed(t, h, "")
+	if len(resp.Posts) != 0 || resp.NextCursor != "" {
+		t.Errorf("empty feed = %+v", resp)
+	}
+	if len(repo.limits) != 1 || repo.limits[0] != defaultFeedLimit+1 {
+		t.Errorf("FindFeed limits = %v, want %d", repo.limits, defaultFeedLimit+1)
+	}
+}
+
+func TestGetFeedRejectsInvalidCursor(t *testing.T) {
+	repo := &feedPosts{}
+	h := NewPostHandler(appPost.NewService(repo, feedAuthors{}, feedBookmarks{}, nil), nil)
+
+	c, w := paramsContext("/api/v1/feed?cursor=AAAA", nil)
+	c.Set("userID", uint(1))
+	h.GetFeed(c)
+	if fields := fieldErrors(t, w); len(fields) != 1 || fields[0].Field != "cursor" {
+		t.Errorf("errors = %+v, want an error on cursor", fields)
+	}
+	if len(repo.limits) != 0 {
+		t.Error("GetFeed queried the repository with an invalid cursor")
+	}
+}
+
+// directTx 直接執行事務函數
+type directTx struct{}
+
+func (directTx) Do(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) }
+
+// versionedPosts 是內存中的單篇文章，Update 只在版本未變時保存
+type versionedPosts struct {
+	post.Repository
+	stored post.Post
+}
+
+func (r *versionedPosts) FindByID(_ context.Context, id uint) (*post.Post, error) {
+	if id != r.stored.ID {
+		return nil, post.ErrPostNotFound
+	}
+	p := r.stored
+	return &p, nil
+}
+
+func (r *versionedPosts) Update(_ context.Context, p *post.Post) error {
+	if r.stored.Version != p.Version {
+		return post.ErrVersionConflict
+	}
+	p.Version++
+	r.stored = *p
+	return nil
+}
+
+// writePost 以用戶 1 的身份修改文章 1
+func writePost(h *PostHandler, method, contentType, body string, headers map[string]string) *httptest.ResponseRecorder {
+	c, w := paramsContext("/api/v1/posts/1", gin.Params{{Key: "id", Value: "1"}})
+	c.Request = httptest.NewRequest(method, "/api/v1/posts/1", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		c.Request.Header.Set(k, v)
+	}
+	c.Set("userID", uint(1))
+	if method == http.MethodPatch {
+		h.PatchPost(c)
+	} else {
+		h.UpdatePost(c)
+	}
+	return w
+}
+
+func TestUpdatePostPreconditions(t *testing.T) {
+	const (
+		update = `{"title":"Edited","content":"Edited content"}`
+		merge  = "application/merge-patch+json"
+	)
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		ifMatch     string
+		status      int
+		code        string
+		etag        string
+	}{
+		{"weak If-Match", http.MethodPut, "application/json", update, `W/"3"`, http.StatusOK, "", `W/"4"`},
+		{"strong form of the weak ETag", http.MethodPut, "application/json", update, `"3"`, http.StatusOK, "", `W/"4"`},
+		{"version in body", http.MethodPut, "application/json", `{"title":"Edited","content":"Edited content","version":3}`, "", http.StatusOK, "", `W/"4"`},
+		{"If-Match takes precedence", http.MethodPut, "application/json", `{"title":"Edited","content":"Edited content","version":3}`, `W/"2"`, http.StatusPreconditionFailed, "version_conflict", `W/"3"`},
+		{"stale If-Match", http.MethodPut, "application/json", update, `W/"2"`, http.StatusPreconditionFailed, "version_conflict", `W/"3"`},
+		{"unparseable If-Match", http.MethodPut, "application/json", update, `W/3`, http.StatusPreconditionFailed, "version_conflict", `W/"3"`},
+		{"stale version in body", http.MethodPut, "application/json", `{"title":"Edited","content":"Edited content","version":2}`, "", http.StatusConflict, "version_conflict", `W/"3"`},
+		{"missing version", http.MethodPut, "application/json", update, "", http.StatusPreconditionRequired, "version_required", ""},
+		{"wildcard If-Match", http.MethodPut, "application/json", update, "*", http.StatusPreconditionRequired, "version_required", ""},
+		{"patch", http.MethodPatch, merge, `{"title":"Edited"}`, `W/"3"`, http.StatusOK, "", `W/"4"`},
+		{"patch with stale If-Match", http.MethodPatch, merge, `{"title":"Edited"}`, `W/"2"`, http.StatusPreconditionFailed, "version_conflict", `W/"3"`},
+		{"patch test failure", http.MethodPatch, "application/json-patch+json", `[{"op":"test","path":"/title","value":"Other"}]`, `W/"3"`, http.StatusConflict, "patch_test_failed", `W/"3"`},
+		{"patch without If-Match", http.MethodPatch, merge, `{"title":"Edited"}`, "", http.StatusPreconditionRequired, "version_required", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &versionedPosts{stored: post.Post{ID: 1, UserID: 1, Title: "Hello", Content: "Hello, world", Version: 3}}
+			h := NewPostHandler(appPost.NewService(repo, feedAuthors{}, feedBookmarks{}, directTx{}), nil)
+
+			headers := map[string]string{}
+			if tt.ifMatch != "" {
+				headers["If-Match"] = tt.ifMatch
+			}
+			w := writePost(h, tt.method, tt.contentType, tt.body, headers)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if got := w.Header().Get("ETag"); got != tt.etag {
+				t.Errorf("ETag = %q, want %q", got, tt.etag)
+			}
+			var body struct {
+				Code    string     `json:"code"`
+				Current *post.Post `json:"current"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if body.Code != tt.code {
+				t.Errorf("code = %q, want %q", body.Code, tt.code)
+			}
+			switch {
+			case tt.status == http.StatusOK:
+				if repo.stored.Title != "Edited" || repo.stored.Version != 4 {
+					t.Errorf("stored post = %+v", repo.stored)
+				}
+			case tt.code == "version_required":
+				if repo.stored.Version != 3 {
+					t.Error("post was updated without a version")
+				}
+			default:
+				if body.Current == nil || body.Current.Title != "Hello" || body.Current.Version != 3 {
+					t.Errorf("current = %+v, want the stored post", body.Current)
+				}
+				if repo.stored.Version != 3 {
+					t.Error("a conflicting update was saved")
+				}
+			}
+		})
+	}
+}
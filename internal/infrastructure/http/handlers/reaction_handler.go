@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	appReaction "blog-api/internal/application/reaction"
+	"blog-api/internal/domain/reaction"
+	"blog-api/internal/infrastructure/http/middlewares"
+	"blog-api/internal/infrastructure/http/problem"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reactorsQuery 是回應者列表的查詢參數，Type 為空時返回所有類型
+type reactorsQuery struct {
+	pageQuery
+	Type string `form:"type"`
+}
+
+type ReactionHandler struct {
+	reactionService *appReaction.Service
+}
+
+func NewReactionHandler(reactionService *appReaction.Service) *ReactionHandler {
+	return &ReactionHandler{reactionService: reactionService}
+}
+
+// React 為文章添加回應
+// @Summary 回應文章
+// @Description 為文章添加一種表情回應（like、love、laugh、wow、sad、celebrate），每種類型每個用戶最多一個
+// @Tags posts
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param type path string true "回應類型"
+// @Security BearerAuth
+// @Success 200 {object} post.ReactionCounts
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /posts/{id}/reactions/{type} [put]
+func (h *ReactionHandler) React(c *gin.Context) {
+	var path reactionPath
+	if !bindPath(c, &path) {
+		return
+	}
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+	counts, err := h.reactionService.React(c.Request.Context(), path.ID, userID, reaction.Type(path.Type))
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, counts)
+}
+
+// Unreact 取消文章的回應
+// @Summary 取消回應文章
+// @Description 取消當前用戶對文章的某種回應
+// @Tags posts
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param type path string true "回應類型"
+// @Security BearerAuth
+// @Success 200 {object} post.ReactionCounts
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /posts/{id}/reactions/{type} [delete]
+func (h *ReactionHandler) Unreact(c *gin.Context) {
+	var path reactionPath
+	if !bindPath(c, &path) {
+		return
+	}
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+	counts, err := h.reactionService.Unreact(c.Request.Context(), path.ID, userID, reaction.Type(path.Type))
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, counts)
+}
+
+// GetReactions 返回回應文章的用戶列表
+// @Summary 獲取文章的回應者
+// @Description 返回回應文章的分頁用戶列表，每頁50個，可按回應類型過濾
+// @Tags posts
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param type query string false "回應類型"
+// @Param page query int false "頁碼" default(1)
+// @Success 200 {array} reaction.Reactor
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /posts/{id}/reactions [get]
+func (h *ReactionHandler) GetReactions(c *gin.Context) {
+	var path idPath
+	if !bindPath(c, &path) {
+		return
+	}
+	var query reactorsQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	reactors, err := h.reactionService.GetReactors(c.Request.Context(), path.ID, reaction.Type(query.Type), query.Page)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reactors)
+}
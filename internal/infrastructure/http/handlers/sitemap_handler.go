@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"blog-api/internal/application/site"
+	appSitemap "blog-api/internal/application/sitemap"
+	"blog-api/internal/infrastructure/http/problem"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sitemapCacheControl 網站地圖在文章變更時重新生成，客戶端只需短時間緩存
+const sitemapCacheControl = "public, max-age=600"
+
+type SitemapHandler struct {
+	sitemapService *appSitemap.Service
+	links          site.Links
+}
+
+func NewSitemapHandler(sitemapService *appSitemap.Service, links site.Links) *SitemapHandler {
+	return &SitemapHandler{sitemapService: sitemapService, links: links}
+}
+
+// GetSitemap 返回網站地圖
+// @Summary 網站地圖
+// @Description 返回包含文章和作者頁面的網站地圖，URL 超過 50,000 個時返回網站地圖索引
+// @Tags seo
+// @Produce xml
+// @Success 200 {string} string
+// @Success 304
+// @Router /sitemap.xml [get]
+func (h *SitemapHandler) GetSitemap(c *gin.Context) {
+	doc, err := h.sitemapService.Index(c.Request.Context())
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	serveSitemap(c, doc)
+}
+
+// GetSitemapPart 返回拆分後的網站地圖分片
+// @Summary 網站地圖分片
+// @Description 返回網站地圖索引中引用的分片，文件名格式為 sitemap-{n}.xml
+// @Tags seo
+// @Produce xml
+// @Param file path string true "分片文件名"
+// @Success 200 {string} string
+// @Success 304
+// @Failure 404 {object} problem.Problem
+// @Router /sitemaps/{file} [get]
+func (h *SitemapHandler) GetSitemapPart(c *gin.Context) {
+	file := c.Param("file")
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(file, "sitemap-"), ".xml"))
+	if err != nil || appSitemap.PartPath(n) != "/sitemaps/"+file {
+		problem.Error(c, appSitemap.ErrSitemapNotFound)
+		return
+	}
+
+	doc, err := h.sitemapService.Part(c.Request.Context(), n)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	serveSitemap(c, doc)
+}
+
+// GetRobots 返回 robots.txt
+// @Summary robots.txt
+// @Description 允許爬取公開頁面，禁止爬取 API，並指向網站地圖
+// @Description 搜索引擎只接受與網站地圖同一主機的 URL，網站需要將 /robots.txt、/sitemap.xml 和 /sitemaps/ 代理到本 API
+// @Tags seo
+// @Produce plain
+// @Success 200 {string} string
+// @Router /robots.txt [get]
+func (h *SitemapHandler) GetRobots(c *gin.Context) {
+	robots := "User-agent: *\n" +
+		"Disallow: /api/\n" +
+		"Disallow: /swagger/\n" +
+		"Allow: /\n" +
+		"\n" +
+		"Sitemap: " + h.links.Site("/sitemap.xml") + "\n"
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.String(http.StatusOK, robots)
+}
+
+// serveSitemap 返回網站地圖文件並處理條件請求
+func serveSitemap(c *gin.Context, doc *appSitemap.Document) {
+	c.Header("Cache-Control", sitemapCacheControl)
+	c.Header("ETag", doc.ETag)
+	if !doc.LastModified.IsZero() {
+		c.Header("Last-Modified", doc.LastModified.UTC().Format(http.TimeFormat))
+	}
+	if notModified(c, doc.ETag, doc.LastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", doc.Body)
+}
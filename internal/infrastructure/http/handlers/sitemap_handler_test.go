@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"blog-api/internal/application/site"
+	appSitemap "blog-api/internal/application/sitemap"
+	"blog-api/internal/domain/sitemap"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type sitemapEntries struct{}
+
+func (sitemapEntries) FindPostEntries(context.Context) ([]sitemap.PostEntry, error) {
+	return []sitemap.PostEntry{{ID: 1, UpdatedAt: time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)}}, nil
+}
+
+func (sitemapEntries) FindAuthorEntries(context.Context) ([]sitemap.AuthorEntry, error) {
+	return nil, nil
+}
+
+func newTestSitemapHandler() *SitemapHandler {
+	links := site.NewLinks("https://blog.example.com", "https://api.example.com")
+	return NewSitemapHandler(appSitemap.NewService(sitemapEntries{}, links, appSitemap.Config{MaxURLs: 1}), links)
+}
+
+func TestGetRobotsPointsToSiteHost(t *testing.T) {
+	c, w := paramsContext("/robots.txt", nil)
+	newTestSitemapHandler().GetRobots(c)
+	if !strings.Contains(w.Body.String(), "\nSitemap: https://blog.example.com/sitemap.xml\n") {
+		t.Errorf("robots.txt = %q, want the sitemap on the site host", w.Body)
+	}
+}
+
+func TestGetSitemapPart(t *testing.T) {
+	h := newTestSitemapHandler()
+	c, w := paramsContext("/sitemaps/sitemap-2.xml", gin.Params{{Key: "file", Value: "sitemap-2.xml"}})
+	h.GetSitemapPart(c)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "https://blog.example.com/posts/1") {
+		t.Fatalf("sitemap-2.xml = %d %s", w.Code, w.Body)
+	}
+
+	etag := w.Header().Get("ETag")
+	c, w = paramsContext("/sitemaps/sitemap-2.xml", gin.Params{{Key: "file", Value: "sitemap-2.xml"}})
+	c.Request.Header.Set("If-None-Match", etag)
+	h.GetSitemapPart(c)
+	c.Writer.WriteHeaderNow()
+	if w.Code != http.StatusNotModified {
+		t.Errorf("conditional request with ETag %s = %d, want 304", etag, w.Code)
+	}
+
+	for _, file := range []string{"sitemap-02.xml", "sitemap-2", "sitemap-+2.xml", "sitemap-3.xml", "other.xml"} {
+		c, w := paramsContext("/sitemaps/"+file, gin.Params{{Key: "file", Value: file}})
+		h.GetSitemapPart(c)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s = %d, want 404", file, w.Code)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"blog-api/internal/application/site"
+	appSyndication "blog-api/internal/application/syndication"
+	"blog-api/internal/infrastructure/http/problem"
+	"blog-api/internal/infrastructure/syndication"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+// feedCacheControl 訂閱閱讀器通常按分鐘輪詢，短時間的緩存可以減少重複生成
+const feedCacheControl = "public, max-age=300"
+
+type SyndicationHandler struct {
+	syndicationService *appSyndication.Service
+	links              site.Links
+}
+
+func NewSyndicationHandler(syndicationService *appSyndication.Service, links site.Links) *SyndicationHandler {
+	return &SyndicationHandler{syndicationService: syndicationService, links: links}
+}
+
+// GetSiteRSS 返回全站的 RSS 訂閱源
+// @Summary 全站 RSS 訂閱源
+// @Description 返回最新文章的 RSS 2.0 訂閱源，支持 ETag 和 Last-Modified 條件請求
+// @Tags feeds
+// @Produce xml
+// @Success 200 {string} string
+// @Success 304
+// @Router /feed.rss [get]
+func (h *SyndicationHandler) GetSiteRSS(c *gin.Context) {
+	h.serveSiteFeed(c, syndication.FormatRSS)
+}
+
+// GetSiteAtom 返回全站的 Atom 訂閱源
+// @Summary 全站 Atom 訂閱源
+// @Description 返回最新文章的 Atom 1.0 訂閱源，支持 ETag 和 Last-Modified 條件請求
+// @Tags feeds
+// @Produce xml
+// @Success 200 {string} string
+// @Success 304
+// @Router /feed.atom [get]
+func (h *SyndicationHandler) GetSiteAtom(c *gin.Context) {
+	h.serveSiteFeed(c, syndication.FormatAtom)
+}
+
+// GetSiteJSONFeed 返回全站的 JSON Feed 訂閱源
+// @Summary 全站 JSON Feed 訂閱源
+// @Description 返回最新文章的 JSON Feed 1.1 訂閱源，支持 ETag 和 Last-Modified 條件請求
+// @Tags feeds
+// @Produce json
+// @Success 200 {string} string
+// @Success 304
+// @Router /feed.json [get]
+func (h *SyndicationHandler) GetSiteJSONFeed(c *gin.Context) {
+	h.serveSiteFeed(c, syndication.FormatJSON)
+}
+
+// GetAuthorRSS 返回作者的 RSS 訂閱源
+// @Summary 作者 RSS 訂閱源
+// @Description 返回指定作者最新文章的 RSS 2.0 訂閱源，支持 ETag 和 Last-Modified 條件請求
+// @Tags feeds
+// @Produce xml
+// @Param username path string true "作者用戶名"
+// @Success 200 {string} string
+// @Success 304
+// @Failure 404 {object} problem.Problem
+// @Router /users/{username}/feed.rss [get]
+func (h *SyndicationHandler) GetAuthorRSS(c *gin.Context) {
+	h.serveAuthorFeed(c, syndication.FormatRSS)
+}
+
+// GetAuthorAtom 返回作者的 Atom 訂閱源
+// @Summary 作者 Atom 訂閱源
+// @Description 返回指定作者最新文章的 Atom 1.0 訂閱源，支持 ETag 和 Last-Modified 條件請求
+// @Tags feeds
+// @Produce xml
+// @Param username path string true "作者用戶名"
+// @Success 200 {string} string
+// @Success 304
+// @Failure 404 {object} problem.Problem
+// @Router /users/{username}/feed.atom [get]
+func (h *SyndicationHandler) GetAuthorAtom(c *gin.Context) {
+	h.serveAuthorFeed(c, syndication.FormatAtom)
+}
+
+// GetAuthorJSONFeed 返回作者的 JSON Feed 訂閱源
+// @Summary 作者 JSON Feed 訂閱源
+// @Description 返回指定作者最新文章的 JSON Feed 1.1 訂閱源，支持 ETag 和 Last-Modified 條件請求
+// @Tags feeds
+// @Produce json
+// @Param username path string true "作者用戶名"
+// @Success 200 {string} string
+// @Success 304
+// @Failure 404 {object} problem.Problem
+// @Router /users/{username}/feed.json [get]
+func (h *SyndicationHandler) GetAuthorJSONFeed(c *gin.Context) {
+	h.serveAuthorFeed(c, syndication.FormatJSON)
+}
+
+// GetTagRSS 返回標籤的 RSS 訂閱源
+// @Summary 標籤 RSS 訂閱源
+// @Description 返回帶有指定標籤的最新文章的 RSS 2.0 訂閱源，支持 ETag 和 Last-Modified 條件請求
+// @Tags feeds
+// @Produce xml
+// @Param tag path string true "標籤"
+// @Success 200 {string} string
+// @Success 304
+// @Failure 404 {object} problem.Problem
+// @Router /tags/{tag}/feed.rss [get]
+func (h *SyndicationHandler) GetTagRSS(c *gin.Context) {
+	h.serveTagFeed(c, syndication.FormatRSS)
+}
+
+// GetTagAtom 返回標籤的 Atom 訂閱源
+// @Summary 標籤 Atom 訂閱源
+// @Description 返回帶有指定標籤的最新文章的 Atom 1.0 訂閱源，支持 ETag 和 Last-Modified 條件請求
+// @Tags feeds
+// @Produce xml
+// @Param tag path string true "標籤"
+// @Success 200 {string} string
+// @Success 304
+// @Failure 404 {object} problem.Problem
+// @Router /tags/{tag}/feed.atom [get]
+func (h *SyndicationHandler) GetTagAtom(c *gin.Context) {
+	h.serveTagFeed(c, syndication.FormatAtom)
+}
+
+// GetTagJSONFeed 返回標籤的 JSON Feed 訂閱源
+// @Summary 標籤 JSON Feed 訂閱源
+// @Description 返回帶有指定標籤的最新文章的 JSON Feed 1.1 訂閱源，支持 ETag 和 Last-Modified 條件請求
+// @Tags feeds
+// @Produce json
+// @Param tag path string true "標籤"
+// @Success 200 {string} string
+// @Success 304
+// @Failure 404 {object} problem.Problem
+// @Router /tags/{tag}/feed.json [get]
+func (h *SyndicationHandler) GetTagJSONFeed(c *gin.Context) {
+	h.serveTagFeed(c, syndication.FormatJSON)
+}
+
+// serveSiteFeed 生成並返回全站訂閱源
+func (h *SyndicationHandler) serveSiteFeed(c *gin.Context, format syndication.Format) {
+	feed, err := h.syndicationService.SiteFeed(c.Request.Context())
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	h.serveFeed(c, format, feed, "/feed."+string(format))
+}
+
+// serveAuthorFeed 生成並返回作者訂閱源
+func (h *SyndicationHandler) serveAuthorFeed(c *gin.Context, format syndication.Format) {
+	username := c.Param("username")
+	feed, err := h.syndicationService.AuthorFeed(c.Request.Context(), username)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	h.serveFeed(c, format, feed, "/users/"+url.PathEscape(username)+"/feed."+string(format))
+}
+
+// serveTagFeed 生成並返回標籤訂閱源
+func (h *SyndicationHandler) serveTagFeed(c *gin.Context, format syndication.Format) {
+	tag := c.Param("tag")
+	feed, err := h.syndicationService.TagFeed(c.Request.Context(), tag)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	h.serveFeed(c, format, feed, "/tags/"+url.PathEscape(tag)+"/feed."+string(format))
+}
+
+// serveFeed 渲染訂閱源並處理條件請求
+func (h *SyndicationHandler) serveFeed(c *gin.Context, format syndication.Format, feed *appSyndication.Feed, path string) {
+	body, err := syndication.Render(format, feed, h.links.API(path))
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("Cache-Control", feedCacheControl)
+	c.Header("ETag", etag)
+	if !feed.Updated.IsZero() {
+		c.Header("Last-Modified", feed.Updated.UTC().Format(http.TimeFormat))
+	}
+	if notModified(c, etag, feed.Updated) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, format.ContentType(), body)
+}
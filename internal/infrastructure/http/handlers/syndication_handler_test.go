@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	appPost "blog-api/internal/application/post"
+	"blog-api/internal/application/site"
+	appSyndication "blog-api/internal/application/syndication"
+	"blog-api/internal/domain/post"
+	"blog-api/internal/infrastructure/http/problem"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// syndicationPosts 返回固定的文章，FindByTag 只返回帶有該標籤的文章
+type syndicationPosts struct {
+	post.Repository
+	posts []post.Post
+	tags  []string
+}
+
+func (r *syndicationPosts) FindAll(context.Context, int, int) ([]post.Post, error) {
+	return append([]post.Post(nil), r.posts...), nil
+}
+
+func (r *syndicationPosts) FindByTag(_ context.Context, tag string, _, _ int) ([]post.Post, error) {
+	r.tags = append(r.tags, tag)
+	var posts []post.Post
+	for _, p := range r.posts {
+		for _, t := range p.Tags {
+			if t == tag {
+				posts = append(posts, p)
+			}
+		}
+	}
+	return posts, nil
+}
+
+func newTestSyndicationHandler(repo *syndicationPosts) *SyndicationHandler {
+	links := site.NewLinks("https://blog.example.com", "https://api.example.com")
+	posts := appPost.NewService(repo, feedAuthors{}, feedBookmarks{}, nil)
+	return NewSyndicationHandler(appSyndication.NewService(posts, feedAuthors{}, links, appSyndication.Config{Title: "Blog"}), links)
+}
+
+func testSyndicationPosts() *syndicationPosts {
+	created := time.Date(2024, 10, 20, 14, 0, 0, 0, time.UTC)
+	return &syndicationPosts{posts: []post.Post{
+		{ID: 2, UserID: 1, Title: "Second", Content: "Second post", Tags: post.Tags{"go"}, CreatedAt: created.Add(time.Hour), UpdatedAt: created.Add(2*time.Hour + 500*time.Millisecond)},
+		{ID: 1, UserID: 1, Title: "First", Content: "First post", Tags: post.Tags{"sql"}, CreatedAt: created, UpdatedAt: created},
+	}}
+}
+
+func TestSiteFeedConditionalRequests(t *testing.T) {
+	h := newTestSyndicationHandler(testSyndicationPosts())
+	formats := []struct {
+		name        string
+		serve       func(*gin.Context)
+		contentType string
+	}{
+		{"rss", h.GetSiteRSS, "application/rss+xml; charset=utf-8"},
+		{"atom", h.GetSiteAtom, "application/atom+xml; charset=utf-8"},
+		{"json", h.GetSiteJSONFeed, "application/feed+json; charset=utf-8"},
+	}
+	for _, format := range formats {
+		t.Run(format.name, func(t *testing.T) {
+			c, w := paramsContext("/feed."+format.name, nil)
+			format.serve(c)
+			if w.Code != http.StatusOK || w.Header().Get("Content-Type") != format.contentType {
+				t.Fatalf("GET = %d %s", w.Code, w.Header().Get("Content-Type"))
+			}
+			etag := w.Header().Get("ETag")
+			lastModified := w.Header().Get("Last-Modified")
+			if etag == "" || lastModified != "Sun, 20 Oct 2024 16:00:00 GMT" {
+				t.Fatalf("ETag = %q, Last-Modified = %q", etag, lastModified)
+			}
+
+			tests := []struct {
+				name    string
+				headers map[string]string
+				want    int
+			}{
+				{"matching etag", map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+				{"weak etag", map[string]string{"If-None-Match": "W/" + etag}, http.StatusNotModified},
+				{"etag in a list", map[string]string{"If-None-Match": `"other", ` + etag}, http.StatusNotModified},
+				{"stale etag", map[string]string{"If-None-Match": `"other"`}, http.StatusOK},
+				{"not modified since", map[string]string{"If-Modified-Since": lastModified}, http.StatusNotModified},
+				{"modified since", map[string]string{"If-Modified-Since": "Sun, 20 Oct 2024 15:59:59 GMT"}, http.StatusOK},
+				{"invalid date", map[string]string{"If-Modified-Since": "yesterday"}, http.StatusOK},
+				// If-None-Match 存在時忽略 If-Modified-Since
+				{"etag takes precedence", map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": lastModified}, http.StatusOK},
+			}
+			for _, tt := range tests {
+				c, w := paramsContext("/feed."+format.name, nil)
+				for k, v := range tt.headers {
+					c.Request.Header.Set(k, v)
+				}
+				format.serve(c)
+				c.Writer.WriteHeaderNow()
+				if w.Code != tt.want {
+					t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+				}
+				if tt.want == http.StatusNotModified && (w.Body.Len() != 0 || w.Header().Get("ETag") != etag) {
+					t.Errorf("%s: 304 has body %q and ETag %q", tt.name, w.Body, w.Header().Get("ETag"))
+				}
+			}
+		})
+	}
+}
+
+func TestTagFeed(t *testing.T) {
+	repo := testSyndicationPosts()
+	h := newTestSyndicationHandler(repo)
+
+	c, w := paramsContext("/tags/Go/feed.json", gin.Params{{Key: "tag", Value: "Go"}})
+	h.GetTagJSONFeed(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET = %d %s", w.Code, w.Body)
+	}
+	var feed struct {
+		Title       string `json:"title"`
+		HomePageURL string `json:"home_page_url"`
+		FeedURL     string `json:"feed_url"`
+		Items       []struct {
+			ID   string   `json:"id"`
+			Tags []string `json:"tags"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(repo.tags) != 1 || repo.tags[0] != "go" {
+		t.Errorf("queried tags = %v, want the normalized tag", repo.tags)
+	}
+	if feed.Title != "#go - Blog" || feed.HomePageURL != "https://blog.example.com/tags/go" || feed.FeedURL != "https://api.example.com/tags/Go/feed.json" {
+		t.Errorf("feed = %+v", feed)
+	}
+	if len(feed.Items) != 1 || feed.Items[0].ID != "https://blog.example.com/posts/2" || len(feed.Items[0].Tags) != 1 {
+		t.Errorf("items = %+v, want only the post tagged go", feed.Items)
+	}
+
+	c, w = paramsContext("/tags/rust/feed.rss", gin.Params{{Key: "tag", Value: "rust"}})
+	h.GetTagRSS(c)
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "<item>") {
+		t.Errorf("unused tag = %d %s, want an empty feed", w.Code, w.Body)
+	}
+
+	c, w = paramsContext("/tags/a%20b/feed.atom", gin.Params{{Key: "tag", Value: "a b"}})
+	h.GetTagAtom(c)
+	var p problem.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil || w.Code != http.StatusNotFound || p.Code != "tag_not_found" {
+		t.Errorf("invalid tag = %d %s, want 404 tag_not_found", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	appToken "blog-api/internal/application/token"
+	"blog-api/internal/infrastructure/http/middlewares"
+	"blog-api/internal/infrastructure/http/problem"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenHandler 處理與個人訪問令牌相關的 HTTP 請求
+type TokenHandler struct {
+	tokenService *appToken.Service
+}
+
+// NewTokenHandler 創建一個新的 TokenHandler 實例
+func NewTokenHandler(tokenService *appToken.Service) *TokenHandler {
+	return &TokenHandler{tokenService: tokenService}
+}
+
+// CreateToken 創建個人訪問令牌
+// @Summary 創建個人訪問令牌
+// @Description 為當前用戶創建一個命名的個人訪問令牌，明文令牌僅在此響應中返回一次。
+// @Description 使用個人訪問令牌調用時，新令牌的過期時間不會晚於調用者令牌的過期時間
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body appToken.CreateInput true "令牌信息"
+// @Success 201 {object} appToken.CreateResult
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Router /tokens [post]
+func (h *TokenHandler) CreateToken(c *gin.Context) {
+	var input appToken.CreateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+
+	result, err := h.tokenService.Create(c.Request.Context(), userID, middlewares.GetScopes(c), middlewares.GetTokenExpiresAt(c), input)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// ListTokens 列出個人訪問令牌
+// @Summary 列出個人訪問令牌
+// @Description 返回當前用戶的所有個人訪問令牌，不包含令牌明文
+// @Tags tokens
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} token.AccessToken
+// @Failure 401 {object} problem.Problem
+// @Router /tokens [get]
+func (h *TokenHandler) ListTokens(c *gin.Context) {
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+
+	tokens, err := h.tokenService.List(c.Request.Context(), userID)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// RevokeToken 撤銷個人訪問令牌
+// @Summary 撤銷個人訪問令牌
+// @Description 撤銷當前用戶的指定個人訪問令牌
+// @Tags tokens
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "令牌ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /tokens/{id} [delete]
+func (h *TokenHandler) RevokeToken(c *gin.Context) {
+	var path idPath
+	if !bindPath(c, &path) {
+		return
+	}
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+
+	if err := h.tokenService.Revoke(c.Request.Context(), userID, path.ID); err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
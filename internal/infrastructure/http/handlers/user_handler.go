@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	appFollow "blog-api/internal/application/follow"
 	"blog-api/internal/application/user"
+	domainUser "blog-api/internal/domain/user"
 	"blog-api/internal/infrastructure/http/middlewares"
+	"blog-api/internal/infrastructure/http/problem"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -10,12 +14,13 @@ import (
 
 // UserHandler 處理與用戶相關的 HTTP 請求
 type UserHandler struct {
-	userService *user.Service
+	userService   *user.Service
+	followService *appFollow.Service
 }
 
 // NewUserHandler 創建一個新的 UserHandler 實例
-func NewUserHandler(userService *user.Service) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService *user.Service, followService *appFollow.Service) *UserHandler {
+	return &UserHandler{userService: userService, followService: followService}
 }
 
 // Register 處理用戶註冊請求
@@ -26,19 +31,21 @@ func NewUserHandler(userService *user.Service) *UserHandler {
 // @Produce  json
 // @Param   input body user.RegisterInput true "註冊信息"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /register [post]
 func (h *UserHandler) Register(c *gin.Context) {
 	var input user.RegisterInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Error(c, err)
 		return
 	}
 
-	if err := h.userService.Register(input); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user"})
+	if err := h.userService.Register(c.Request.Context(), input); err != nil {
+		problem.Error(c, err)
 		return
 	}
 
@@ -47,30 +54,31 @@ func (h *UserHandler) Register(c *gin.Context) {
 
 // Login 處理用戶登錄請求
 // @Summary 用戶登錄
-// @Description 驗證用戶憑證並返回 JWT 令牌
+// @Description 驗證用戶憑證並返回 JWT 令牌，可選擇申請更窄的權限範圍和指定受眾
 // @Tags user
 // @Accept  json
 // @Produce  json
 // @Param   input body user.LoginInput true "登錄信息"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
 // @Router /login [post]
 func (h *UserHandler) Login(c *gin.Context) {
 	var input user.LoginInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Error(c, err)
 		return
 	}
 
-	token, err := h.userService.Login(input)
+	jwtToken, err := h.userService.Login(c.Request.Context(), input)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		problem.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, gin.H{"token": jwtToken})
 }
 
 // GetProfile 獲取用戶資料
@@ -80,25 +88,53 @@ func (h *UserHandler) Login(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} user.User
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /profile [get]
 func (h *UserHandler) GetProfile(c *gin.Context) {
 	userID, err := middlewares.GetUserID(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		respondUnauthorized(c)
 		return
 	}
 
-	user, err := h.userService.GetUserProfile(userID)
+	user, err := h.userService.GetUserProfile(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user profile"})
+		problem.Error(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, user)
 }
 
+// GetPublicProfile 獲取用戶的公開資料
+// @Summary 獲取作者公開資料
+// @Description 根據用戶名返回作者的公開資料，不包含郵箱等私有字段；已認證時包含是否已關注
+// @Tags users
+// @Produce json
+// @Param username path string true "用戶名"
+// @Success 200 {object} domainUser.PublicProfile
+// @Failure 404 {object} problem.Problem
+// @Router /users/{username} [get]
+func (h *UserHandler) GetPublicProfile(c *gin.Context) {
+	profile, err := h.userService.GetPublicProfile(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	if viewerID, ok := middlewares.GetOptionalUserID(c); ok {
+		following, err := h.followService.IsFollowing(c.Request.Context(), viewerID, profile.Username)
+		if err != nil {
+			problem.Error(c, err)
+			return
+		}
+		profile.IsFollowing = &following
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
 // ChangePassword 更改用戶密碼
 // @Summary 更改用戶密碼
 // @Description 更改當前登錄用戶的密碼
@@ -108,27 +144,143 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 // @Security BearerAuth
 // @Param input body user.ChangePasswordInput true "更改密碼信息"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /change-password [post]
 func (h *UserHandler) ChangePassword(c *gin.Context) {
 	var input user.ChangePasswordInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Error(c, err)
 		return
 	}
 
 	userID, err := middlewares.GetUserID(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		respondUnauthorized(c)
 		return
 	}
 
-	if err := h.userService.ChangePassword(userID, input); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+	if err := h.userService.ChangePassword(c.Request.Context(), userID, input); err != nil {
+		if errors.Is(err, domainUser.ErrInvalidPassword) {
+			respondIncorrectPassword(c, "currentPassword")
+			return
+		}
+		problem.Error(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
 }
+
+// UpdateProfile 更新用戶資料
+// @Summary 更新用戶資料
+// @Description 部分更新當前登錄用戶的資料，更改郵箱時會向新郵箱發送驗證鏈接，驗證前原郵箱保持不變
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body user.UpdateProfileInput true "要更新的資料"
+// @Success 200 {object} domainUser.User
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /profile [patch]
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	var input user.UpdateProfileInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+
+	u, err := h.userService.UpdateProfile(c.Request.Context(), userID, input)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, u)
+}
+
+// VerifyEmail 確認新郵箱
+// @Summary 確認新郵箱
+// @Description 使用驗證郵件中的令牌確認新的郵箱地址
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param input body user.VerifyEmailInput true "驗證令牌"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Router /verify-email [post]
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var input user.VerifyEmailInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	if err := h.userService.VerifyEmail(c.Request.Context(), input); err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+// DeleteAccount 刪除用戶賬戶
+// @Summary 刪除賬戶
+// @Description 確認密碼後刪除當前用戶的賬戶，文章按配置的策略匿名化、轉移、刪除，或在仍有文章時拒絕刪除
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body user.DeleteAccountInput true "當前密碼"
+// @Success 204 "No Content"
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /account [delete]
+func (h *UserHandler) DeleteAccount(c *gin.Context) {
+	var input user.DeleteAccountInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	userID, err := middlewares.GetUserID(c)
+	if err != nil {
+		respondUnauthorized(c)
+		return
+	}
+
+	if err := h.userService.DeleteAccount(c.Request.Context(), userID, input); err != nil {
+		if errors.Is(err, domainUser.ErrInvalidPassword) {
+			respondIncorrectPassword(c, "password")
+			return
+		}
+		problem.Error(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// respondIncorrectPassword 在已認證用戶確認密碼失敗時返回 403
+// 用戶已經登錄，因此不使用登錄失敗時的 401
+func respondIncorrectPassword(c *gin.Context, field string) {
+	problem.Write(c, problem.New(http.StatusForbidden, "incorrect_password", "password is incorrect").
+		WithFields(problem.FieldError{Field: field, Code: "incorrect_password", Message: "password is incorrect"}))
+}
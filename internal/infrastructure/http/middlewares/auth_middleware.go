@@ -1,8 +1,12 @@
 package middlewares
 
 import (
+	appToken "blog-api/internal/application/token"
 	"blog-api/internal/application/user"
+	"blog-api/internal/domain/token"
 	"blog-api/internal/infrastructure/auth"
+	"blog-api/internal/infrastructure/http/problem"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -17,58 +21,157 @@ const (
 	authorizationHeader  = "Authorization"
 	userIDKey            = "userID"
 	passwordChangedAtKey = "passwordChangedAt"
+	scopesKey            = "scopes"
+	tokenExpiresAtKey    = "tokenExpiresAt"
 )
 
 var (
 	errMissingAuthHeader = "Authorization header is required"
 	errInvalidToken      = "Invalid or expired token"
+	errInsufficientScope = "Token does not have the required scope"
 )
 
-// AuthMiddleware 返回一個 Gin 中間件，用於驗證 JWT 令牌
-func AuthMiddleware(jwtService *auth.JWTService, userService *user.Service) gin.HandlerFunc {
+// AuthMiddleware 返回一個 Gin 中間件，用於驗證 JWT 令牌或個人訪問令牌
+func AuthMiddleware(jwtService *auth.JWTService, userService *user.Service, tokenService *appToken.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		log.Println("Starting AuthMiddleware")
 		authHeader := c.GetHeader(authorizationHeader)
 
 		if authHeader == "" {
 			log.Println("Missing Authorization header")
-			c.JSON(http.StatusUnauthorized, gin.H{"error": errMissingAuthHeader})
-			c.Abort()
+			unauthorized(c, "authorization_required", errMissingAuthHeader)
 			return
 		}
 
-		tokenString := extractToken(authHeader)
-		claims, err := jwtService.ValidateToken(tokenString)
-		if err != nil {
-			log.Printf("Token validation error: %v", err)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": errInvalidToken})
-			c.Abort()
+		if !authenticate(c, extractToken(authHeader), jwtService, userService, tokenService) {
 			return
 		}
 
-		// 獲取用戶當前的資料
-		currentUser, err := userService.GetUserProfile(claims.UserID)
-		if err != nil {
-			log.Printf("Failed to get user profile: %v", err)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
-			c.Abort()
+		c.Next()
+		log.Println("Finished AuthMiddleware")
+	}
+}
+
+// OptionalAuthMiddleware 返回一個用於公開路由的 Gin 中間件
+// 沒有提供令牌時以匿名身份繼續，提供了令牌時與 AuthMiddleware 一樣驗證，無效的令牌仍會被拒絕
+func OptionalAuthMiddleware(jwtService *auth.JWTService, userService *user.Service, tokenService *appToken.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader(authorizationHeader)
+		if authHeader == "" {
+			c.Next()
 			return
 		}
 
-		// 比較 token 中的密碼更改時間與用戶當前的密碼更改時間
-		if claims.PasswordChangedAt.Before(currentUser.PasswordChangedAt) {
-			log.Printf("Token expired due to password change. Token time: %v, Current time: %v", claims.PasswordChangedAt, currentUser.PasswordChangedAt)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token expired due to password change"})
-			c.Abort()
+		if !authenticate(c, extractToken(authHeader), jwtService, userService, tokenService) {
 			return
 		}
+		c.Next()
+	}
+}
+
+// authenticate 驗證令牌並設置上下文，驗證失敗時寫入 401 響應並返回 false
+func authenticate(c *gin.Context, tokenString string, jwtService *auth.JWTService, userService *user.Service, tokenService *appToken.Service) bool {
+	if auth.IsPAT(tokenString) {
+		return authenticatePAT(c, tokenString, userService, tokenService)
+	}
+
+	claims, err := jwtService.ValidateToken(tokenString)
+	if err != nil {
+		log.Printf("Token validation error: %v", err)
+		rejectToken(c, err)
+		return false
+	}
+
+	// 獲取用戶當前的資料
+	currentUser, err := userService.GetUserProfile(c.Request.Context(), claims.UserID)
+	if err != nil {
+		log.Printf("Failed to get user profile: %v", err)
+		unauthorized(c, "user_not_found", "User not found")
+		return false
+	}
+
+	// 比較 token 中的密碼更改時間與用戶當前的密碼更改時間
+	if claims.PasswordChangedAt.Before(currentUser.PasswordChangedAt) {
+		log.Printf("Token expired due to password change. Token time: %v, Current time: %v", claims.PasswordChangedAt, currentUser.PasswordChangedAt)
+		unauthorized(c, "token_expired", "Token expired due to password change")
+		return false
+	}
+
+	c.Set(userIDKey, claims.UserID)
+	c.Set(passwordChangedAtKey, claims.PasswordChangedAt)
+	c.Set(scopesKey, claims.Scopes)
+	log.Printf("User authenticated: %d, Password changed at: %v", claims.UserID, claims.PasswordChangedAt)
+	return true
+}
+
+// tokenRejection 描述令牌驗證失敗時返回給客戶端的信息
+type tokenRejection struct {
+	code    string
+	message string
+}
+
+// tokenRejections 將令牌錯誤映射為不同的 401 響應，讓客戶端可以區分需要刷新還是重新登錄
+var tokenRejections = map[error]tokenRejection{
+	auth.ErrExpiredToken:     {code: "token_expired", message: "Token has expired"},
+	auth.ErrMalformedToken:   {code: "token_malformed", message: "Token is malformed"},
+	auth.ErrInvalidSignature: {code: "token_signature_invalid", message: "Token signature is invalid"},
+	auth.ErrTokenNotYetValid: {code: "token_not_yet_valid", message: "Token is not valid yet"},
+	auth.ErrInvalidIssuer:    {code: "token_issuer_invalid", message: "Token issuer is not accepted"},
+	auth.ErrInvalidAudience:  {code: "token_audience_invalid", message: "Token audience is not accepted"},
+}
+
+// rejectToken 根據令牌錯誤返回相應的 401 響應
+func rejectToken(c *gin.Context, err error) {
+	rejection := tokenRejection{code: "token_invalid", message: errInvalidToken}
+	for target, r := range tokenRejections {
+		if errors.Is(err, target) {
+			rejection = r
+			break
+		}
+	}
+	c.Header("WWW-Authenticate", fmt.Sprintf(`Bearer error="invalid_token", error_description=%q`, rejection.message))
+	unauthorized(c, rejection.code, rejection.message)
+}
+
+// unauthorized 寫出 401 問題詳情並中止請求
+func unauthorized(c *gin.Context, code, detail string) {
+	problem.Write(c, problem.New(http.StatusUnauthorized, code, detail))
+}
+
+// authenticatePAT 驗證個人訪問令牌並設置上下文
+func authenticatePAT(c *gin.Context, raw string, userService *user.Service, tokenService *appToken.Service) bool {
+	t, err := tokenService.Authenticate(c.Request.Context(), raw)
+	if err != nil {
+		log.Printf("Access token validation error: %v", err)
+		unauthorized(c, "token_invalid", errInvalidToken)
+		return false
+	}
+
+	currentUser, err := userService.GetUserProfile(c.Request.Context(), t.UserID)
+	if err != nil || !currentUser.IsActive {
+		log.Printf("Access token owner %d is unavailable: %v", t.UserID, err)
+		unauthorized(c, "user_not_found", "User not found")
+		return false
+	}
 
-		c.Set(userIDKey, claims.UserID)
-		c.Set(passwordChangedAtKey, claims.PasswordChangedAt)
-		log.Printf("User authenticated: %d, Password changed at: %v", claims.UserID, claims.PasswordChangedAt)
+	c.Set(userIDKey, t.UserID)
+	c.Set(scopesKey, t.Scopes)
+	if t.ExpiresAt != nil {
+		c.Set(tokenExpiresAtKey, *t.ExpiresAt)
+	}
+	log.Printf("User authenticated with access token %s: %d", t.Prefix, t.UserID)
+	return true
+}
 
+// RequireScope 返回一個 Gin 中間件，要求已認證的令牌具備指定的權限範圍
+// 必須在 AuthMiddleware 之後使用
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !GetScopes(c).Has(scope) {
+			problem.Write(c, problem.New(http.StatusForbidden, "insufficient_scope", errInsufficientScope).With("requiredScope", scope))
+			return
+		}
 		c.Next()
-		log.Println("Finished AuthMiddleware")
 	}
 }
 
@@ -95,6 +198,16 @@ func GetUserID(c *gin.Context) (uint, error) {
 	return id, nil
 }
 
+// GetOptionalUserID 從 Gin 上下文中獲取可能存在的用戶 ID
+// 用於 OptionalAuthMiddleware 保護的路由，匿名請求時返回 false
+func GetOptionalUserID(c *gin.Context) (uint, bool) {
+	id, err := GetUserID(c)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
 // GetPasswordChangedAt 從 Gin 上下文中獲取密碼修改時間
 func GetPasswordChangedAt(c *gin.Context) (time.Time, error) {
 	passwordChangedAt, exists := c.Get(passwordChangedAtKey)
@@ -109,3 +222,27 @@ func GetPasswordChangedAt(c *gin.Context) (time.Time, error) {
 
 	return changedAt, nil
 }
+
+// GetScopes 從 Gin 上下文中獲取已認證令牌的權限範圍
+func GetScopes(c *gin.Context) token.Scopes {
+	scopes, exists := c.Get(scopesKey)
+	if !exists {
+		return nil
+	}
+	s, _ := scopes.(token.Scopes)
+	return s
+}
+
+// GetTokenExpiresAt 從 Gin 上下文中獲取調用者個人訪問令牌的過期時間
+// 使用 JWT 或永不過期的個人訪問令牌認證時返回 nil
+func GetTokenExpiresAt(c *gin.Context) *time.Time {
+	expiresAt, exists := c.Get(tokenExpiresAtKey)
+	if !exists {
+		return nil
+	}
+	t, ok := expiresAt.(time.Time)
+	if !ok {
+		return nil
+	}
+	return &t
+}
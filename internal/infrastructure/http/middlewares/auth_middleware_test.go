@@ -0,0 +1,185 @@
+package middlewares
+
+import (
+	"blog-api/internal/domain/token"
+	"blog-api/internal/infrastructure/auth"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// problemBody 是測試中解碼的問題詳情
+type problemBody struct {
+	Status        int    `json:"status"`
+	Code          string `json:"code"`
+	Detail        string `json:"detail"`
+	RequiredScope string `json:"requiredScope"`
+}
+
+// decodeProblem 解碼響應中的問題詳情
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) problemBody {
+	t.Helper()
+	var body problemBody
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+	return body
+}
+
+// wwwAuthenticate 返回指定描述的 WWW-Authenticate 頭
+func wwwAuthenticate(description string) string {
+	return fmt.Sprintf(`Bearer error="invalid_token", error_description=%q`, description)
+}
+
+func TestRejectToken(t *testing.T) {
+	tests := []struct {
+		err         error
+		code        string
+		description string
+	}{
+		{auth.ErrExpiredToken, "token_expired", "Token has expired"},
+		{auth.ErrMalformedToken, "token_malformed", "Token is malformed"},
+		{auth.ErrInvalidSignature, "token_signature_invalid", "Token signature is invalid"},
+		{auth.ErrTokenNotYetValid, "token_not_yet_valid", "Token is not valid yet"},
+		{auth.ErrInvalidIssuer, "token_issuer_invalid", "Token issuer is not accepted"},
+		{auth.ErrInvalidAudience, "token_audience_invalid", "Token audience is not accepted"},
+		{fmt.Errorf("parse: %w", auth.ErrExpiredToken), "token_expired", "Token has expired"},
+		{fmt.Errorf("%w: %v", auth.ErrInvalidToken, jwt.ErrTokenRequiredClaimMissing), "token_invalid", errInvalidToken},
+		{errors.New("unexpected"), "token_invalid", errInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+			rejectToken(c, tt.err)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401", w.Code)
+			}
+			if !c.IsAborted() {
+				t.Error("request was not aborted")
+			}
+			if got := w.Header().Get("WWW-Authenticate"); got != wwwAuthenticate(tt.description) {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, wwwAuthenticate(tt.description))
+			}
+			if body := decodeProblem(t, w); body.Code != tt.code || body.Detail != tt.description {
+				t.Errorf("problem = %+v, want code %s", body, tt.code)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareRejectsInvalidJWT(t *testing.T) {
+	keys, err := auth.NewKeyManager(auth.KeySource{SecretKey: testSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService := auth.NewJWTService(keys, auth.JWTConfig{})
+	sign := func(modify func(*auth.Claims)) string {
+		now := time.Now()
+		claims := auth.Claims{
+			UserID: 1,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    auth.DefaultIssuer,
+				Audience:  jwt.ClaimStrings{auth.DefaultAudience},
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+				NotBefore: jwt.NewNumericDate(now),
+				IssuedAt:  jwt.NewNumericDate(now),
+			},
+		}
+		modify(&claims)
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	past := jwt.NewNumericDate(time.Now().Add(-time.Hour))
+
+	tests := []struct {
+		name   string
+		header string
+		code   string
+	}{
+		{"missing header", "", "authorization_required"},
+		{"malformed", "Bearer garbage", "token_malformed"},
+		{"expired", "Bearer " + sign(func(c *auth.Claims) { c.ExpiresAt = past }), "token_expired"},
+		{"wrong audience", "Bearer " + sign(func(c *auth.Claims) { c.Audience = jwt.ClaimStrings{"other"} }), "token_audience_invalid"},
+		{"wrong issuer", "Bearer " + sign(func(c *auth.Claims) { c.Issuer = "other" }), "token_issuer_invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/", AuthMiddleware(jwtService, nil, nil), func(c *gin.Context) {
+				t.Error("handler called for a rejected token")
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(authorizationHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401", w.Code)
+			}
+			if body := decodeProblem(t, w); body.Code != tt.code {
+				t.Errorf("code = %q, want %q", body.Code, tt.code)
+			}
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes interface{}
+		status int
+	}{
+		{"granted", token.Scopes{token.ScopePostsRead, token.ScopePostsWrite}, http.StatusNoContent},
+		{"full access", token.AllScopes, http.StatusNoContent},
+		{"missing scope", token.Scopes{token.ScopePostsRead}, http.StatusForbidden},
+		{"no scopes", token.Scopes{}, http.StatusForbidden},
+		{"unauthenticated", nil, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.POST("/", func(c *gin.Context) {
+				if tt.scopes != nil {
+					c.Set(scopesKey, tt.scopes)
+				}
+			}, RequireScope(token.ScopePostsWrite), func(c *gin.Context) {
+				c.Status(http.StatusNoContent)
+			})
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if tt.status != http.StatusForbidden {
+				return
+			}
+			body := decodeProblem(t, w)
+			if body.Code != "insufficient_scope" || body.RequiredScope != token.ScopePostsWrite {
+				t.Errorf("problem = %+v", body)
+			}
+		})
+	}
+}
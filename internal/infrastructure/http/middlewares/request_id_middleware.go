@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"blog-api/internal/infrastructure/http/problem"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestIDKey 是請求 ID 在 Gin 上下文中的鍵
+const requestIDKey = "requestID"
+
+// validRequestID 限制客戶端提供的請求 ID，避免將任意內容寫入響應頭和日誌
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID 返回一個 Gin 中間件，為每個請求分配請求 ID 並寫入響應頭
+// 客戶端或網關提供了合法的 X-Request-ID 時沿用，否則生成新的 ID；錯誤響應和日誌都會帶上它
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(problem.RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(problem.RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// GetRequestID 從 Gin 上下文中獲取當前請求的 ID
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// newRequestID 生成一個隨機的請求 ID
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,23 @@
+package middlewares
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout 返回一個 Gin 中間件，為每個請求的上下文設置處理時限
+// 超時或客戶端斷開連接時，服務和存儲層中進行的數據庫查詢會隨上下文一起取消；timeout 為 0 時不限制
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,105 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// UseRequestFieldNames 讓驗證錯誤使用客戶端發送的字段名
+// 依次使用 json（請求體）、form（查詢參數）和 uri（路徑參數）標籤，都沒有時使用結構體字段名
+func UseRequestFieldNames() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		for _, tag := range []string{"json", "form", "uri"} {
+			name := strings.SplitN(f.Tag.Get(tag), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return f.Name
+	})
+}
+
+// InvalidParams 創建路徑或查詢參數無效時的 400 問題詳情
+func InvalidParams(fields ...FieldError) *Problem {
+	return New(http.StatusBadRequest, "invalid_parameter", "the request contains invalid parameters").WithFields(fields...)
+}
+
+// ValidationFields 將驗證錯誤轉換為字段錯誤
+func ValidationFields(errs validator.ValidationErrors) []FieldError {
+	fields := make([]FieldError, 0, len(errs))
+	for _, fe := range errs {
+		fields = append(fields, FieldError{Field: fe.Field(), Code: fe.Tag(), Message: validationMessage(fe)})
+	}
+	return fields
+}
+
+// fromBinding 轉換請求體綁定和驗證錯誤
+func fromBinding(err error) *Problem {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return New(http.StatusBadRequest, "validation_failed", "the request contains invalid fields").
+			WithFields(ValidationFields(validationErrs)...)
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &typeErr):
+		p := New(http.StatusBadRequest, "malformed_body", "the request body contains a value of the wrong type")
+		if typeErr.Field != "" {
+			p.WithFields(FieldError{Field: typeErr.Field, Code: "type", Message: "must be of type " + typeErr.Type.String()})
+		}
+		return p
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return New(http.StatusBadRequest, "malformed_body", "the request body is not valid JSON")
+	}
+	return nil
+}
+
+// validationMessage 為常見的驗證標籤生成可讀的說明
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return fe.Field() + " is required"
+	case "email":
+		return fe.Field() + " must be a valid email address"
+	case "min":
+		if isNumber(fe.Kind()) {
+			return fe.Field() + " must be at least " + fe.Param()
+		}
+		return fe.Field() + " must have at least " + fe.Param() + " item(s) or characters"
+	case "max":
+		if isNumber(fe.Kind()) {
+			return fe.Field() + " must be at most " + fe.Param()
+		}
+		return fe.Field() + " must have at most " + fe.Param() + " item(s) or characters"
+	case "oneof":
+		return fe.Field() + " must be one of: " + fe.Param()
+	}
+	return fe.Field() + " is invalid"
+}
+
+// isNumber 判斷字段是否為數值類型，數值的 min/max 表示大小而不是長度
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
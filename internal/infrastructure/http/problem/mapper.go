@@ -0,0 +1,150 @@
+package problem
+
+import (
+	appSitemap "blog-api/internal/application/sitemap"
+	"blog-api/internal/domain/bookmark"
+	"blog-api/internal/domain/export"
+	"blog-api/internal/domain/follow"
+	"blog-api/internal/domain/post"
+	"blog-api/internal/domain/reaction"
+	"blog-api/internal/domain/token"
+	"blog-api/internal/domain/user"
+	"blog-api/internal/infrastructure/auth"
+	"blog-api/internal/infrastructure/imaging"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatusClientClosedRequest 表示客戶端在響應之前斷開了連接，沿用 nginx 的非標準狀態碼
+const StatusClientClosedRequest = 499
+
+// mapping 描述一個領域錯誤對應的 HTTP 狀態碼和錯誤碼
+// field 不為空時同時輸出字段錯誤；detail 為空時使用錯誤本身的信息
+type mapping struct {
+	target error
+	status int
+	code   string
+	field  string
+	detail string
+}
+
+// mappings 是領域錯誤到問題詳情的映射表，按順序使用 errors.Is 匹配
+// 違反業務規則的輸入返回 422，請求本身無法解析或缺少必填字段時返回 400
+var mappings = []mapping{
+	{target: post.ErrPostNotFound, status: http.StatusNotFound, code: "post_not_found"},
+	{target: post.ErrAuthorNotFound, status: http.StatusNotFound, code: "user_not_found", detail: "user not found"},
+	{target: post.ErrUnauthorized, status: http.StatusForbidden, code: "not_post_author", detail: "only the author of the post can perform this action"},
+	{target: post.ErrInvalidTitle, status: http.StatusUnprocessableEntity, code: "validation_failed", field: "title"},
+	{target: post.ErrInvalidContent, status: http.StatusUnprocessableEntity, code: "validation_failed", field: "content"},
+	{target: post.ErrInvalidTags, status: http.StatusUnprocessableEntity, code: "validation_failed", field: "tags"},
+	{target: post.ErrTagNotFound, status: http.StatusNotFound, code: "tag_not_found"},
+	{target: post.ErrInvalidPatch, status: http.StatusUnprocessableEntity, code: "invalid_patch"},
+	{target: post.ErrPatchTestFailed, status: http.StatusConflict, code: "patch_test_failed"},
+	{target: post.ErrVersionConflict, status: http.StatusConflict, code: "version_conflict"},
+
+	{target: user.ErrUserNotFound, status: http.StatusNotFound, code: "user_not_found"},
+	// 不區分密碼錯誤和賬戶停用，避免透露賬戶狀態
+	{target: user.ErrInvalidPassword, status: http.StatusUnauthorized, code: "invalid_credentials", detail: "invalid username or password"},
+	{target: user.ErrAccountInactive, status: http.StatusUnauthorized, code: "invalid_credentials", detail: "invalid username or password"},
+	{target: user.ErrDuplicateUsername, status: http.StatusConflict, code: "username_taken", field: "username"},
+	{target: user.ErrDuplicateEmail, status: http.StatusConflict, code: "email_taken", field: "email"},
+	{target: user.ErrInvalidName, status: http.StatusUnprocessableEntity, code: "validation_failed"},
+	{target: user.ErrInvalidBio, status: http.StatusUnprocessableEntity, code: "validation_failed", field: "bio"},
+	{target: user.ErrInvalidWebsite, status: http.StatusUnprocessableEntity, code: "validation_failed", field: "website"},
+	{target: user.ErrInvalidEmailToken, status: http.StatusBadRequest, code: "invalid_email_token", field: "token"},
+	{target: user.ErrDeletionTarget, status: http.StatusConflict, code: "deletion_target_unavailable"},
+	{target: user.ErrUserHasPosts, status: http.StatusConflict, code: "user_has_posts", detail: "delete or transfer your posts before deleting the account"},
+
+	{target: reaction.ErrInvalidType, status: http.StatusBadRequest, code: "invalid_reaction_type", field: "type"},
+	{target: follow.ErrSelfFollow, status: http.StatusUnprocessableEntity, code: "self_follow"},
+
+	{target: bookmark.ErrBookmarkNotFound, status: http.StatusNotFound, code: "bookmark_not_found"},
+	{target: bookmark.ErrInvalidFolder, status: http.StatusUnprocessableEntity, code: "validation_failed", field: "folder"},
+	{target: bookmark.ErrInvalidNote, status: http.StatusUnprocessableEntity, code: "validation_failed", field: "note"},
+
+	{target: export.ErrJobNotFound, status: http.StatusNotFound, code: "export_not_found"},
+	{target: export.ErrJobNotReady, status: http.StatusNotFound, code: "export_not_ready"},
+	{target: export.ErrExportExpired, status: http.StatusGone, code: "export_expired"},
+	{target: export.ErrInvalidSignature, status: http.StatusForbidden, code: "invalid_download_link"},
+	{target: export.ErrJobAlreadyRunning, status: http.StatusConflict, code: "export_in_progress"},
+
+	{target: token.ErrTokenNotFound, status: http.StatusNotFound, code: "access_token_not_found"},
+	{target: token.ErrTokenRevoked, status: http.StatusUnauthorized, code: "access_token_revoked"},
+	{target: token.ErrTokenExpired, status: http.StatusUnauthorized, code: "access_token_expired"},
+	{target: token.ErrInvalidName, status: http.StatusUnprocessableEntity, code: "validation_failed", field: "name"},
+	{target: token.ErrInvalidScope, status: http.StatusUnprocessableEntity, code: "validation_failed", field: "scopes"},
+	{target: token.ErrInvalidExpiry, status: http.StatusUnprocessableEntity, code: "validation_failed", field: "expiresAt"},
+	{target: token.ErrInsufficientScope, status: http.StatusForbidden, code: "insufficient_scope", detail: "cannot grant scopes beyond the current token"},
+
+	{target: auth.ErrInvalidAudience, status: http.StatusUnprocessableEntity, code: "validation_failed", field: "audience"},
+	{target: imaging.ErrInvalidImage, status: http.StatusUnprocessableEntity, code: "invalid_image", field: "avatar"},
+	{target: imaging.ErrImageTooLarge, status: http.StatusUnprocessableEntity, code: "invalid_image", field: "avatar"},
+	{target: appSitemap.ErrSitemapNotFound, status: http.StatusNotFound, code: "sitemap_not_found"},
+
+	{target: context.DeadlineExceeded, status: http.StatusGatewayTimeout, code: "timeout", detail: "the request took too long to process"},
+	{target: context.Canceled, status: StatusClientClosedRequest, code: "client_closed_request", detail: "the client closed the request"},
+}
+
+// FromError 將錯誤轉換為問題詳情，未知錯誤返回不含內部信息的 500
+func FromError(err error) *Problem {
+	var policyErr *user.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		p := New(http.StatusUnprocessableEntity, "password_policy", "password does not meet policy")
+		for _, v := range policyErr.Violations {
+			p.WithFields(FieldError{Field: "password", Code: v.Rule, Message: v.Message})
+		}
+		return p
+	}
+
+	var conflict *post.ConflictError
+	if errors.As(err, &conflict) {
+		p := fromMapping(err)
+		if p == nil {
+			p = New(http.StatusConflict, "version_conflict", post.ErrVersionConflict.Error())
+		}
+		return p.With("current", conflict.Current)
+	}
+
+	if p := fromBinding(err); p != nil {
+		return p
+	}
+	if p := fromMapping(err); p != nil {
+		return p
+	}
+	return New(http.StatusInternalServerError, "internal_error", "an unexpected error occurred")
+}
+
+// fromMapping 在映射表中查找錯誤
+func fromMapping(err error) *Problem {
+	for _, m := range mappings {
+		if !errors.Is(err, m.target) {
+			continue
+		}
+		detail := m.detail
+		if detail == "" {
+			detail = m.target.Error()
+		}
+		p := New(m.status, m.code, detail)
+		if m.status == StatusClientClosedRequest {
+			p.Title = "Client Closed Request"
+		}
+		if m.field != "" {
+			p.WithFields(FieldError{Field: m.field, Code: m.code, Message: detail})
+		}
+		return p
+	}
+	return nil
+}
+
+// Error 將錯誤轉換為問題詳情並寫出，服務器錯誤會連同請求 ID 記錄到日誌
+func Error(c *gin.Context, err error) {
+	p := FromError(err)
+	if p.Status >= http.StatusInternalServerError {
+		log.Printf("Request %s %s failed [%s]: %v", c.Request.Method, c.Request.URL.Path, c.Writer.Header().Get(RequestIDHeader), err)
+	}
+	Write(c, p)
+}
@@ -0,0 +1,155 @@
+package problem
+
+import (
+	"blog-api/internal/domain/post"
+	"blog-api/internal/domain/user"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFromErrorMapsDomainErrors(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+		field  string
+	}{
+		{post.ErrPostNotFound, http.StatusNotFound, "post_not_found", ""},
+		{user.ErrDuplicateUsername, http.StatusConflict, "username_taken", "username"},
+		{user.ErrDuplicateEmail, http.StatusConflict, "email_taken", "email"},
+		{fmt.Errorf("update user: %w", user.ErrDuplicateEmail), http.StatusConflict, "email_taken", "email"},
+		{user.ErrAccountInactive, http.StatusUnauthorized, "invalid_credentials", ""},
+		{post.ErrInvalidTitle, http.StatusUnprocessableEntity, "validation_failed", "title"},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout, "timeout", ""},
+	}
+	for _, tt := range tests {
+		p := FromError(tt.err)
+		if p.Status != tt.status || p.Code != tt.code || p.Type != typePrefix+tt.code {
+			t.Errorf("FromError(%v) = %d %s %s, want %d %s", tt.err, p.Status, p.Code, p.Type, tt.status, tt.code)
+		}
+		if tt.field == "" {
+			if len(p.Errors) != 0 {
+				t.Errorf("FromError(%v) errors = %+v, want none", tt.err, p.Errors)
+			}
+			continue
+		}
+		if len(p.Errors) != 1 || p.Errors[0].Field != tt.field || p.Errors[0].Code != tt.code {
+			t.Errorf("FromError(%v) errors = %+v, want one %s error on %s", tt.err, p.Errors, tt.code, tt.field)
+		}
+	}
+}
+
+func TestFromErrorUsesMappingDetail(t *testing.T) {
+	// 密碼錯誤和賬戶停用使用相同的信息，不透露賬戶狀態
+	if a, b := FromError(user.ErrInvalidPassword), FromError(user.ErrAccountInactive); a.Detail != b.Detail || a.Detail == "" {
+		t.Errorf("details = %q and %q, want the same non-empty detail", a.Detail, b.Detail)
+	}
+	if p := FromError(post.ErrPostNotFound); p.Detail != post.ErrPostNotFound.Error() {
+		t.Errorf("detail = %q, want %q", p.Detail, post.ErrPostNotFound.Error())
+	}
+	if p := FromError(context.Canceled); p.Status != StatusClientClosedRequest || p.Title != "Client Closed Request" {
+		t.Errorf("FromError(context.Canceled) = %d %q", p.Status, p.Title)
+	}
+}
+
+func TestFromErrorHidesUnknownErrors(t *testing.T) {
+	p := FromError(errors.New("pq: connection refused to 10.0.0.5"))
+	if p.Status != http.StatusInternalServerError || p.Code != "internal_error" {
+		t.Errorf("FromError = %d %s, want 500 internal_error", p.Status, p.Code)
+	}
+	if p.Detail != "an unexpected error occurred" {
+		t.Errorf("detail = %q leaks the internal error", p.Detail)
+	}
+}
+
+func TestFromErrorPasswordPolicy(t *testing.T) {
+	err := &user.PasswordPolicyError{Violations: []user.PasswordViolation{
+		{Rule: "min_length", Message: "too short"},
+		{Rule: "digit", Message: "needs a digit"},
+	}}
+	p := FromError(fmt.Errorf("register: %w", err))
+	if p.Status != http.StatusUnprocessableEntity || p.Code != "password_policy" || len(p.Errors) != 2 {
+		t.Fatalf("FromError = %d %s %+v", p.Status, p.Code, p.Errors)
+	}
+	if p.Errors[1] != (FieldError{Field: "password", Code: "digit", Message: "needs a digit"}) {
+		t.Errorf("errors[1] = %+v", p.Errors[1])
+	}
+}
+
+func TestFromErrorConflictIncludesCurrent(t *testing.T) {
+	current := &post.Post{ID: 7, Title: "Current", Version: 4}
+	tests := []struct {
+		err  error
+		code string
+	}{
+		{&post.ConflictError{Current: current, Err: post.ErrVersionConflict}, "version_conflict"},
+		{&post.ConflictError{Current: current, Err: post.ErrPatchTestFailed}, "patch_test_failed"},
+		{&post.ConflictError{Current: current}, "version_conflict"},
+	}
+	for _, tt := range tests {
+		p := FromError(tt.err)
+		if p.Status != http.StatusConflict || p.Code != tt.code {
+			t.Errorf("FromError(%v) = %d %s, want 409 %s", tt.err, p.Status, p.Code, tt.code)
+		}
+
+		body, err := json.Marshal(p)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		var decoded struct {
+			Code    string    `json:"code"`
+			Current post.Post `json:"current"`
+		}
+		if err := json.Unmarshal(body, &decoded); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		if decoded.Code != tt.code || decoded.Current.ID != current.ID || decoded.Current.Version != current.Version {
+			t.Errorf("body = %s, want the code and the current post", body)
+		}
+	}
+}
+
+func TestMarshalJSONKeepsStandardMembers(t *testing.T) {
+	body, err := json.Marshal(New(http.StatusConflict, "version_conflict", "stale").With("code", "overridden").With("extra", 1))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var members map[string]interface{}
+	if err := json.Unmarshal(body, &members); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if members["code"] != "version_conflict" || members["extra"] != float64(1) {
+		t.Errorf("body = %s, want standard code and the extension member", body)
+	}
+}
+
+func TestErrorWritesProblem(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", nil)
+	c.Header(RequestIDHeader, "req-1")
+
+	Error(c, user.ErrDuplicateUsername)
+
+	if w.Code != http.StatusConflict || w.Header().Get("Content-Type") != MediaType {
+		t.Fatalf("response = %d %s, want 409 %s", w.Code, w.Header().Get("Content-Type"), MediaType)
+	}
+	if !c.IsAborted() {
+		t.Error("Error did not abort the handler chain")
+	}
+	var p Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if p.Code != "username_taken" || p.Instance != "/api/v1/auth/register" || p.RequestID != "req-1" {
+		t.Errorf("problem = %+v", p)
+	}
+}
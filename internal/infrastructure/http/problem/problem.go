@@ -0,0 +1,105 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MediaType 是 RFC 7807 問題詳情的媒體類型
+const MediaType = "application/problem+json"
+
+// RequestIDHeader 是攜帶請求 ID 的 HTTP 頭
+const RequestIDHeader = "X-Request-ID"
+
+// typePrefix 問題類型 URI 的前綴，後接錯誤碼
+const typePrefix = "urn:blog-api:problem:"
+
+// FieldError 描述單個字段未通過驗證的原因
+type FieldError struct {
+	Field   string `json:"field" example:"title"`
+	Code    string `json:"code" example:"required"`
+	Message string `json:"message" example:"title is required"`
+}
+
+// Problem 是 RFC 7807 問題詳情，所有錯誤響應都使用此格式
+// Code 是穩定的機器可讀錯誤碼，客戶端應根據它而不是 Detail 處理錯誤
+type Problem struct {
+	Type       string                 `json:"type" example:"urn:blog-api:problem:post_not_found"`
+	Title      string                 `json:"title" example:"Not Found"`
+	Status     int                    `json:"status" example:"404"`
+	Detail     string                 `json:"detail,omitempty" example:"post not found"`
+	Instance   string                 `json:"instance,omitempty" example:"/api/v1/posts/42"`
+	Code       string                 `json:"code" example:"post_not_found"`
+	RequestID  string                 `json:"requestId,omitempty" example:"5f2b8c1e9a7d4e3f"`
+	Errors     []FieldError           `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// New 創建一個新的問題詳情，標題取自狀態碼
+func New(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   typePrefix + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// WithFields 附加字段級的驗證錯誤
+func (p *Problem) WithFields(fields ...FieldError) *Problem {
+	p.Errors = append(p.Errors, fields...)
+	return p
+}
+
+// With 附加一個擴展成員，例如衝突時的當前資源
+func (p *Problem) With(key string, value interface{}) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]interface{})
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+// MarshalJSON 將擴展成員與標準成員輸出在同一層級
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type plain Problem
+	body, err := json.Marshal(plain(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return body, err
+	}
+
+	members := make(map[string]interface{}, len(p.Extensions)+8)
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+	var standard map[string]json.RawMessage
+	if err := json.Unmarshal(body, &standard); err != nil {
+		return nil, err
+	}
+	// 標準成員優先，擴展成員不能覆蓋它們
+	for k, v := range standard {
+		members[k] = v
+	}
+	return json.Marshal(members)
+}
+
+// Write 寫出問題詳情並中止後續處理器，請求路徑和請求 ID 自動填入
+func Write(c *gin.Context, p *Problem) {
+	if p.Instance == "" && c.Request != nil {
+		p.Instance = c.Request.URL.Path
+	}
+	if p.RequestID == "" {
+		p.RequestID = c.Writer.Header().Get(RequestIDHeader)
+	}
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Abort()
+	c.Data(p.Status, MediaType, body)
+}
@@ -1,52 +1,152 @@
 package http
 
 import (
+	appToken "blog-api/internal/application/token"
 	"blog-api/internal/application/user"
+	"blog-api/internal/domain/token"
+	domainUser "blog-api/internal/domain/user"
 	"blog-api/internal/infrastructure/auth"
 	"blog-api/internal/infrastructure/http/handlers"
 	"blog-api/internal/infrastructure/http/middlewares"
+	"blog-api/internal/infrastructure/http/problem"
+	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-// SetupRouter 配置 API 路由
-func SetupRouter(userHandler *handlers.UserHandler, postHandler *handlers.PostHandler, jwtService *auth.JWTService, userService *user.Service) *gin.Engine {
-	r := gin.Default()
+// Handlers 匯總路由使用的所有處理器
+type Handlers struct {
+	User        *handlers.UserHandler
+	Post        *handlers.PostHandler
+	Token       *handlers.TokenHandler
+	JWKS        *handlers.JWKSHandler
+	Export      *handlers.ExportHandler
+	Avatar      *handlers.AvatarHandler
+	Follow      *handlers.FollowHandler
+	Reaction    *handlers.ReactionHandler
+	Bookmark    *handlers.BookmarkHandler
+	Analytics   *handlers.AnalyticsHandler
+	Syndication *handlers.SyndicationHandler
+	Sitemap     *handlers.SitemapHandler
+}
+
+// SetupRouter 配置 API 路由，requestTimeout 限制每個請求的處理時間
+func SetupRouter(h Handlers, jwtService *auth.JWTService, userService *user.Service, tokenService *appToken.Service, requestTimeout time.Duration) *gin.Engine {
+	problem.UseRequestFieldNames()
+
+	r := gin.New()
+	r.HandleMethodNotAllowed = true
+	// 請求 ID 最先分配，使恢復 panic 時的錯誤響應也帶上它
+	r.Use(middlewares.RequestID(), gin.Logger(), gin.CustomRecovery(func(c *gin.Context, _ interface{}) {
+		problem.Write(c, problem.New(http.StatusInternalServerError, "internal_error", "an unexpected error occurred"))
+	}))
+	r.Use(middlewares.Timeout(requestTimeout))
+	r.NoRoute(func(c *gin.Context) {
+		problem.Write(c, problem.New(http.StatusNotFound, "route_not_found", "no route matches the requested path"))
+	})
+	r.NoMethod(func(c *gin.Context) {
+		problem.Write(c, problem.New(http.StatusMethodNotAllowed, "method_not_allowed", "the requested method is not supported for this path"))
+	})
+
+	authMiddleware := middlewares.AuthMiddleware(jwtService, userService, tokenService)
+	optionalAuth := middlewares.OptionalAuthMiddleware(jwtService, userService, tokenService)
 
 	// API 路由
 	api := r.Group("/api/v1")
 	{
 		// 用戶相關路由
-		api.POST("/register", userHandler.Register)
-		api.POST("/login", userHandler.Login)
+		api.POST("/register", h.User.Register)
+		api.POST("/login", h.User.Login)
+		api.POST("/verify-email", h.User.VerifyEmail)
+		api.GET("/exports/:id/download", h.Export.DownloadExport) // 由簽名鏈接授權
+
+		// 作者公開資料
+		users := api.Group("/users")
+		{
+			users.GET("/:username", optionalAuth, h.User.GetPublicProfile)
+			users.GET("/:username/posts", optionalAuth, h.Post.GetAuthorPosts)
+			users.GET("/:username/followers", h.Follow.GetFollowers)
+			users.GET("/:username/following", h.Follow.GetFollowing)
+			users.POST("/:username/follow", authMiddleware, middlewares.RequireScope(token.ScopeProfileWrite), h.Follow.Follow)
+			users.DELETE("/:username/follow", authMiddleware, middlewares.RequireScope(token.ScopeProfileWrite), h.Follow.Unfollow)
+		}
 
 		// 文章相關路由
 		posts := api.Group("/posts")
 		{
-			posts.GET("", postHandler.GetPosts)
-			posts.GET("/:id", postHandler.GetPost)
+			posts.GET("", optionalAuth, h.Post.GetPosts)
+			posts.GET("/:id", optionalAuth, h.Post.GetPost)
+			posts.GET("/:id/reactions", h.Reaction.GetReactions)
+			posts.GET("/:id/analytics", authMiddleware, middlewares.RequireScope(token.ScopePostsRead), h.Analytics.GetPostAnalytics)
 
 			// 需要認證的路由
 			authorized := posts.Group("/")
-			authorized.Use(middlewares.AuthMiddleware(jwtService, userService))
+			authorized.Use(authMiddleware, middlewares.RequireScope(token.ScopePostsWrite))
 			{
-				authorized.POST("", postHandler.CreatePost)
-				authorized.PUT("/:id", postHandler.UpdatePost)
-				authorized.DELETE("/:id", postHandler.DeletePost)
+				authorized.POST("", h.Post.CreatePost)
+				authorized.PUT("/:id", h.Post.UpdatePost)
+				authorized.PATCH("/:id", h.Post.PatchPost)
+				authorized.DELETE("/:id", h.Post.DeletePost)
+				authorized.PUT("/:id/reactions/:type", h.Reaction.React)
+				authorized.DELETE("/:id/reactions/:type", h.Reaction.Unreact)
+				authorized.PUT("/:id/bookmark", h.Bookmark.SaveBookmark)
+				authorized.DELETE("/:id/bookmark", h.Bookmark.DeleteBookmark)
 			}
 		}
 
-		// 用戶認證路由
+		// 用戶認證路由，每個路由要求相應的權限範圍
 		authorized := api.Group("/")
-		authorized.Use(middlewares.AuthMiddleware(jwtService, userService))
+		authorized.Use(authMiddleware)
 		{
-			authorized.GET("/profile", userHandler.GetProfile)
-			authorized.POST("/change-password", userHandler.ChangePassword)
+			authorized.GET("/profile", middlewares.RequireScope(token.ScopeProfileRead), h.User.GetProfile)
+			authorized.PATCH("/profile", middlewares.RequireScope(token.ScopeProfileWrite), h.User.UpdateProfile)
+			authorized.PUT("/profile/avatar", middlewares.RequireScope(token.ScopeProfileWrite), h.Avatar.UploadAvatar)
+			authorized.DELETE("/profile/avatar", middlewares.RequireScope(token.ScopeProfileWrite), h.Avatar.DeleteAvatar)
+			authorized.DELETE("/account", middlewares.RequireScope(token.ScopeProfileWrite), h.User.DeleteAccount)
+			authorized.POST("/account/export", middlewares.RequireScope(token.ScopeProfileRead), h.Export.RequestExport)
+			authorized.GET("/account/export/:id", middlewares.RequireScope(token.ScopeProfileRead), h.Export.GetExport)
+			authorized.POST("/change-password", middlewares.RequireScope(token.ScopeProfileWrite), h.User.ChangePassword)
+			authorized.GET("/feed", middlewares.RequireScope(token.ScopePostsRead), h.Post.GetFeed)
+			authorized.GET("/bookmarks", middlewares.RequireScope(token.ScopePostsRead), h.Bookmark.ListBookmarks)
+			authorized.GET("/bookmarks/folders", middlewares.RequireScope(token.ScopePostsRead), h.Bookmark.ListBookmarkFolders)
+
+			// 個人訪問令牌管理
+			tokens := authorized.Group("/tokens")
+			tokens.Use(middlewares.RequireScope(token.ScopeTokensManage))
+			{
+				tokens.POST("", h.Token.CreateToken)
+				tokens.GET("", h.Token.ListTokens)
+				tokens.DELETE("/:id", h.Token.RevokeToken)
+			}
 		}
 	}
 
+	// 頭像文件，URL 包含內容哈希，可以長期緩存
+	r.GET(domainUser.UploadedAvatarPath+":file", h.Avatar.GetUploadedAvatar)
+	r.GET(domainUser.IdenticonPath+":file", h.Avatar.GetIdenticon)
+
+	// 訂閱源，位於 API 版本之外以便訂閱閱讀器使用穩定的地址
+	r.GET("/feed.rss", h.Syndication.GetSiteRSS)
+	r.GET("/feed.atom", h.Syndication.GetSiteAtom)
+	r.GET("/feed.json", h.Syndication.GetSiteJSONFeed)
+	r.GET("/users/:username/feed.rss", h.Syndication.GetAuthorRSS)
+	r.GET("/users/:username/feed.atom", h.Syndication.GetAuthorAtom)
+	r.GET("/users/:username/feed.json", h.Syndication.GetAuthorJSONFeed)
+	r.GET("/tags/:tag/feed.rss", h.Syndication.GetTagRSS)
+	r.GET("/tags/:tag/feed.atom", h.Syndication.GetTagAtom)
+	r.GET("/tags/:tag/feed.json", h.Syndication.GetTagJSONFeed)
+
+	// 網站地圖與 robots.txt
+	r.GET("/sitemap.xml", h.Sitemap.GetSitemap)
+	r.GET("/sitemaps/:file", h.Sitemap.GetSitemapPart)
+	r.GET("/robots.txt", h.Sitemap.GetRobots)
+
+	// JWT 公鑰，位於 API 版本之外以符合 well-known 約定
+	r.GET("/.well-known/jwks.json", h.JWKS.GetJWKS)
+
 	// Swagger 文檔路由
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
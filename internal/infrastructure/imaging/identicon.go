@@ -0,0 +1,47 @@
+package imaging
+
+import (
+	"crypto/sha256"
+	"image"
+	"image/color"
+)
+
+// identiconGrid 身份圖標的網格大小，左右對稱
+const identiconGrid = 5
+
+// Identicon 根據種子生成確定性的對稱身份圖標 PNG，相同的種子總是生成相同的圖片
+func Identicon(seed string, size int) ([]byte, error) {
+	sum := sha256.Sum256([]byte(seed))
+
+	// 使用哈希的前三個字節作為前景色，並限制亮度範圍以保證對比度
+	fg := color.NRGBA{R: 48 + sum[0]%160, G: 48 + sum[1]%160, B: 48 + sum[2]%160, A: 255}
+	bg := color.NRGBA{R: 240, G: 240, B: 240, A: 255}
+
+	// 只需決定左側三列，右側兩列鏡像
+	var cells [identiconGrid][identiconGrid]bool
+	bit := 0
+	for x := 0; x < (identiconGrid+1)/2; x++ {
+		for y := 0; y < identiconGrid; y++ {
+			on := sum[3+bit/8]&(1<<(bit%8)) != 0
+			cells[y][x] = on
+			cells[y][identiconGrid-1-x] = on
+			bit++
+		}
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	margin := size / 10
+	cell := float64(size-2*margin) / identiconGrid
+	for py := 0; py < size; py++ {
+		for px := 0; px < size; px++ {
+			c := bg
+			gx := int(float64(px-margin) / cell)
+			gy := int(float64(py-margin) / cell)
+			if px >= margin && py >= margin && gx < identiconGrid && gy < identiconGrid && cells[gy][gx] {
+				c = fg
+			}
+			img.SetNRGBA(px, py, c)
+		}
+	}
+	return EncodePNG(img)
+}
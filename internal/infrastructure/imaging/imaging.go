@@ -0,0 +1,129 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif"  // 註冊 GIF 解碼器
+	_ "image/jpeg" // 註冊 JPEG 解碼器
+	"image/png"
+	"io"
+)
+
+// MaxDimension 允許上傳的圖片最大邊長，避免解壓炸彈佔用過多內存
+const MaxDimension = 4096
+
+// 定義可能的錯誤
+var (
+	ErrInvalidImage  = errors.New("invalid image")
+	ErrImageTooLarge = errors.New("image dimensions are too large")
+)
+
+// SquareThumbnails 解碼圖片，居中裁剪為正方形並縮放為各個尺寸的 PNG
+func SquareThumbnails(r io.Reader, sizes []int) (map[int][]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width > MaxDimension || cfg.Height > MaxDimension {
+		return nil, ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	square := cropSquare(src)
+
+	out := make(map[int][]byte, len(sizes))
+	for _, size := range sizes {
+		encoded, err := EncodePNG(resize(square, size))
+		if err != nil {
+			return nil, err
+		}
+		out[size] = encoded
+	}
+	return out, nil
+}
+
+// EncodePNG 將圖片編碼為 PNG
+func EncodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// cropSquare 以圖片中心裁剪出最大的正方形區域
+func cropSquare(src image.Image) image.Image {
+	b := src.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+	rect := image.Rect(x0, y0, x0+side, y0+side)
+
+	if sub, ok := src.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(rect)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			dst.Set(x, y, src.At(x0+x, y0+y))
+		}
+	}
+	return dst
+}
+
+// resize 使用區域平均將正方形圖片縮放為 size×size，放大時退化為最近鄰採樣
+func resize(src image.Image, size int) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	scaleX := float64(b.Dx()) / float64(size)
+	scaleY := float64(b.Dy()) / float64(size)
+
+	for dy := 0; dy < size; dy++ {
+		sy0 := b.Min.Y + int(float64(dy)*scaleY)
+		sy1 := b.Min.Y + int(float64(dy+1)*scaleY)
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+		for dx := 0; dx < size; dx++ {
+			sx0 := b.Min.X + int(float64(dx)*scaleX)
+			sx1 := b.Min.X + int(float64(dx+1)*scaleX)
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+
+			var r, g, bl, a, n uint64
+			for y := sy0; y < sy1; y++ {
+				for x := sx0; x < sx1; x++ {
+					c := color.NRGBA64Model.Convert(src.At(x, y)).(color.NRGBA64)
+					r += uint64(c.R)
+					g += uint64(c.G)
+					bl += uint64(c.B)
+					a += uint64(c.A)
+					n++
+				}
+			}
+			dst.SetNRGBA(dx, dy, color.NRGBA{
+				R: uint8((r / n) >> 8),
+				G: uint8((g / n) >> 8),
+				B: uint8((bl / n) >> 8),
+				A: uint8((a / n) >> 8),
+			})
+		}
+	}
+	return dst
+}
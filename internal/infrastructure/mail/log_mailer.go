@@ -0,0 +1,17 @@
+package mail
+
+import "log"
+
+// LogMailer 將郵件內容寫入日誌，用於開發環境或尚未配置郵件服務時
+type LogMailer struct{}
+
+// NewLogMailer 創建一個新的 LogMailer 實例
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send 將郵件寫入日誌
+func (m *LogMailer) Send(to, subject, body string) error {
+	log.Printf("Mail to %s: %s\n%s", to, subject, body)
+	return nil
+}
@@ -0,0 +1,372 @@
+package migrate
+
+import (
+	"blog-api/internal/infrastructure/postgres/migrations"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestLoadSortsAndPairsMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_add_posts.up.sql":     {Data: []byte("CREATE TABLE posts ();")},
+		"0002_add_posts.down.sql":   {Data: []byte("DROP TABLE posts;")},
+		"0001_add_users.up.sql":     {Data: []byte("CREATE TABLE users ();")},
+		"0010_add_index.up.sql":     {Data: []byte("CREATE INDEX i ON posts (id);")},
+		"README.md":                 {Data: []byte("ignored")},
+		"0003_Bad-Name.up.sql":      {Data: []byte("ignored")},
+		"migrations.go":             {Data: []byte("package migrations")},
+		"0001_add_users.down.sql":   {Data: []byte("DROP TABLE users;")},
+		"nested/0004_nested.up.sql": {Data: []byte("ignored")},
+	}
+
+	got, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := []Migration{
+		{Version: 1, Name: "add_users", Up: "CREATE TABLE users ();", Down: "DROP TABLE users;"},
+		{Version: 2, Name: "add_posts", Up: "CREATE TABLE posts ();", Down: "DROP TABLE posts;"},
+		{Version: 10, Name: "add_index", Up: "CREATE INDEX i ON posts (id);"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Load returned %d migrations, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("migration %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLoadRejectsInvalidSets(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"conflicting names": {
+			"0001_a.up.sql": {Data: []byte("SELECT 1;")},
+			"0001_b.up.sql": {Data: []byte("SELECT 1;")},
+		},
+		"missing up script": {
+			"0001_a.down.sql": {Data: []byte("SELECT 1;")},
+		},
+		"empty up script": {
+			"0001_a.up.sql": {Data: []byte("  \n")},
+		},
+	}
+	for name, fsys := range tests {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: Load succeeded, want error", name)
+		}
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	all, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(all) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+	for i, m := range all {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration %s has version %d, want %d", m.Name, m.Version, i+1)
+		}
+		if strings.TrimSpace(m.Down) == "" {
+			t.Errorf("migration %04d_%s has no down script", m.Version, m.Name)
+		}
+	}
+}
+
+func TestCreateUsesNextVersion(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"0001_first.up.sql", "0001_first.down.sql", "0002_second.up.sql"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1;"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths, err := Create(dir, "third_change")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	want := []string{filepath.Join(dir, "0003_third_change.up.sql"), filepath.Join(dir, "0003_third_change.down.sql")}
+	if len(paths) != 2 || paths[0] != want[0] || paths[1] != want[1] {
+		t.Errorf("Create = %q, want %q", paths, want)
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("created file: %v", err)
+		}
+	}
+
+	if _, err := Create(dir, "Bad Name"); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("Create with invalid name = %v, want ErrInvalidName", err)
+	}
+}
+
+func TestUpAppliesPendingMigrationsInOrder(t *testing.T) {
+	db, state := openFakeDB(t)
+	state.applied[1] = time.Now()
+	m := &Migrator{db: db, migrations: []Migration{
+		{Version: 1, Name: "one", Up: "UP 1", Down: "DOWN 1"},
+		{Version: 2, Name: "two", Up: "UP 2", Down: "DOWN 2"},
+		{Version: 3, Name: "three", Up: "UP 3", Down: "DOWN 3"},
+	}}
+
+	applied, err := m.Up(context.Background())
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(applied) != 2 || applied[0].Version != 2 || applied[1].Version != 3 {
+		t.Errorf("Up applied %+v, want versions 2 and 3", applied)
+	}
+	if got := state.scripts(); strings.Join(got, ",") != "UP 2,UP 3" {
+		t.Errorf("executed scripts %q, want UP 2 then UP 3", got)
+	}
+	if !state.isApplied(2) || !state.isApplied(3) {
+		t.Error("versions 2 and 3 were not recorded in schema_migrations")
+	}
+
+	// 再次執行時沒有待應用的遷移
+	applied, err = m.Up(context.Background())
+	if err != nil || len(applied) != 0 {
+		t.Errorf("second Up = %+v, %v; want nothing applied", applied, err)
+	}
+}
+
+func TestUpStopsAtFailedMigration(t *testing.T) {
+	db, state := openFakeDB(t)
+	m := &Migrator{db: db, migrations: []Migration{
+		{Version: 1, Name: "one", Up: "UP 1"},
+		{Version: 2, Name: "two", Up: "FAIL"},
+		{Version: 3, Name: "three", Up: "UP 3"},
+	}}
+
+	applied, err := m.Up(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "migration 2_two") {
+		t.Fatalf("Up error = %v, want failure of migration 2", err)
+	}
+	if len(applied) != 1 || !state.isApplied(1) {
+		t.Errorf("Up applied %+v, want only version 1", applied)
+	}
+	// 失敗的遷移在事務中回滾，不會被記錄為已應用
+	if state.isApplied(2) || state.isApplied(3) {
+		t.Error("failed or later migrations were recorded as applied")
+	}
+}
+
+func TestUpRejectsUnknownVersions(t *testing.T) {
+	db, state := openFakeDB(t)
+	state.applied[9] = time.Now()
+	m := &Migrator{db: db, migrations: []Migration{{Version: 1, Name: "one", Up: "UP 1"}}}
+
+	if _, err := m.Up(context.Background()); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("Up error = %v, want ErrUnknownVersion", err)
+	}
+	if len(state.scripts()) != 0 {
+		t.Errorf("executed scripts %q, want none", state.scripts())
+	}
+}
+
+func TestDownRevertsMostRecent(t *testing.T) {
+	db, state := openFakeDB(t)
+	state.applied[1] = time.Now()
+	state.applied[2] = time.Now()
+	m := &Migrator{db: db, migrations: []Migration{
+		{Version: 1, Name: "one", Up: "UP 1", Down: "DOWN 1"},
+		{Version: 2, Name: "two", Up: "UP 2", Down: "DOWN 2"},
+		{Version: 3, Name: "three", Up: "UP 3", Down: "DOWN 3"},
+	}}
+
+	reverted, err := m.Down(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if len(reverted) != 1 || reverted[0].Version != 2 {
+		t.Errorf("Down reverted %+v, want version 2", reverted)
+	}
+	if state.isApplied(2) || !state.isApplied(1) {
+		t.Error("Down did not remove only version 2 from schema_migrations")
+	}
+	if got := state.scripts(); strings.Join(got, ",") != "DOWN 2" {
+		t.Errorf("executed scripts %q, want DOWN 2", got)
+	}
+}
+
+func TestDownWithoutScriptFails(t *testing.T) {
+	db, state := openFakeDB(t)
+	state.applied[1] = time.Now()
+	m := &Migrator{db: db, migrations: []Migration{{Version: 1, Name: "one", Up: "UP 1"}}}
+
+	if _, err := m.Down(context.Background(), 1); !errors.Is(err, ErrNoDownMigration) {
+		t.Errorf("Down error = %v, want ErrNoDownMigration", err)
+	}
+	if !state.isApplied(1) {
+		t.Error("version 1 was removed without running a down script")
+	}
+}
+
+// fakeState 在內存中模擬 schema_migrations 表，並記錄執行過的遷移腳本
+type fakeState struct {
+	mu       sync.Mutex
+	applied  map[int64]time.Time
+	executed []string
+}
+
+func (s *fakeState) scripts() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.executed...)
+}
+
+func (s *fakeState) isApplied(version int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.applied[version]
+	return ok
+}
+
+// fakeDriver 只理解 Migrator 使用的語句，其他語句視為遷移腳本；腳本為 FAIL 時返回錯誤
+// 事務中的修改在提交時才生效，以檢查失敗的遷移會被回滾
+type fakeDriver struct {
+	state *fakeState
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{state: d.state}, nil
+}
+
+type fakeConn struct {
+	state *fakeState
+	tx    *fakeTx
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.tx = &fakeTx{conn: c}
+	return c.tx, nil
+}
+
+type fakeTx struct {
+	conn *fakeConn
+	ops  []func(*fakeState)
+}
+
+func (t *fakeTx) Commit() error {
+	t.conn.state.mu.Lock()
+	defer t.conn.state.mu.Unlock()
+	for _, op := range t.ops {
+		op(t.conn.state)
+	}
+	t.conn.tx = nil
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.conn.tx = nil
+	return nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	q := strings.TrimSpace(s.query)
+	var op func(*fakeState)
+	switch {
+	case strings.HasPrefix(q, "SELECT pg_advisory"), strings.HasPrefix(q, "CREATE TABLE IF NOT EXISTS schema_migrations"):
+		return driver.RowsAffected(0), nil
+	case strings.HasPrefix(q, "INSERT INTO schema_migrations"):
+		version := args[0].(int64)
+		op = func(st *fakeState) { st.applied[version] = time.Now() }
+	case strings.HasPrefix(q, "DELETE FROM schema_migrations"):
+		version := args[0].(int64)
+		op = func(st *fakeState) { delete(st.applied, version) }
+	case q == "FAIL":
+		return nil, errors.New("syntax error")
+	default:
+		op = func(st *fakeState) { st.executed = append(st.executed, q) }
+	}
+
+	if s.conn.tx != nil {
+		s.conn.tx.ops = append(s.conn.tx.ops, op)
+	} else {
+		s.conn.state.mu.Lock()
+		op(s.conn.state)
+		s.conn.state.mu.Unlock()
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	if !strings.HasPrefix(strings.TrimSpace(s.query), "SELECT version, applied_at FROM schema_migrations") {
+		return nil, errors.New("unexpected query: " + s.query)
+	}
+	s.conn.state.mu.Lock()
+	defer s.conn.state.mu.Unlock()
+	rows := &fakeRows{}
+	for version, at := range s.conn.state.applied {
+		rows.values = append(rows.values, []driver.Value{version, at})
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	values [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"version", "applied_at"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// fakeDriverSeq 為每個測試註冊的驅動生成唯一名稱
+var fakeDriverSeq struct {
+	sync.Mutex
+	n int
+}
+
+// openFakeDB 打開一個使用 fakeDriver 的數據庫連接
+func openFakeDB(t *testing.T) (*sql.DB, *fakeState) {
+	t.Helper()
+	fakeDriverSeq.Lock()
+	fakeDriverSeq.n++
+	name := "fake-migrate-" + strconv.Itoa(fakeDriverSeq.n)
+	fakeDriverSeq.Unlock()
+
+	state := &fakeState{applied: make(map[int64]time.Time)}
+	sql.Register(name, fakeDriver{state: state})
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, state
+}
@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"blog-api/internal/domain/analytics"
+	"blog-api/internal/domain/post"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AnalyticsRepository 實現 analytics.Repository 接口
+type AnalyticsRepository struct {
+	db *gorm.DB
+}
+
+// NewAnalyticsRepository 創建一個新的 AnalyticsRepository 實例
+func NewAnalyticsRepository(db *gorm.DB) *AnalyticsRepository {
+	return &AnalyticsRepository{db: db}
+}
+
+// Increment 在一個事務中批量累加瀏覽次數
+// 計數在內存中緩衝期間文章可能已被刪除，這些文章的計數會被跳過，否則外鍵錯誤會使整批計數反復重試
+func (r *AnalyticsRepository) Increment(ctx context.Context, daily []analytics.DailyCount, referrers []analytics.ReferrerCount) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		existing, err := lockExistingPosts(tx, daily, referrers)
+		if err != nil {
+			return err
+		}
+		daily = filterDaily(daily, existing)
+		referrers = filterReferrers(referrers, existing)
+
+		if len(daily) > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "post_id"}, {Name: "day"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"views": gorm.Expr("post_view_daily.views + EXCLUDED.views")}),
+			}).Create(&daily).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(referrers) > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "post_id"}, {Name: "day"}, {Name: "referrer"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"views": gorm.Expr("post_view_referrers.views + EXCLUDED.views")}),
+			}).Create(&referrers).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// lockExistingPosts 返回計數涉及的文章中仍然存在的文章，並鎖定它們直到事務結束，防止寫入期間被刪除
+func lockExistingPosts(tx *gorm.DB, daily []analytics.DailyCount, referrers []analytics.ReferrerCount) (map[uint]bool, error) {
+	existing := make(map[uint]bool)
+	seen := make(map[uint]bool)
+	var ids []uint
+	for _, d := range daily {
+		if !seen[d.PostID] {
+			seen[d.PostID] = true
+			ids = append(ids, d.PostID)
+		}
+	}
+	for _, c := range referrers {
+		if !seen[c.PostID] {
+			seen[c.PostID] = true
+			ids = append(ids, c.PostID)
+		}
+	}
+	if len(ids) == 0 {
+		return existing, nil
+	}
+
+	var found []uint
+	err := tx.Model(&post.Post{}).Clauses(clause.Locking{Strength: "KEY SHARE"}).
+		Where("id IN ?", ids).Pluck("id", &found).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range found {
+		existing[id] = true
+	}
+	return existing, nil
+}
+
+// filterDaily 去掉已刪除文章的每日計數
+func filterDaily(daily []analytics.DailyCount, existing map[uint]bool) []analytics.DailyCount {
+	kept := daily[:0:0]
+	for _, d := range daily {
+		if existing[d.PostID] {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+// filterReferrers 去掉已刪除文章的來源計數
+func filterReferrers(referrers []analytics.ReferrerCount, existing map[uint]bool) []analytics.ReferrerCount {
+	kept := referrers[:0:0]
+	for _, c := range referrers {
+		if existing[c.PostID] {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// FindDaily 獲取文章在日期範圍內（包含兩端）每天的瀏覽次數
+func (r *AnalyticsRepository) FindDaily(ctx context.Context, postID uint, from, to time.Time) ([]analytics.DailyCount, error) {
+	var counts []analytics.DailyCount
+	err := conn(ctx, r.db).Where("post_id = ? AND day BETWEEN ? AND ?", postID, from, to).Order("day").Find(&counts).Error
+	return counts, err
+}
+
+// FindReferrers 獲取文章在日期範圍內瀏覽次數最多的來源
+func (r *AnalyticsRepository) FindReferrers(ctx context.Context, postID uint, from, to time.Time, limit int) ([]analytics.ReferrerViews, error) {
+	var referrers []analytics.ReferrerViews
+	q := conn(ctx, r.db).Model(&analytics.ReferrerCount{}).
+		Select("referrer, SUM(views) AS views").
+		Where("post_id = ? AND day BETWEEN ? AND ?", postID, from, to).
+		Group("referrer").Order("views DESC, referrer")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	err := q.Scan(&referrers).Error
+	return referrers, err
+}
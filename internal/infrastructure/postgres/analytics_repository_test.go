@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"blog-api/internal/domain/analytics"
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIncrementSkipsDeletedPosts(t *testing.T) {
+	db, d := newRecordingDB(t)
+	// 只有文章 10 仍然存在，文章 20 在計數緩衝期間被刪除
+	d.respond = func(query string) ([]string, [][]driver.Value) {
+		if strings.Contains(query, `FROM "posts"`) {
+			return []string{"id"}, [][]driver.Value{{int64(10)}}
+		}
+		return nil, nil
+	}
+	day := time.Date(2024, 10, 20, 0, 0, 0, 0, time.UTC)
+
+	err := NewAnalyticsRepository(db).Increment(context.Background(),
+		[]analytics.DailyCount{{PostID: 10, Day: day, Views: 1}, {PostID: 20, Day: day, Views: 3}},
+		[]analytics.ReferrerCount{{PostID: 10, Day: day, Referrer: "direct", Views: 1}, {PostID: 20, Day: day, Referrer: "direct", Views: 3}})
+	if err != nil {
+		t.Fatalf("Increment: %v", err)
+	}
+
+	var locked bool
+	inserts := 0
+	for i, s := range d.log() {
+		if strings.Contains(s, `FROM "posts"`) {
+			locked = strings.Contains(s, "FOR KEY SHARE")
+		}
+		if !strings.HasPrefix(s, "INSERT") {
+			continue
+		}
+		inserts++
+		for _, arg := range d.args[i] {
+			if arg == int64(20) {
+				t.Errorf("%s inserted counts for the deleted post: %v", s, d.args[i])
+			}
+		}
+	}
+	if !locked {
+		t.Errorf("statements = %q, want the existing posts locked with FOR KEY SHARE", d.log())
+	}
+	if inserts != 2 {
+		t.Errorf("statements = %q, want daily and referrer inserts", d.log())
+	}
+}
+
+func TestIncrementWithOnlyDeletedPosts(t *testing.T) {
+	db, d := newRecordingDB(t)
+	day := time.Date(2024, 10, 20, 0, 0, 0, 0, time.UTC)
+
+	err := NewAnalyticsRepository(db).Increment(context.Background(),
+		[]analytics.DailyCount{{PostID: 20, Day: day, Views: 3}},
+		[]analytics.ReferrerCount{{PostID: 20, Day: day, Referrer: "direct", Views: 3}})
+	if err != nil {
+		t.Fatalf("Increment: %v", err)
+	}
+	for _, s := range d.log() {
+		if strings.HasPrefix(s, "INSERT") {
+			t.Errorf("statements = %q, want no inserts", d.log())
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"blog-api/internal/domain/post"
+	"blog-api/internal/domain/user"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// AuthorRepository 實現 post.AuthorRepository 接口，從用戶表中讀取作者摘要
+type AuthorRepository struct {
+	db *gorm.DB
+}
+
+// NewAuthorRepository 創建一個新的 AuthorRepository 實例
+func NewAuthorRepository(db *gorm.DB) *AuthorRepository {
+	return &AuthorRepository{db: db}
+}
+
+// authorColumns 作者摘要只讀取公開字段
+var authorColumns = []string{"id", "username", "first_name", "last_name", "avatar_hash"}
+
+// FindByIDs 批量查找作者摘要，避免在文章列表中逐條查詢
+func (r *AuthorRepository) FindByIDs(ctx context.Context, ids []uint) (map[uint]post.Author, error) {
+	authors := make(map[uint]post.Author, len(ids))
+	if len(ids) == 0 {
+		return authors, nil
+	}
+
+	var users []user.User
+	if err := conn(ctx, r.db).Select(authorColumns).Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		authors[u.ID] = toAuthor(u)
+	}
+	return authors, nil
+}
+
+// FindByUsername 根據用戶名查找仍處於啟用狀態的作者
+func (r *AuthorRepository) FindByUsername(ctx context.Context, username string) (*post.Author, error) {
+	var u user.User
+	err := conn(ctx, r.db).Select(authorColumns).Where("username = ? AND is_active = ?", username, true).First(&u).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, post.ErrAuthorNotFound
+		}
+		return nil, err
+	}
+	a := toAuthor(u)
+	return &a, nil
+}
+
+// toAuthor 將用戶記錄轉換為作者摘要
+func toAuthor(u user.User) post.Author {
+	return post.Author{
+		ID:        u.ID,
+		Username:  u.Username,
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		AvatarURL: user.AvatarURL(u.Username, u.AvatarHash),
+	}
+}
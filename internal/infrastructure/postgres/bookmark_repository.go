@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"blog-api/internal/domain/bookmark"
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BookmarkRepository 實現 bookmark.Repository 接口
+type BookmarkRepository struct {
+	db *gorm.DB
+}
+
+// NewBookmarkRepository 創建一個新的 BookmarkRepository 實例
+func NewBookmarkRepository(db *gorm.DB) *BookmarkRepository {
+	return &BookmarkRepository{db: db}
+}
+
+// Save 保存收藏，同一用戶重複收藏同一文章時更新收藏夾和備註
+func (r *BookmarkRepository) Save(ctx context.Context, b *bookmark.Bookmark) error {
+	return conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "post_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"folder", "note", "updated_at"}),
+	}).Create(b).Error
+}
+
+// Delete 刪除收藏
+func (r *BookmarkRepository) Delete(ctx context.Context, userID, postID uint) error {
+	result := conn(ctx, r.db).Where("user_id = ? AND post_id = ?", userID, postID).Delete(&bookmark.Bookmark{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return bookmark.ErrBookmarkNotFound
+	}
+	return nil
+}
+
+// FindByUserID 獲取用戶的收藏列表，按收藏時間倒序，beforeID 為 0 時從最新的收藏開始
+func (r *BookmarkRepository) FindByUserID(ctx context.Context, userID uint, folder *string, beforeID uint, limit int) ([]bookmark.Bookmark, error) {
+	var bookmarks []bookmark.Bookmark
+	q := conn(ctx, r.db).Where("user_id = ?", userID)
+	if folder != nil {
+		q = q.Where("folder = ?", *folder)
+	}
+	if beforeID > 0 {
+		q = q.Where("id < ?", beforeID)
+	}
+	err := q.Order("id DESC").Limit(limit).Find(&bookmarks).Error
+	return bookmarks, err
+}
+
+// FindFolders 獲取用戶的收藏夾及其中的收藏數量
+func (r *BookmarkRepository) FindFolders(ctx context.Context, userID uint) ([]bookmark.Folder, error) {
+	var folders []bookmark.Folder
+	err := conn(ctx, r.db).Model(&bookmark.Bookmark{}).
+		Select("folder AS name, COUNT(*) AS count").
+		Where("user_id = ?", userID).
+		Group("folder").Order("folder").
+		Scan(&folders).Error
+	return folders, err
+}
+
+// FindBookmarkedPostIDs 批量檢查用戶收藏了哪些文章
+func (r *BookmarkRepository) FindBookmarkedPostIDs(ctx context.Context, userID uint, postIDs []uint) (map[uint]bool, error) {
+	bookmarked := make(map[uint]bool, len(postIDs))
+	if len(postIDs) == 0 {
+		return bookmarked, nil
+	}
+
+	var ids []uint
+	err := conn(ctx, r.db).Model(&bookmark.Bookmark{}).
+		Where("user_id = ? AND post_id IN ?", userID, postIDs).
+		Pluck("post_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		bookmarked[id] = true
+	}
+	return bookmarked, nil
+}
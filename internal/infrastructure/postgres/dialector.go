@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"fmt"
+
+	pgDriver "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dialector 包裝 GORM 的 PostgreSQL 方言
+// 驅動的錯誤轉換會丟棄原始的 *pgconn.PgError，包裝後兩者都能通過 errors.Is/As 取得，
+// 倉庫可以據此讀取約束名稱等細節
+type dialector struct {
+	*pgDriver.Dialector
+}
+
+// Open 根據連接字符串創建 PostgreSQL 方言
+func Open(dsn string) gorm.Dialector {
+	return dialector{pgDriver.Open(dsn).(*pgDriver.Dialector)}
+}
+
+// Translate 將數據庫錯誤轉換為 GORM 的通用錯誤，同時保留原始錯誤
+func (d dialector) Translate(err error) error {
+	translated := d.Dialector.Translate(err)
+	if translated == err {
+		return err
+	}
+	return fmt.Errorf("%w: %w", translated, err)
+}
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"blog-api/internal/domain/user"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+func TestDialectorTranslateKeepsPgError(t *testing.T) {
+	d := Open("host=localhost")
+	translator, ok := d.(gorm.ErrorTranslator)
+	if !ok {
+		t.Fatal("dialector does not implement gorm.ErrorTranslator")
+	}
+
+	pgErr := &pgconn.PgError{Code: "23505", ConstraintName: "idx_users_email"}
+	err := translator.Translate(pgErr)
+	var got *pgconn.PgError
+	if !errors.Is(err, gorm.ErrDuplicatedKey) || !errors.As(err, &got) || got != pgErr {
+		t.Errorf("Translate = %v, want gorm.ErrDuplicatedKey wrapping the original error", err)
+	}
+
+	other := errors.New("connection reset")
+	if err := translator.Translate(other); err != other {
+		t.Errorf("Translate(%v) = %v, want it unchanged", other, err)
+	}
+}
+
+func TestDuplicateUserError(t *testing.T) {
+	translate := Open("host=localhost").(gorm.ErrorTranslator).Translate
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"username", translate(&pgconn.PgError{Code: "23505", ConstraintName: "idx_users_username"}), user.ErrDuplicateUsername},
+		{"email", translate(&pgconn.PgError{Code: "23505", ConstraintName: "idx_users_email"}), user.ErrDuplicateEmail},
+		{"other constraint", translate(&pgconn.PgError{Code: "23505", ConstraintName: "users_pkey"}), gorm.ErrDuplicatedKey},
+		{"not a duplicate", translate(&pgconn.PgError{Code: "23503", ConstraintName: "idx_users_email"}), gorm.ErrForeignKeyViolated},
+		{"untranslated", gorm.ErrDuplicatedKey, gorm.ErrDuplicatedKey},
+	}
+	for _, tt := range tests {
+		err := duplicateUserError(tt.err)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: duplicateUserError = %v, want %v", tt.name, err, tt.want)
+		}
+		if tt.want != user.ErrDuplicateUsername && tt.want != user.ErrDuplicateEmail &&
+			(errors.Is(err, user.ErrDuplicateUsername) || errors.Is(err, user.ErrDuplicateEmail)) {
+			t.Errorf("%s: duplicateUserError = %v, want no domain error", tt.name, err)
+		}
+	}
+	if duplicateUserError(nil) != nil {
+		t.Error("duplicateUserError(nil) != nil")
+	}
+}
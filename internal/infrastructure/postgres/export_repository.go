@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"blog-api/internal/domain/export"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ExportRepository 實現 export.Repository 接口
+type ExportRepository struct {
+	db *gorm.DB
+}
+
+// NewExportRepository 創建一個新的 ExportRepository 實例
+func NewExportRepository(db *gorm.DB) *ExportRepository {
+	return &ExportRepository{db: db}
+}
+
+// Create 保存新的導出任務
+// 部分唯一索引保證每個用戶只有一個未完成的任務，並發創建時後到的一方失敗
+func (r *ExportRepository) Create(ctx context.Context, job *export.Job) error {
+	if err := conn(ctx, r.db).Create(job).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return export.ErrJobAlreadyRunning
+		}
+		return err
+	}
+	return nil
+}
+
+// FindByID 根據ID查找導出任務
+func (r *ExportRepository) FindByID(ctx context.Context, id uint) (*export.Job, error) {
+	var job export.Job
+	if err := conn(ctx, r.db).First(&job, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, export.ErrJobNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// FindActiveByUserID 查找用戶尚未完成的導出任務
+func (r *ExportRepository) FindActiveByUserID(ctx context.Context, userID uint) (*export.Job, error) {
+	var job export.Job
+	err := conn(ctx, r.db).Where("user_id = ? AND status IN ?", userID, []export.Status{export.StatusPending, export.StatusRunning}).
+		First(&job).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, export.ErrJobNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// FindExpired 查找在指定時間前過期但尚未清理的導出任務
+func (r *ExportRepository) FindExpired(ctx context.Context, before time.Time) ([]export.Job, error) {
+	var jobs []export.Job
+	err := conn(ctx, r.db).Where("status = ? AND expires_at < ?", export.StatusCompleted, before).Find(&jobs).Error
+	return jobs, err
+}
+
+// FindStale 查找在指定時間前創建但仍未完成的導出任務
+func (r *ExportRepository) FindStale(ctx context.Context, before time.Time) ([]export.Job, error) {
+	var jobs []export.Job
+	err := conn(ctx, r.db).Where("status IN ? AND created_at < ?", []export.Status{export.StatusPending, export.StatusRunning}, before).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// FailUnfinished 將仍未完成的任務標記為失敗，任務在此期間已結束時返回 false
+func (r *ExportRepository) FailUnfinished(ctx context.Context, id uint, reason error) (bool, error) {
+	result := conn(ctx, r.db).Model(&export.Job{}).
+		Where("id = ? AND status IN ?", id, []export.Status{export.StatusPending, export.StatusRunning}).
+		UpdateColumns(map[string]interface{}{"status": export.StatusFailed, "error": reason.Error(), "completed_at": time.Now()})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ExpireByUserID 將用戶所有已完成的導出任務標記為已過期
+func (r *ExportRepository) ExpireByUserID(ctx context.Context, userID uint) error {
+	return conn(ctx, r.db).Model(&export.Job{}).
+		Where("user_id = ? AND status = ?", userID, export.StatusCompleted).
+		UpdateColumns(map[string]interface{}{"status": export.StatusExpired, "file_path": ""}).Error
+}
+
+// Update 更新導出任務
+func (r *ExportRepository) Update(ctx context.Context, job *export.Job) error {
+	return conn(ctx, r.db).Save(job).Error
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"blog-api/internal/domain/follow"
+	"blog-api/internal/domain/user"
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FollowRepository 實現 follow.Repository 接口
+type FollowRepository struct {
+	db *gorm.DB
+}
+
+// NewFollowRepository 創建一個新的 FollowRepository 實例
+func NewFollowRepository(db *gorm.DB) *FollowRepository {
+	return &FollowRepository{db: db}
+}
+
+// Create 保存關注關係，重複關注時不做任何操作
+func (r *FollowRepository) Create(ctx context.Context, f *follow.Follow) error {
+	return conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(f).Error
+}
+
+// Delete 刪除關注關係
+func (r *FollowRepository) Delete(ctx context.Context, followerID, followeeID uint) error {
+	return conn(ctx, r.db).Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Delete(&follow.Follow{}).Error
+}
+
+// Exists 檢查關注關係是否存在
+func (r *FollowRepository) Exists(ctx context.Context, followerID, followeeID uint) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&follow.Follow{}).Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Count(&count).Error
+	return count > 0, err
+}
+
+// FindFollowers 獲取關注指定用戶的分頁用戶列表，最新的關注者在前
+func (r *FollowRepository) FindFollowers(ctx context.Context, userID uint, page, pageSize int) ([]user.User, error) {
+	var users []user.User
+	err := conn(ctx, r.db).Joins("JOIN follows ON follows.follower_id = users.id").
+		Where("follows.followee_id = ? AND users.is_active = ?", userID, true).
+		Order("follows.created_at DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&users).Error
+	return users, err
+}
+
+// FindFollowing 獲取指定用戶關注的分頁用戶列表，最新關注的在前
+func (r *FollowRepository) FindFollowing(ctx context.Context, userID uint, page, pageSize int) ([]user.User, error) {
+	var users []user.User
+	err := conn(ctx, r.db).Joins("JOIN follows ON follows.followee_id = users.id").
+		Where("follows.follower_id = ? AND users.is_active = ?", userID, true).
+		Order("follows.created_at DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&users).Error
+	return users, err
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS bookmarks;
+DROP TABLE IF EXISTS post_reactions;
+DROP TABLE IF EXISTS follows;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS post_view_referrers;
+DROP TABLE IF EXISTS post_view_daily;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS post_view_daily (
+    post_id BIGINT NOT NULL,
+    day DATE NOT NULL,
+    views BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (post_id, day)
+);
+
+CREATE TABLE IF NOT EXISTS post_view_referrers (
+    post_id BIGINT NOT NULL,
+    day DATE NOT NULL,
+    referrer VARCHAR(255) NOT NULL,
+    views BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (post_id, day, referrer)
+);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_posts_created_at;
+
+ALTER TABLE post_view_referrers DROP CONSTRAINT IF EXISTS fk_post_view_referrers_post;
+ALTER TABLE post_view_daily DROP CONSTRAINT IF EXISTS fk_post_view_daily_post;
+ALTER TABLE bookmarks DROP CONSTRAINT IF EXISTS fk_bookmarks_user, DROP CONSTRAINT IF EXISTS fk_bookmarks_post;
+ALTER TABLE post_reactions DROP CONSTRAINT IF EXISTS fk_post_reactions_user, DROP CONSTRAINT IF EXISTS fk_post_reactions_post;
+ALTER TABLE follows DROP CONSTRAINT IF EXISTS fk_follows_followee, DROP CONSTRAINT IF EXISTS fk_follows_follower;
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS fk_jobs_user;
+ALTER TABLE access_tokens DROP CONSTRAINT IF EXISTS fk_access_tokens_user;
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS fk_posts_user;
@@ -0,0 +1,45 @@
+-- 沒有作者的文章無法自動處理，需要在遷移之前手動轉移或刪除
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM posts p WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = p.user_id)) THEN
+        RAISE EXCEPTION 'posts reference users that do not exist; reassign or delete them before running this migration';
+    END IF;
+END $$;
+
+-- 其他表中的孤立記錄沒有意義，直接清理
+DELETE FROM access_tokens t WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = t.user_id);
+DELETE FROM jobs j WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = j.user_id);
+DELETE FROM follows f WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = f.follower_id)
+    OR NOT EXISTS (SELECT 1 FROM users u WHERE u.id = f.followee_id);
+DELETE FROM post_reactions r WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = r.user_id)
+    OR NOT EXISTS (SELECT 1 FROM posts p WHERE p.id = r.post_id);
+DELETE FROM bookmarks b WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = b.user_id)
+    OR NOT EXISTS (SELECT 1 FROM posts p WHERE p.id = b.post_id);
+DELETE FROM post_view_daily v WHERE NOT EXISTS (SELECT 1 FROM posts p WHERE p.id = v.post_id);
+DELETE FROM post_view_referrers v WHERE NOT EXISTS (SELECT 1 FROM posts p WHERE p.id = v.post_id);
+
+-- 文章使用 RESTRICT：刪除用戶之前必須按刪除策略處理其文章，數據庫保證不會出現孤立的文章
+ALTER TABLE posts
+    ADD CONSTRAINT fk_posts_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE RESTRICT;
+
+-- 用戶或文章擁有的數據隨之刪除
+ALTER TABLE access_tokens
+    ADD CONSTRAINT fk_access_tokens_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE jobs
+    ADD CONSTRAINT fk_jobs_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE follows
+    ADD CONSTRAINT fk_follows_follower FOREIGN KEY (follower_id) REFERENCES users (id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_follows_followee FOREIGN KEY (followee_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE post_reactions
+    ADD CONSTRAINT fk_post_reactions_post FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_post_reactions_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE bookmarks
+    ADD CONSTRAINT fk_bookmarks_post FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_bookmarks_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE post_view_daily
+    ADD CONSTRAINT fk_post_view_daily_post FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE;
+ALTER TABLE post_view_referrers
+    ADD CONSTRAINT fk_post_view_referrers_post FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE;
+
+-- user_id 的查詢由 idx_posts_user_id_id 覆蓋，這裡補充按發表時間排序和過濾的索引
+CREATE INDEX IF NOT EXISTS idx_posts_created_at ON posts (created_at);
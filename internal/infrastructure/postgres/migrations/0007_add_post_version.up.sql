@@ -0,0 +1,2 @@
+-- 文章版本號，每次更新時遞增，用於檢測並發編輯
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
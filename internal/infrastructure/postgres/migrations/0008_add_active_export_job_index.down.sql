@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_jobs_user_id_active;
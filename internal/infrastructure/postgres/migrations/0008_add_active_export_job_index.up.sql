@@ -0,0 +1,7 @@
+-- 每個用戶同時只能有一個未完成的導出任務，由數據庫保證並發請求不會重複創建
+-- 已有的重複任務只保留最新的一個，其餘標記為失敗
+UPDATE jobs SET status = 'failed', error = 'export was interrupted', completed_at = CURRENT_TIMESTAMP
+WHERE status IN ('pending', 'running')
+    AND id NOT IN (SELECT MAX(id) FROM jobs WHERE status IN ('pending', 'running') GROUP BY user_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_user_id_active ON jobs (user_id) WHERE status IN ('pending', 'running');
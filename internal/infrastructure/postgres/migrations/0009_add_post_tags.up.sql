@@ -0,0 +1,3 @@
+-- 文章標籤，以 JSONB 數組存儲；GIN 索引支持按標籤查詢，例如 tags @> '["go"]'
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]';
+CREATE INDEX IF NOT EXISTS idx_posts_tags ON posts USING GIN (tags jsonb_path_ops);
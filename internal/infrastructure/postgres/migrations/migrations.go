@@ -0,0 +1,12 @@
+// Package migrations 嵌入數據庫結構的版本化 SQL 遷移
+package migrations
+
+import "embed"
+
+// FS 包含所有遷移文件，文件名格式為 <版本>_<名稱>.up.sql / .down.sql
+//
+//go:embed *.sql
+var FS embed.FS
+
+// Dir 是遷移文件在源碼中的目錄，用於 migrate create
+const Dir = "internal/infrastructure/postgres/migrations"
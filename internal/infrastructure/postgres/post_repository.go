@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"blog-api/internal/domain/post"
+	"context"
 
 	"gorm.io/gorm"
 )
@@ -17,17 +18,66 @@ func NewPostRepository(db *gorm.DB) *PostRepository {
 }
 
 // FindAll 獲取分頁的文章列表
-func (r *PostRepository) FindAll(page, pageSize int) ([]post.Post, error) {
+func (r *PostRepository) FindAll(ctx context.Context, page, pageSize int) ([]post.Post, error) {
 	var posts []post.Post
 	offset := (page - 1) * pageSize
-	err := r.db.Order("id DESC").Offset(offset).Limit(pageSize).Find(&posts).Error
+	err := conn(ctx, r.db).Order("id DESC").Offset(offset).Limit(pageSize).Find(&posts).Error
+	return posts, err
+}
+
+// FindByUserID 獲取指定作者的分頁文章列表
+func (r *PostRepository) FindByUserID(ctx context.Context, userID uint, page, pageSize int) ([]post.Post, error) {
+	var posts []post.Post
+	offset := (page - 1) * pageSize
+	err := conn(ctx, r.db).Where("user_id = ?", userID).Order("id DESC").Offset(offset).Limit(pageSize).Find(&posts).Error
+	return posts, err
+}
+
+// FindByTag 獲取帶有指定標籤的分頁文章列表，使用 tags 列上的 GIN 索引
+func (r *PostRepository) FindByTag(ctx context.Context, tag string, page, pageSize int) ([]post.Post, error) {
+	var posts []post.Post
+	offset := (page - 1) * pageSize
+	err := conn(ctx, r.db).Where("tags @> ?::jsonb", post.Tags{tag}).Order("id DESC").Offset(offset).Limit(pageSize).Find(&posts).Error
+	return posts, err
+}
+
+// CountByUserID 統計指定作者的文章數量
+func (r *PostRepository) CountByUserID(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&post.Post{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// FindFeed 獲取關注作者的文章，beforeID 為 0 時從最新的文章開始
+// 對每位關注的作者通過 (user_id, id) 索引最多取 limit 篇，再合併排序，
+// 因此查詢成本隨關注人數線性增長，而不會掃描整張文章表
+func (r *PostRepository) FindFeed(ctx context.Context, followerID, beforeID uint, limit int) ([]post.Post, error) {
+	var posts []post.Post
+	cursor := ""
+	args := []interface{}{}
+	if beforeID > 0 {
+		cursor = " AND posts.id < ?"
+		args = append(args, beforeID)
+	}
+	args = append(args, limit, followerID, limit)
+	err := conn(ctx, r.db).Raw(`
+		SELECT p.* FROM follows f
+		CROSS JOIN LATERAL (
+			SELECT * FROM posts
+			WHERE posts.user_id = f.followee_id`+cursor+`
+			ORDER BY posts.id DESC
+			LIMIT ?
+		) p
+		WHERE f.follower_id = ?
+		ORDER BY p.id DESC
+		LIMIT ?`, args...).Scan(&posts).Error
 	return posts, err
 }
 
 // FindByID 根據ID查找文章
-func (r *PostRepository) FindByID(id uint) (*post.Post, error) {
+func (r *PostRepository) FindByID(ctx context.Context, id uint) (*post.Post, error) {
 	var p post.Post
-	if err := r.db.First(&p, id).Error; err != nil {
+	if err := conn(ctx, r.db).First(&p, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, post.ErrPostNotFound
 		}
@@ -36,17 +86,44 @@ func (r *PostRepository) FindByID(id uint) (*post.Post, error) {
 	return &p, nil
 }
 
+// FindByIDs 批量查找文章，不存在的ID會被忽略
+func (r *PostRepository) FindByIDs(ctx context.Context, ids []uint) ([]post.Post, error) {
+	var posts []post.Post
+	if len(ids) == 0 {
+		return posts, nil
+	}
+	err := conn(ctx, r.db).Where("id IN ?", ids).Find(&posts).Error
+	return posts, err
+}
+
 // Create 創建新文章
-func (r *PostRepository) Create(post *post.Post) error {
-	return r.db.Create(post).Error
+func (r *PostRepository) Create(ctx context.Context, post *post.Post) error {
+	return conn(ctx, r.db).Create(post).Error
 }
 
-// Update 更新現有文章
-func (r *PostRepository) Update(post *post.Post) error {
-	return r.db.Save(post).Error
+// Update 更新現有文章，只有數據庫中的版本與 p.Version 相同時才會更新，成功後版本加一
+// 回應計數由 ReactionRepository 原子地維護，這裡不覆蓋以免丟失並發的計數
+func (r *PostRepository) Update(ctx context.Context, p *post.Post) error {
+	expected := p.Version
+	p.Version++
+	result := conn(ctx, r.db).Model(p).Where("version = ?", expected).
+		Select("*").Omit("reaction_counts").Updates(p)
+	if result.Error != nil || result.RowsAffected == 0 {
+		p.Version = expected
+		if result.Error != nil {
+			return result.Error
+		}
+		return post.ErrVersionConflict
+	}
+	return nil
 }
 
 // Delete 刪除文章
-func (r *PostRepository) Delete(id uint) error {
-	return r.db.Delete(&post.Post{}, id).Error
+func (r *PostRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Delete(&post.Post{}, id).Error
+}
+
+// ReassignAuthor 將一個用戶的所有文章轉移給另一個用戶
+func (r *PostRepository) ReassignAuthor(ctx context.Context, fromUserID, toUserID uint) error {
+	return conn(ctx, r.db).Model(&post.Post{}).Where("user_id = ?", fromUserID).UpdateColumn("user_id", toUserID).Error
 }
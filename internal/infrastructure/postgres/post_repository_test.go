@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"blog-api/internal/domain/post"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFindFeedCursor(t *testing.T) {
+	tests := []struct {
+		name       string
+		beforeID   uint
+		wantCursor bool
+		wantArgs   []driver.Value
+	}{
+		{"first page", 0, false, []driver.Value{int64(20), int64(1), int64(20)}},
+		{"next page", 500, true, []driver.Value{int64(500), int64(20), int64(1), int64(20)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, d := newRecordingDB(t)
+			if _, err := NewPostRepository(db).FindFeed(context.Background(), 1, tt.beforeID, 20); err != nil {
+				t.Fatalf("FindFeed: %v", err)
+			}
+			statements := d.log()
+			if len(statements) != 1 {
+				t.Fatalf("statements = %q, want one query", statements)
+			}
+			if got := strings.Contains(statements[0], "posts.id <"); got != tt.wantCursor {
+				t.Errorf("query has cursor condition = %v, want %v:\n%s", got, tt.wantCursor, statements[0])
+			}
+			if !reflect.DeepEqual(d.args[0], tt.wantArgs) {
+				t.Errorf("args = %v, want %v", d.args[0], tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestFindByTagUsesJSONBContainment(t *testing.T) {
+	db, d := newRecordingDB(t)
+	if _, err := NewPostRepository(db).FindByTag(context.Background(), "go", 1, 20); err != nil {
+		t.Fatalf("FindByTag: %v", err)
+	}
+	statements := d.log()
+	if len(statements) != 1 || !strings.Contains(statements[0], "tags @> $1::jsonb") {
+		t.Fatalf("statements = %q, want a jsonb containment query", statements)
+	}
+	if len(d.args[0]) == 0 || d.args[0][0] != `["go"]` {
+		t.Errorf("args = %v, want the tag as a JSON array", d.args[0])
+	}
+}
+
+func TestUpdateChecksVersion(t *testing.T) {
+	db, d := newRecordingDB(t)
+	p := &post.Post{ID: 1, UserID: 1, Title: "Hello", Content: "Hello, world", Version: 3}
+
+	// 記錄驅動的更新不影響任何行，相當於文章已被並發修改
+	err := NewPostRepository(db).Update(context.Background(), p)
+	if !errors.Is(err, post.ErrVersionConflict) {
+		t.Fatalf("Update error = %v, want %v", err, post.ErrVersionConflict)
+	}
+	if p.Version != 3 {
+		t.Errorf("Version = %d after a conflict, want it restored to 3", p.Version)
+	}
+
+	statements := d.log()
+	if len(statements) != 1 || !strings.Contains(statements[0], "version = $") || strings.Contains(statements[0], "reaction_counts") {
+		t.Fatalf("statements = %q, want one versioned update that leaves reaction counts alone", statements)
+	}
+	// 新版本寫入 SET，讀取時的版本作為條件
+	for _, tt := range []struct {
+		pattern string
+		want    int64
+	}{
+		{`"version"=\$(\d+)`, 4},
+		{`WHERE version = \$(\d+)`, 3},
+	} {
+		m := regexp.MustCompile(tt.pattern).FindStringSubmatch(statements[0])
+		if m == nil {
+			t.Fatalf("statement %q does not match %s", statements[0], tt.pattern)
+		}
+		n, _ := strconv.Atoi(m[1])
+		if got := d.args[0][n-1]; got != tt.want {
+			t.Errorf("%s arg = %v, want %d", tt.pattern, got, tt.want)
+		}
+	}
+}
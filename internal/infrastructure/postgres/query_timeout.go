@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// queryTimeoutKey 在語句設置中保存設置時限之前的上下文和取消函數
+const queryTimeoutKey = "query_timeout"
+
+// queryTimeoutState 保存單條語句的時限狀態
+type queryTimeoutState struct {
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+// callbackRegistrar 是 GORM 回調鏈中某個位置的註冊接口
+type callbackRegistrar interface {
+	Register(name string, fn func(*gorm.DB)) error
+}
+
+// QueryTimeout 是一個 GORM 插件，為每條語句設置執行時限
+// 時限與請求上下文疊加，以較早的截止時間為準；Row/Rows 的結果在回調之後才讀取，因此不受此限制
+type QueryTimeout struct {
+	timeout time.Duration
+}
+
+// NewQueryTimeout 創建一個新的 QueryTimeout 插件，timeout 為 0 時不限制
+func NewQueryTimeout(timeout time.Duration) *QueryTimeout {
+	return &QueryTimeout{timeout: timeout}
+}
+
+// Name 返回插件名稱
+func (q *QueryTimeout) Name() string {
+	return "query_timeout"
+}
+
+// Initialize 在增刪改查和原始 SQL 的回調鏈首尾註冊時限處理
+func (q *QueryTimeout) Initialize(db *gorm.DB) error {
+	if q.timeout <= 0 {
+		return nil
+	}
+
+	cb := db.Callback()
+	chains := []struct {
+		name          string
+		before, after callbackRegistrar
+	}{
+		{"create", cb.Create().Before("*"), cb.Create().After("*")},
+		{"query", cb.Query().Before("*"), cb.Query().After("*")},
+		{"update", cb.Update().Before("*"), cb.Update().After("*")},
+		{"delete", cb.Delete().Before("*"), cb.Delete().After("*")},
+		{"raw", cb.Raw().Before("*"), cb.Raw().After("*")},
+	}
+	for _, chain := range chains {
+		if err := chain.before.Register("query_timeout:start_"+chain.name, q.start); err != nil {
+			return err
+		}
+		if err := chain.after.Register("query_timeout:end_"+chain.name, q.end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// start 為語句設置時限
+func (q *QueryTimeout) start(db *gorm.DB) {
+	parent := db.Statement.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, q.timeout)
+	db.Statement.Context = ctx
+	db.InstanceSet(queryTimeoutKey, queryTimeoutState{parent: parent, cancel: cancel})
+}
+
+// end 釋放時限並恢復原來的上下文，使同一個查詢鏈上的後續語句重新計時
+func (q *QueryTimeout) end(db *gorm.DB) {
+	v, ok := db.InstanceGet(queryTimeoutKey)
+	if !ok {
+		return
+	}
+	state := v.(queryTimeoutState)
+	state.cancel()
+	db.Statement.Context = state.parent
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"blog-api/internal/domain/post"
+	"blog-api/internal/domain/reaction"
+	"blog-api/internal/domain/user"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReactionRepository 實現 reaction.Repository 接口
+type ReactionRepository struct {
+	db *gorm.DB
+}
+
+// NewReactionRepository 創建一個新的 ReactionRepository 實例
+func NewReactionRepository(db *gorm.DB) *ReactionRepository {
+	return &ReactionRepository{db: db}
+}
+
+// adjustCountSQL 在單條 UPDATE 中基於當前值調整計數，行鎖保證並發請求不會丟失更新
+const adjustCountSQL = `
+	UPDATE posts
+	SET reaction_counts = jsonb_set(
+		reaction_counts,
+		ARRAY[?::text],
+		to_jsonb(GREATEST(COALESCE((reaction_counts->>?)::bigint, 0) + ?, 0))
+	)
+	WHERE id = ?`
+
+// Add 保存回應，只有新插入的回應才會增加計數
+func (r *ReactionRepository) Add(ctx context.Context, rc *reaction.Reaction) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(rc)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return adjustCount(tx, rc.PostID, rc.Type, 1)
+	})
+}
+
+// Remove 刪除回應，只有實際刪除的回應才會減少計數
+func (r *ReactionRepository) Remove(ctx context.Context, postID, userID uint, reactionType reaction.Type) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("post_id = ? AND user_id = ? AND type = ?", postID, userID, reactionType).Delete(&reaction.Reaction{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return adjustCount(tx, postID, reactionType, -1)
+	})
+}
+
+// FindByUserID 獲取用戶的所有回應，最新的回應在前
+func (r *ReactionRepository) FindByUserID(ctx context.Context, userID uint, page, pageSize int) ([]reaction.Reaction, error) {
+	var reactions []reaction.Reaction
+	err := conn(ctx, r.db).Where("user_id = ?", userID).
+		Order("created_at DESC, post_id, type").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&reactions).Error
+	return reactions, err
+}
+
+// FindReactors 獲取回應文章的用戶列表，最新的回應在前
+func (r *ReactionRepository) FindReactors(ctx context.Context, postID uint, reactionType reaction.Type, page, pageSize int) ([]reaction.Reactor, error) {
+	var rows []struct {
+		UserID     uint
+		Username   string
+		FirstName  string
+		LastName   string
+		AvatarHash string
+		Type       reaction.Type
+		CreatedAt  time.Time
+	}
+	q := conn(ctx, r.db).Table("post_reactions").
+		Select("users.id AS user_id, users.username, users.first_name, users.last_name, users.avatar_hash, post_reactions.type, post_reactions.created_at").
+		Joins("JOIN users ON users.id = post_reactions.user_id").
+		Where("post_reactions.post_id = ? AND users.is_active = ?", postID, true)
+	if reactionType != "" {
+		q = q.Where("post_reactions.type = ?", reactionType)
+	}
+	err := q.Order("post_reactions.created_at DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	reactors := make([]reaction.Reactor, len(rows))
+	for i, row := range rows {
+		reactors[i] = reaction.Reactor{
+			User: post.Author{
+				ID:        row.UserID,
+				Username:  row.Username,
+				FirstName: row.FirstName,
+				LastName:  row.LastName,
+				AvatarURL: user.AvatarURL(row.Username, row.AvatarHash),
+			},
+			Type:      row.Type,
+			CreatedAt: row.CreatedAt,
+		}
+	}
+	return reactors, nil
+}
+
+// adjustCount 原子地調整文章上某種回應的計數
+func adjustCount(tx *gorm.DB, postID uint, reactionType reaction.Type, delta int) error {
+	return tx.Exec(adjustCountSQL, string(reactionType), string(reactionType), delta, postID).Error
+}
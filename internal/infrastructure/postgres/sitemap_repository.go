@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"blog-api/internal/domain/sitemap"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// SitemapRepository 實現 sitemap.Repository 接口
+type SitemapRepository struct {
+	db *gorm.DB
+}
+
+// NewSitemapRepository 創建一個新的 SitemapRepository 實例
+func NewSitemapRepository(db *gorm.DB) *SitemapRepository {
+	return &SitemapRepository{db: db}
+}
+
+// FindPostEntries 獲取所有文章的ID和更新時間
+func (r *SitemapRepository) FindPostEntries(ctx context.Context) ([]sitemap.PostEntry, error) {
+	var entries []sitemap.PostEntry
+	err := conn(ctx, r.db).Table("posts").Select("id, updated_at").Order("id").Scan(&entries).Error
+	return entries, err
+}
+
+// FindAuthorEntries 獲取所有發表過文章且仍處於啟用狀態的作者
+func (r *SitemapRepository) FindAuthorEntries(ctx context.Context) ([]sitemap.AuthorEntry, error) {
+	var entries []sitemap.AuthorEntry
+	err := conn(ctx, r.db).Table("users").
+		Select("users.username, MAX(posts.updated_at) AS updated_at").
+		Joins("JOIN posts ON posts.user_id = users.id").
+		Where("users.is_active = ?", true).
+		Group("users.username").Order("users.username").
+		Scan(&entries).Error
+	return entries, err
+}
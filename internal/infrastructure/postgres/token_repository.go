@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"blog-api/internal/domain/token"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TokenRepository 實現 token.Repository 接口
+type TokenRepository struct {
+	db *gorm.DB
+}
+
+// NewTokenRepository 創建一個新的 TokenRepository 實例
+func NewTokenRepository(db *gorm.DB) *TokenRepository {
+	return &TokenRepository{db: db}
+}
+
+// Create 保存新的訪問令牌
+func (r *TokenRepository) Create(ctx context.Context, t *token.AccessToken) error {
+	return conn(ctx, r.db).Create(t).Error
+}
+
+// FindByID 根據ID查找訪問令牌
+func (r *TokenRepository) FindByID(ctx context.Context, id uint) (*token.AccessToken, error) {
+	var t token.AccessToken
+	if err := conn(ctx, r.db).First(&t, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, token.ErrTokenNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// FindByPrefix 根據可見前綴查找訪問令牌
+func (r *TokenRepository) FindByPrefix(ctx context.Context, prefix string) (*token.AccessToken, error) {
+	var t token.AccessToken
+	if err := conn(ctx, r.db).Where("prefix = ?", prefix).First(&t).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, token.ErrTokenNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// FindByUserID 獲取用戶的所有訪問令牌
+func (r *TokenRepository) FindByUserID(ctx context.Context, userID uint) ([]token.AccessToken, error) {
+	var tokens []token.AccessToken
+	err := conn(ctx, r.db).Where("user_id = ?", userID).Order("id DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+// Revoke 撤銷訪問令牌
+func (r *TokenRepository) Revoke(ctx context.Context, id uint, at time.Time) error {
+	return conn(ctx, r.db).Model(&token.AccessToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).UpdateColumn("revoked_at", at).Error
+}
+
+// Touch 更新訪問令牌的最後使用時間，已撤銷的令牌不會被修改
+func (r *TokenRepository) Touch(ctx context.Context, id uint, at time.Time) error {
+	return conn(ctx, r.db).Model(&token.AccessToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).UpdateColumn("last_used_at", at).Error
+}
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// txKey 是上下文中保存事務句柄的鍵
+type txKey struct{}
+
+// retryableCodes 可以通過重新執行事務解決的 Postgres 錯誤碼：序列化失敗和死鎖
+var retryableCodes = map[string]bool{
+	"40001": true,
+	"40P01": true,
+}
+
+// TransactionConfig 定義事務的隔離級別和重試策略
+type TransactionConfig struct {
+	Isolation    sql.IsolationLevel
+	MaxRetries   int           // 序列化衝突時最多重試的次數
+	RetryBackoff time.Duration // 第一次重試前的等待時間，之後每次加倍並加入隨機抖動
+}
+
+// TransactionManager 實現 transaction.Manager 接口
+type TransactionManager struct {
+	db     *gorm.DB
+	config TransactionConfig
+}
+
+// NewTransactionManager 創建一個新的 TransactionManager 實例
+func NewTransactionManager(db *gorm.DB, config TransactionConfig) *TransactionManager {
+	return &TransactionManager{db: db, config: config}
+}
+
+// Do 在事務中執行 fn，已在事務中時使用保存點，最外層事務遇到序列化衝突時重試
+func (m *TransactionManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		// GORM 在已開始的事務上調用 Transaction 時會使用保存點
+		return tx.WithContext(ctx).Transaction(func(sp *gorm.DB) error {
+			return fn(context.WithValue(ctx, txKey{}, sp))
+		})
+	}
+
+	backoff := m.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		}, &sql.TxOptions{Isolation: m.config.Isolation})
+		if err == nil || !isRetryable(err) || attempt >= m.config.MaxRetries {
+			return err
+		}
+
+		log.Printf("Retrying transaction after conflict (attempt %d): %v", attempt+1, err)
+		wait := backoff
+		if wait > 0 {
+			wait += time.Duration(rand.Int63n(int64(wait)))
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// ParseIsolationLevel 解析隔離級別名稱，例如 read_committed、repeatable_read 或 serializable
+func ParseIsolationLevel(name string) (sql.IsolationLevel, error) {
+	switch strings.ToLower(strings.ReplaceAll(name, " ", "_")) {
+	case "", "default":
+		return sql.LevelDefault, nil
+	case "read_committed":
+		return sql.LevelReadCommitted, nil
+	case "repeatable_read":
+		return sql.LevelRepeatableRead, nil
+	case "serializable":
+		return sql.LevelSerializable, nil
+	}
+	return sql.LevelDefault, fmt.Errorf("unsupported isolation level: %q", name)
+}
+
+// isRetryable 判斷事務錯誤是否可以通過重新執行解決
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && retryableCodes[pgErr.Code]
+}
+
+// conn 返回用於執行查詢的數據庫句柄，ctx 攜帶事務時使用該事務
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -0,0 +1,278 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	pgDriver "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// recordingDriver 是只記錄語句的 database/sql 驅動，用於在沒有數據庫的情況下檢查生成的語句
+// respond 不為空時為查詢提供結果，否則查詢返回空結果
+type recordingDriver struct {
+	mu         sync.Mutex
+	statements []string
+	args       [][]driver.Value
+	respond    func(query string) ([]string, [][]driver.Value)
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) {
+	return &recordingConn{driver: d}, nil
+}
+
+func (d *recordingDriver) record(statement string, args ...driver.Value) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements = append(d.statements, statement)
+	d.args = append(d.args, args)
+}
+
+func (d *recordingDriver) log() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.statements...)
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{conn: c, query: query}, nil
+}
+
+func (c *recordingConn) Close() error { return nil }
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *recordingConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.driver.record("BEGIN")
+	return &recordingTx{driver: c.driver}, nil
+}
+
+type recordingTx struct {
+	driver *recordingDriver
+}
+
+func (t *recordingTx) Commit() error {
+	t.driver.record("COMMIT")
+	return nil
+}
+
+func (t *recordingTx) Rollback() error {
+	t.driver.record("ROLLBACK")
+	return nil
+}
+
+type recordingStmt struct {
+	conn  *recordingConn
+	query string
+}
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return -1 }
+
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.conn.driver.record(s.query, args...)
+	return driver.RowsAffected(0), nil
+}
+
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.conn.driver.record(s.query, args...)
+	if s.conn.driver.respond == nil {
+		return &resultRows{}, nil
+	}
+	columns, rows := s.conn.driver.respond(s.query)
+	return &resultRows{columns: columns, rows: rows}, nil
+}
+
+// resultRows 依次返回預設的結果行
+type resultRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *resultRows) Columns() []string { return r.columns }
+func (r *resultRows) Close() error      { return nil }
+
+func (r *resultRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// driverSeq 為每個測試註冊的驅動生成唯一名稱
+var driverSeq struct {
+	sync.Mutex
+	n int
+}
+
+// newRecordingDB 打開一個使用 recordingDriver 的 GORM 連接
+func newRecordingDB(t *testing.T) (*gorm.DB, *recordingDriver) {
+	t.Helper()
+	driverSeq.Lock()
+	driverSeq.n++
+	name := "recording-" + strconv.Itoa(driverSeq.n)
+	driverSeq.Unlock()
+
+	d := &recordingDriver{}
+	sql.Register(name, d)
+	db, err := gorm.Open(pgDriver.New(pgDriver.Config{DriverName: name, DSN: name}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	return db, d
+}
+
+func TestTransactionManagerNestedUsesSavepoint(t *testing.T) {
+	db, d := newRecordingDB(t)
+	m := NewTransactionManager(db, TransactionConfig{})
+	innerErr := errors.New("inner failed")
+
+	err := m.Do(context.Background(), func(ctx context.Context) error {
+		outer := conn(ctx, db)
+		if err := m.Do(ctx, func(ctx context.Context) error {
+			if conn(ctx, db).Statement.ConnPool == outer.Statement.ConnPool {
+				return nil
+			}
+			return errors.New("nested transaction did not share the outer connection")
+		}); err != nil {
+			return err
+		}
+		if err := m.Do(ctx, func(context.Context) error { return innerErr }); !errors.Is(err, innerErr) {
+			t.Errorf("nested Do error = %v, want %v", err, innerErr)
+		}
+		// 內層失敗只回滾到保存點，外層事務仍然可以提交
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	var begins, savepoints, rollbacksTo, commits, rollbacks int
+	for _, s := range d.log() {
+		switch {
+		case s == "BEGIN":
+			begins++
+		case strings.HasPrefix(s, "SAVEPOINT"):
+			savepoints++
+		case strings.HasPrefix(s, "ROLLBACK TO SAVEPOINT"):
+			rollbacksTo++
+		case s == "COMMIT":
+			commits++
+		case s == "ROLLBACK":
+			rollbacks++
+		}
+	}
+	if begins != 1 || savepoints != 2 || rollbacksTo != 1 || commits != 1 || rollbacks != 0 {
+		t.Errorf("statements = %q, want 1 BEGIN, 2 SAVEPOINT, 1 ROLLBACK TO SAVEPOINT and 1 COMMIT", d.log())
+	}
+}
+
+func TestTransactionManagerRetriesConflicts(t *testing.T) {
+	db, _ := newRecordingDB(t)
+	m := NewTransactionManager(db, TransactionConfig{MaxRetries: 3})
+
+	attempts := 0
+	err := m.Do(context.Background(), func(context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return &pgconn.PgError{Code: "40001"}
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Errorf("Do = %v after %d attempts, want success after 3", err, attempts)
+	}
+}
+
+func TestTransactionManagerGivesUpAfterMaxRetries(t *testing.T) {
+	db, _ := newRecordingDB(t)
+	m := NewTransactionManager(db, TransactionConfig{MaxRetries: 2})
+
+	attempts := 0
+	err := m.Do(context.Background(), func(context.Context) error {
+		attempts++
+		return &pgconn.PgError{Code: "40P01"}
+	})
+	if !isRetryable(err) || attempts != 3 {
+		t.Errorf("Do = %v after %d attempts, want deadlock error after 3", err, attempts)
+	}
+}
+
+func TestTransactionManagerDoesNotRetryOtherErrors(t *testing.T) {
+	db, d := newRecordingDB(t)
+	m := NewTransactionManager(db, TransactionConfig{MaxRetries: 3})
+	want := &pgconn.PgError{Code: "23505"}
+
+	attempts := 0
+	err := m.Do(context.Background(), func(context.Context) error {
+		attempts++
+		return want
+	})
+	if !errors.Is(err, want) || attempts != 1 {
+		t.Errorf("Do = %v after %d attempts, want the original error after 1", err, attempts)
+	}
+	if log := d.log(); len(log) != 2 || log[1] != "ROLLBACK" {
+		t.Errorf("statements = %q, want BEGIN then ROLLBACK", log)
+	}
+}
+
+func TestTransactionManagerRetriesOnlyOutermost(t *testing.T) {
+	db, _ := newRecordingDB(t)
+	m := NewTransactionManager(db, TransactionConfig{MaxRetries: 3})
+
+	inner := 0
+	err := m.Do(context.Background(), func(ctx context.Context) error {
+		return m.Do(ctx, func(context.Context) error {
+			inner++
+			if inner == 1 {
+				return &pgconn.PgError{Code: "40001"}
+			}
+			return nil
+		})
+	})
+	// 衝突使整個事務失效，只能由最外層重新執行
+	if err != nil || inner != 2 {
+		t.Errorf("Do = %v after %d inner attempts, want success after 2", err, inner)
+	}
+}
+
+func TestParseIsolationLevel(t *testing.T) {
+	tests := []struct {
+		name string
+		want sql.IsolationLevel
+	}{
+		{"", sql.LevelDefault},
+		{"default", sql.LevelDefault},
+		{"read_committed", sql.LevelReadCommitted},
+		{"Repeatable Read", sql.LevelRepeatableRead},
+		{"SERIALIZABLE", sql.LevelSerializable},
+	}
+	for _, tt := range tests {
+		got, err := ParseIsolationLevel(tt.name)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseIsolationLevel(%q) = %v, %v; want %v", tt.name, got, err, tt.want)
+		}
+	}
+	if _, err := ParseIsolationLevel("snapshot"); err == nil {
+		t.Error("ParseIsolationLevel(snapshot) succeeded, want error")
+	}
+}
@@ -1,9 +1,14 @@
 package postgres
 
 import (
+	"blog-api/internal/domain/post"
 	"blog-api/internal/domain/user"
+	"context"
+	"errors"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // UserRepository 實現 user.Repository 接口，使用 GORM 和 PostgreSQL
@@ -17,43 +22,147 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 }
 
 // Create 將新用戶保存到數據庫
-func (r *UserRepository) Create(user *user.User) error {
-	return r.db.Create(user).Error
+// 並發註冊時先前的唯一性檢查可能都通過，由唯一索引拒絕的一方會得到對應的重複錯誤
+func (r *UserRepository) Create(ctx context.Context, user *user.User) error {
+	return duplicateUserError(conn(ctx, r.db).Create(user).Error)
 }
 
 // FindByUsername 根據用戶名從數據庫中查找用戶
-func (r *UserRepository) FindByUsername(username string) (*user.User, error) {
+func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*user.User, error) {
 	var u user.User
-	if err := r.db.Where("username = ?", username).First(&u).Error; err != nil {
+	if err := conn(ctx, r.db).Where("username = ?", username).First(&u).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, user.ErrUserNotFound
+		}
 		return nil, err
 	}
 	return &u, nil
 }
 
 // FindByID 根據ID從數據庫中查找用戶
-func (r *UserRepository) FindByID(id uint) (*user.User, error) {
+func (r *UserRepository) FindByID(ctx context.Context, id uint) (*user.User, error) {
 	var u user.User
-	if err := r.db.First(&u, id).Error; err != nil {
+	if err := conn(ctx, r.db).First(&u, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, user.ErrUserNotFound
+		}
 		return nil, err
 	}
 	return &u, nil
 }
 
 // FindByEmail 根據郵箱從數據庫中查找用戶
-func (r *UserRepository) FindByEmail(email string) (*user.User, error) {
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*user.User, error) {
 	var u user.User
-	if err := r.db.Where("email = ?", email).First(&u).Error; err != nil {
+	if err := conn(ctx, r.db).Where("email = ?", email).First(&u).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, user.ErrUserNotFound
+		}
 		return nil, err
 	}
 	return &u, nil
 }
 
+// FindByEmailTokenHash 根據郵箱驗證令牌的哈希查找用戶
+func (r *UserRepository) FindByEmailTokenHash(ctx context.Context, tokenHash string) (*user.User, error) {
+	var u user.User
+	if err := conn(ctx, r.db).Where("email_token_hash = ?", tokenHash).First(&u).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, user.ErrInvalidEmailToken
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+// CountByAvatarHash 統計使用指定頭像的用戶數量，相同的圖片會得到相同的哈希
+func (r *UserRepository) CountByAvatarHash(ctx context.Context, avatarHash string) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&user.User{}).Where("avatar_hash = ?", avatarHash).Count(&count).Error
+	return count, err
+}
+
+// SetAvatarHash 只更新用戶的頭像哈希並返回之前的值
+// 先鎖定用戶行再讀取舊值，並發上傳時每個舊頭像都只會被返回一次
+func (r *UserRepository) SetAvatarHash(ctx context.Context, id uint, avatarHash string) (string, error) {
+	var previous string
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var u user.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "avatar_hash").First(&u, id).Error
+		if err == gorm.ErrRecordNotFound {
+			return user.ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+		previous = u.AvatarHash
+		return tx.Model(&user.User{}).Where("id = ?", id).UpdateColumn("avatar_hash", avatarHash).Error
+	})
+	return previous, err
+}
+
 // Update 更新數據庫中的用戶信息
-func (r *UserRepository) Update(user *user.User) error {
-	return r.db.Save(user).Error
+func (r *UserRepository) Update(ctx context.Context, user *user.User) error {
+	return duplicateUserError(conn(ctx, r.db).Save(user).Error)
+}
+
+// duplicateUserError 根據違反的唯一約束將錯誤轉換為用戶名或郵箱重複錯誤
+func duplicateUserError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.Is(err, gorm.ErrDuplicatedKey) || !errors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.ConstraintName {
+	case "idx_users_username":
+		return user.ErrDuplicateUsername
+	case "idx_users_email":
+		return user.ErrDuplicateEmail
+	}
+	return err
 }
 
 // Delete 從數據庫中刪除用戶
-func (r *UserRepository) Delete(id uint) error {
-	return r.db.Delete(&user.User{}, id).Error
+// 用戶擁有的令牌、關注、收藏等數據由外鍵級聯刪除；文章的外鍵為 RESTRICT，
+// 必須先按刪除策略轉移或刪除文章
+func (r *UserRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return deleteUser(tx, id)
+	})
+}
+
+// DeleteWithPosts 在同一事務中刪除用戶及其所有文章
+func (r *UserRepository) DeleteWithPosts(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", id).Delete(&post.Post{}).Error; err != nil {
+			return err
+		}
+		return deleteUser(tx, id)
+	})
+}
+
+// recountReactionsSQL 重新計算用戶回應過的文章的回應計數，排除即將被級聯刪除的回應
+const recountReactionsSQL = `
+	UPDATE posts p
+	SET reaction_counts = COALESCE((
+		SELECT jsonb_object_agg(t.type, t.n)
+		FROM (
+			SELECT r.type, COUNT(*) AS n FROM post_reactions r
+			WHERE r.post_id = p.id AND r.user_id <> ?
+			GROUP BY r.type
+		) t
+	), '{}'::jsonb)
+	WHERE p.id IN (SELECT post_id FROM post_reactions WHERE user_id = ?)`
+
+// deleteUser 刪除用戶記錄，並保持其他文章上的回應計數準確
+func deleteUser(tx *gorm.DB, id uint) error {
+	if err := tx.Exec(recountReactionsSQL, id, id).Error; err != nil {
+		return err
+	}
+	if err := tx.Delete(&user.User{}, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return user.ErrUserHasPosts
+		}
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound 表示請求的對象不存在
+var ErrNotFound = errors.New("object not found")
+
+// LocalStorage 將對象保存在本地目錄中
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage 創建一個新的 LocalStorage 實例
+func NewLocalStorage(dir string) *LocalStorage {
+	return &LocalStorage{dir: dir}
+}
+
+// Put 保存對象，key 可以包含子目錄
+func (s *LocalStorage) Put(key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// 先寫入臨時文件再重命名，避免讀取到寫了一半的文件
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Get 讀取對象
+func (s *LocalStorage) Get(key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// Delete 刪除對象，對象不存在時不報錯
+func (s *LocalStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// path 將 key 轉換為本地路徑，拒絕跳出存儲目錄的 key
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if strings.Contains(key, "..") {
+		return "", ErrNotFound
+	}
+	return filepath.Join(s.dir, clean), nil
+}
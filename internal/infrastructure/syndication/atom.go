@@ -0,0 +1,97 @@
+package syndication
+
+import (
+	appSyndication "blog-api/internal/application/syndication"
+	"encoding/xml"
+	"time"
+)
+
+// Atom 1.0 文檔結構
+type atomFeed struct {
+	XMLName   xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang      string      `xml:"xml:lang,attr,omitempty"`
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Subtitle  string      `xml:"subtitle,omitempty"`
+	Updated   string      `xml:"updated"`
+	Links     []atomLink  `xml:"link"`
+	Author    *atomPerson `xml:"author,omitempty"`
+	Generator string      `xml:"generator"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published"`
+	Links      []atomLink     `xml:"link"`
+	Author     atomPerson     `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+}
+
+// renderAtom 渲染 Atom 1.0 訂閱源
+func renderAtom(feed *appSyndication.Feed, selfURL string) ([]byte, error) {
+	updated := feed.Updated
+	if updated.IsZero() {
+		updated = time.Unix(0, 0).UTC()
+	}
+
+	doc := atomFeed{
+		Lang:     feed.Language,
+		ID:       selfURL,
+		Title:    feed.Title,
+		Subtitle: feed.Description,
+		Updated:  updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: selfURL, Rel: "self", Type: FormatAtom.mediaType()},
+			{Href: feed.HomeURL, Rel: "alternate", Type: "text/html"},
+		},
+		Generator: generator,
+		Entries:   make([]atomEntry, len(feed.Items)),
+	}
+	if feed.Author != nil {
+		doc.Author = &atomPerson{Name: feed.Author.Name, URI: feed.Author.URL}
+	}
+	for i, item := range feed.Items {
+		entry := atomEntry{
+			ID:        item.ID,
+			Title:     item.Title,
+			Updated:   item.Updated.Format(time.RFC3339),
+			Published: item.Published.Format(time.RFC3339),
+			Links:     []atomLink{{Href: item.URL, Rel: "alternate", Type: "text/html"}},
+			Author:    atomPerson{Name: item.Author.Name, URI: item.Author.URL},
+			Summary:   &atomText{Type: "text", Body: item.Summary},
+		}
+		if item.Content != "" {
+			entry.Content = &atomText{Type: "text", Body: item.Content}
+		}
+		for _, tag := range item.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		doc.Entries[i] = entry
+	}
+	return marshalXML(doc)
+}
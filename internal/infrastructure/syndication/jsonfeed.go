@@ -0,0 +1,77 @@
+package syndication
+
+import (
+	appSyndication "blog-api/internal/application/syndication"
+	"encoding/json"
+	"time"
+)
+
+// JSON Feed 1.1 文檔結構
+type jsonFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url"`
+	FeedURL     string           `json:"feed_url"`
+	Description string           `json:"description,omitempty"`
+	Language    string           `json:"language,omitempty"`
+	Authors     []jsonFeedAuthor `json:"authors,omitempty"`
+	Items       []jsonFeedItem   `json:"items"`
+}
+
+type jsonFeedAuthor struct {
+	Name   string `json:"name"`
+	URL    string `json:"url,omitempty"`
+	Avatar string `json:"avatar,omitempty"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentText   string           `json:"content_text"`
+	Summary       string           `json:"summary,omitempty"`
+	DatePublished string           `json:"date_published"`
+	DateModified  string           `json:"date_modified"`
+	Authors       []jsonFeedAuthor `json:"authors"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+// renderJSON 渲染 JSON Feed 1.1 訂閱源
+func renderJSON(feed *appSyndication.Feed, selfURL string) ([]byte, error) {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feed.Title,
+		HomePageURL: feed.HomeURL,
+		FeedURL:     selfURL,
+		Description: feed.Description,
+		Language:    feed.Language,
+		Items:       make([]jsonFeedItem, len(feed.Items)),
+	}
+	if feed.Author != nil {
+		doc.Authors = []jsonFeedAuthor{toJSONFeedAuthor(*feed.Author)}
+	}
+	for i, item := range feed.Items {
+		// JSON Feed 要求 content_text 或 content_html 至少一個，只輸出摘要時使用摘要
+		content := item.Content
+		if content == "" {
+			content = item.Summary
+		}
+		doc.Items[i] = jsonFeedItem{
+			ID:            item.ID,
+			URL:           item.URL,
+			Title:         item.Title,
+			ContentText:   content,
+			Summary:       item.Summary,
+			DatePublished: item.Published.Format(time.RFC3339),
+			DateModified:  item.Updated.Format(time.RFC3339),
+			Authors:       []jsonFeedAuthor{toJSONFeedAuthor(item.Author)},
+			Tags:          item.Tags,
+		}
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// toJSONFeedAuthor 轉換作者信息
+func toJSONFeedAuthor(p appSyndication.Person) jsonFeedAuthor {
+	return jsonFeedAuthor{Name: p.Name, URL: p.URL, Avatar: p.AvatarURL}
+}
@@ -0,0 +1,94 @@
+package syndication
+
+import (
+	appSyndication "blog-api/internal/application/syndication"
+	"encoding/xml"
+	"time"
+)
+
+// RSS 2.0 文檔結構，作者使用 dc:creator，因為 RSS 的 author 元素要求郵箱地址
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string      `xml:"title"`
+	Link          string      `xml:"link"`
+	Description   string      `xml:"description"`
+	AtomLink      rssAtomLink `xml:"atom:link"`
+	Language      string      `xml:"language,omitempty"`
+	LastBuildDate string      `xml:"lastBuildDate,omitempty"`
+	Generator     string      `xml:"generator"`
+	Items         []rssItem   `xml:"item"`
+}
+
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Creator     string   `xml:"dc:creator"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// renderRSS 渲染 RSS 2.0 訂閱源
+func renderRSS(feed *appSyndication.Feed, selfURL string) ([]byte, error) {
+	doc := rssDocument{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       feed.Title,
+			Link:        feed.HomeURL,
+			Description: feed.Description,
+			AtomLink:    rssAtomLink{Href: selfURL, Rel: "self", Type: FormatRSS.mediaType()},
+			Language:    feed.Language,
+			Generator:   generator,
+			Items:       make([]rssItem, len(feed.Items)),
+		},
+	}
+	if !feed.Updated.IsZero() {
+		doc.Channel.LastBuildDate = feed.Updated.Format(time.RFC1123Z)
+	}
+	for i, item := range feed.Items {
+		description := item.Content
+		if description == "" {
+			description = item.Summary
+		}
+		doc.Channel.Items[i] = rssItem{
+			Title:       item.Title,
+			Link:        item.URL,
+			GUID:        rssGUID{IsPermaLink: true, Value: item.ID},
+			PubDate:     item.Published.Format(time.RFC1123Z),
+			Creator:     item.Author.Name,
+			Categories:  item.Tags,
+			Description: description,
+		}
+	}
+	return marshalXML(doc)
+}
+
+// marshalXML 將文檔編碼為帶 XML 聲明的縮進格式
+func marshalXML(doc interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,58 @@
+package syndication
+
+import (
+	appSyndication "blog-api/internal/application/syndication"
+	"errors"
+	"strings"
+)
+
+// Format 代表一種訂閱源格式
+type Format string
+
+// 支持的訂閱源格式
+const (
+	FormatRSS  Format = "rss"
+	FormatAtom Format = "atom"
+	FormatJSON Format = "json"
+)
+
+// ErrUnsupportedFormat 表示不支持的訂閱源格式
+var ErrUnsupportedFormat = errors.New("unsupported feed format")
+
+// generator 是寫入訂閱源的生成器名稱
+const generator = "blog-api"
+
+// ContentType 返回格式對應的 MIME 類型
+func (f Format) ContentType() string {
+	switch f {
+	case FormatRSS:
+		return "application/rss+xml; charset=utf-8"
+	case FormatAtom:
+		return "application/atom+xml; charset=utf-8"
+	case FormatJSON:
+		return "application/feed+json; charset=utf-8"
+	}
+	return "application/octet-stream"
+}
+
+// mediaType 返回不帶字符集參數的 MIME 類型，用於訂閱源中的自引用鏈接
+func (f Format) mediaType() string {
+	ct := f.ContentType()
+	if i := strings.IndexByte(ct, ';'); i >= 0 {
+		return ct[:i]
+	}
+	return ct
+}
+
+// Render 將訂閱源渲染為指定格式，selfURL 為訂閱源本身的地址
+func Render(format Format, feed *appSyndication.Feed, selfURL string) ([]byte, error) {
+	switch format {
+	case FormatRSS:
+		return renderRSS(feed, selfURL)
+	case FormatAtom:
+		return renderAtom(feed, selfURL)
+	case FormatJSON:
+		return renderJSON(feed, selfURL)
+	}
+	return nil, ErrUnsupportedFormat
+}
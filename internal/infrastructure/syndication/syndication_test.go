@@ -0,0 +1,277 @@
+package syndication
+
+import (
+	appSyndication "blog-api/internal/application/syndication"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const selfURL = "https://api.example.com/feed"
+
+// testFeed 返回包含需要轉義字符的訂閱源
+func testFeed() *appSyndication.Feed {
+	published := time.Date(2024, 10, 20, 14, 0, 0, 0, time.UTC)
+	updated := published.Add(90 * time.Minute)
+	return &appSyndication.Feed{
+		Title:       "Tom & Jerry's <Blog>",
+		Description: "Cats & mice",
+		Language:    "en",
+		HomeURL:     "https://example.com/",
+		Updated:     updated,
+		Items: []appSyndication.Item{{
+			ID:        "https://example.com/posts/1",
+			URL:       "https://example.com/posts/1",
+			Title:     `Generics <T> & "you"`,
+			Content:   "<script>alert('x')</script> & more",
+			Summary:   "<script>alert('x')</script> & more",
+			Author:    appSyndication.Person{Name: "John Doe", URL: "https://example.com/users/johndoe"},
+			Tags:      []string{"go", "generics"},
+			Published: published,
+			Updated:   updated,
+		}},
+	}
+}
+
+func render(t *testing.T, format Format, feed *appSyndication.Feed) []byte {
+	t.Helper()
+	body, err := Render(format, feed, selfURL)
+	if err != nil {
+		t.Fatalf("Render(%s): %v", format, err)
+	}
+	return body
+}
+
+// assertEscaped 檢查原始文本沒有未經轉義地出現在輸出中
+func assertEscaped(t *testing.T, body []byte, raw ...string) {
+	t.Helper()
+	for _, s := range raw {
+		if bytes.Contains(body, []byte(s)) {
+			t.Errorf("output contains unescaped %q", s)
+		}
+	}
+}
+
+func TestRenderRSS(t *testing.T) {
+	body := render(t, FormatRSS, testFeed())
+	if !bytes.HasPrefix(body, []byte(xml.Header)) {
+		t.Error("RSS output has no XML declaration")
+	}
+	assertEscaped(t, body, "<Blog>", "<script>", "<T>")
+
+	var doc struct {
+		XMLName xml.Name `xml:"rss"`
+		Version string   `xml:"version,attr"`
+		Channel struct {
+			// atom:link 必須在 link 之前，否則沒有命名空間的 link 字段也會匹配它
+			AtomLink struct {
+				Href string `xml:"href,attr"`
+				Rel  string `xml:"rel,attr"`
+				Type string `xml:"type,attr"`
+			} `xml:"http://www.w3.org/2005/Atom link"`
+			Title         string `xml:"title"`
+			Link          string `xml:"link"`
+			Description   string `xml:"description"`
+			LastBuildDate string `xml:"lastBuildDate"`
+			Items         []struct {
+				Title string `xml:"title"`
+				Link  string `xml:"link"`
+				GUID  struct {
+					IsPermaLink string `xml:"isPermaLink,attr"`
+					Value       string `xml:",chardata"`
+				} `xml:"guid"`
+				PubDate     string   `xml:"pubDate"`
+				Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+				Categories  []string `xml:"category"`
+				Description string   `xml:"description"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, body)
+	}
+
+	ch := doc.Channel
+	if doc.Version != "2.0" || ch.Title != "Tom & Jerry's <Blog>" || ch.Link != "https://example.com/" || ch.Description != "Cats & mice" {
+		t.Errorf("channel = version %q, title %q, link %q, description %q", doc.Version, ch.Title, ch.Link, ch.Description)
+	}
+	if ch.AtomLink.Href != selfURL || ch.AtomLink.Rel != "self" || ch.AtomLink.Type != "application/rss+xml" {
+		t.Errorf("atom:link = %+v", ch.AtomLink)
+	}
+	if got, err := time.Parse(time.RFC1123Z, ch.LastBuildDate); err != nil || !got.Equal(testFeed().Updated) {
+		t.Errorf("lastBuildDate = %q, want an RFC 822 date of the last update", ch.LastBuildDate)
+	}
+	if len(ch.Items) != 1 {
+		t.Fatalf("items = %d, want 1", len(ch.Items))
+	}
+	item := ch.Items[0]
+	if item.Title != `Generics <T> & "you"` || item.Description != "<script>alert('x')</script> & more" {
+		t.Errorf("item text = %q / %q, want the original text after unescaping", item.Title, item.Description)
+	}
+	if item.GUID.Value != "https://example.com/posts/1" || item.GUID.IsPermaLink != "true" || item.Link != item.GUID.Value {
+		t.Errorf("item guid = %+v, link = %q", item.GUID, item.Link)
+	}
+	if got, err := time.Parse(time.RFC1123Z, item.PubDate); err != nil || !got.Equal(testFeed().Items[0].Published) {
+		t.Errorf("pubDate = %q, want an RFC 822 date of publication", item.PubDate)
+	}
+	if item.Creator != "John Doe" || !reflect.DeepEqual(item.Categories, []string{"go", "generics"}) {
+		t.Errorf("item creator = %q, categories = %v", item.Creator, item.Categories)
+	}
+}
+
+func TestRenderAtom(t *testing.T) {
+	body := render(t, FormatAtom, testFeed())
+	assertEscaped(t, body, "<Blog>", "<script>", "<T>")
+
+	type link struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	}
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		ID      string   `xml:"id"`
+		Title   string   `xml:"title"`
+		Updated string   `xml:"updated"`
+		Links   []link   `xml:"link"`
+		Entries []struct {
+			ID        string `xml:"id"`
+			Title     string `xml:"title"`
+			Updated   string `xml:"updated"`
+			Published string `xml:"published"`
+			Links     []link `xml:"link"`
+			Author    struct {
+				Name string `xml:"name"`
+			} `xml:"author"`
+			Categories []struct {
+				Term string `xml:"term,attr"`
+			} `xml:"category"`
+			Content struct {
+				Type string `xml:"type,attr"`
+				Body string `xml:",chardata"`
+			} `xml:"content"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, body)
+	}
+
+	if doc.ID != selfURL || doc.Title != "Tom & Jerry's <Blog>" {
+		t.Errorf("feed id = %q, title = %q", doc.ID, doc.Title)
+	}
+	if got, err := time.Parse(time.RFC3339, doc.Updated); err != nil || !got.Equal(testFeed().Updated) {
+		t.Errorf("feed updated = %q, want an RFC 3339 date of the last update", doc.Updated)
+	}
+	want := []link{{Href: selfURL, Rel: "self"}, {Href: "https://example.com/", Rel: "alternate"}}
+	if !reflect.DeepEqual(doc.Links, want) {
+		t.Errorf("feed links = %+v, want %+v", doc.Links, want)
+	}
+	if len(doc.Entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(doc.Entries))
+	}
+	entry := doc.Entries[0]
+	if entry.ID != "https://example.com/posts/1" || entry.Title != `Generics <T> & "you"` || entry.Author.Name != "John Doe" {
+		t.Errorf("entry = id %q, title %q, author %q", entry.ID, entry.Title, entry.Author.Name)
+	}
+	if got, err := time.Parse(time.RFC3339, entry.Updated); err != nil || !got.Equal(testFeed().Items[0].Updated) {
+		t.Errorf("entry updated = %q", entry.Updated)
+	}
+	if _, err := time.Parse(time.RFC3339, entry.Published); err != nil {
+		t.Errorf("entry published = %q: %v", entry.Published, err)
+	}
+	if entry.Content.Type != "text" || entry.Content.Body != "<script>alert('x')</script> & more" {
+		t.Errorf("entry content = %+v", entry.Content)
+	}
+	if len(entry.Categories) != 2 || entry.Categories[0].Term != "go" {
+		t.Errorf("entry categories = %+v", entry.Categories)
+	}
+}
+
+func TestRenderJSONFeed(t *testing.T) {
+	body := render(t, FormatJSON, testFeed())
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, body)
+	}
+	if doc["version"] != "https://jsonfeed.org/version/1.1" || doc["title"] != "Tom & Jerry's <Blog>" || doc["feed_url"] != selfURL {
+		t.Errorf("feed = version %v, title %v, feed_url %v", doc["version"], doc["title"], doc["feed_url"])
+	}
+	items, _ := doc["items"].([]interface{})
+	if len(items) != 1 {
+		t.Fatalf("items = %v, want one item", doc["items"])
+	}
+	item := items[0].(map[string]interface{})
+	if item["id"] != "https://example.com/posts/1" || item["content_text"] != "<script>alert('x')</script> & more" {
+		t.Errorf("item = %v", item)
+	}
+	for _, field := range []string{"date_published", "date_modified"} {
+		if s, _ := item[field].(string); s == "" {
+			t.Errorf("item has no %s", field)
+		} else if _, err := time.Parse(time.RFC3339, s); err != nil {
+			t.Errorf("item %s = %q is not RFC 3339", field, s)
+		}
+	}
+	if !reflect.DeepEqual(item["tags"], []interface{}{"go", "generics"}) {
+		t.Errorf("item tags = %v", item["tags"])
+	}
+	if _, ok := item["content_html"]; ok {
+		t.Error("item has content_html; content is plain text")
+	}
+}
+
+func TestRenderExcerptFeed(t *testing.T) {
+	feed := testFeed()
+	feed.Items[0].Content = ""
+	feed.Items[0].Summary = "Short summary"
+
+	var rss struct {
+		Description string `xml:"channel>item>description"`
+	}
+	if err := xml.Unmarshal(render(t, FormatRSS, feed), &rss); err != nil || rss.Description != "Short summary" {
+		t.Errorf("RSS description = %q (%v), want the summary", rss.Description, err)
+	}
+	atom := render(t, FormatAtom, feed)
+	if bytes.Contains(atom, []byte("<content")) || !bytes.Contains(atom, []byte("Short summary")) {
+		t.Errorf("Atom entry should have only a summary:\n%s", atom)
+	}
+	var jf struct {
+		Items []struct {
+			ContentText string `json:"content_text"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(render(t, FormatJSON, feed), &jf); err != nil || jf.Items[0].ContentText != "Short summary" {
+		t.Errorf("JSON Feed content_text = %+v (%v), want the summary", jf.Items, err)
+	}
+}
+
+func TestRenderEmptyFeed(t *testing.T) {
+	feed := &appSyndication.Feed{Title: "Empty", Description: "Nothing yet", HomeURL: "https://example.com/"}
+
+	rss := render(t, FormatRSS, feed)
+	if bytes.Contains(rss, []byte("lastBuildDate")) || bytes.Contains(rss, []byte("<item")) {
+		t.Errorf("empty RSS feed has items or a build date:\n%s", rss)
+	}
+	var atom struct {
+		Updated string `xml:"updated"`
+	}
+	if err := xml.Unmarshal(render(t, FormatAtom, feed), &atom); err != nil || atom.Updated == "" {
+		t.Errorf("empty Atom feed updated = %q (%v); the element is required", atom.Updated, err)
+	}
+	if body := render(t, FormatJSON, feed); !strings.Contains(string(body), `"items": []`) {
+		t.Errorf("empty JSON Feed must have an items array:\n%s", body)
+	}
+}
+
+func TestRenderUnsupportedFormat(t *testing.T) {
+	if _, err := Render(Format("xml"), testFeed(), selfURL); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Render error = %v, want %v", err, ErrUnsupportedFormat)
+	}
+	if got := Format("xml").ContentType(); got != "application/octet-stream" {
+		t.Errorf("ContentType = %q", got)
+	}
+}